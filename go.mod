@@ -3,7 +3,11 @@ module neofy
 go 1.22.5
 
 require (
-	github.com/joho/godotenv v1.5.1 // indirect
-	golang.org/x/sys v0.23.0 // indirect
-	golang.org/x/term v0.23.0 // indirect
+	github.com/BurntSushi/toml v1.4.0
+	github.com/godbus/dbus/v5 v5.1.0
+	github.com/joho/godotenv v1.5.1
+	github.com/rivo/uniseg v0.4.7
+	golang.org/x/term v0.23.0
 )
+
+require golang.org/x/sys v0.23.0 // indirect
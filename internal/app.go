@@ -1,23 +1,159 @@
 package internal
 
 import (
+	"errors"
+	"log/slog"
+	"neofy/internal/cli"
 	"neofy/internal/config"
+	"neofy/internal/consts"
+	"neofy/internal/daemon"
 	"neofy/internal/data"
+	"neofy/internal/mode"
+	"neofy/internal/mpris"
 	"neofy/internal/output"
+	"neofy/internal/spotify"
+	"neofy/internal/terminal"
+	"runtime/debug"
+	"time"
 )
 
 func RunApp(enableMock bool) error {
+	// NOTE: Runs on panics too, the terminal is put back before the panic is printed
+	defer terminal.Restore()
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("panic", "panic", r, "stack", string(debug.Stack()))
+			panic(r)
+		}
+	}()
+	slog.Info("starting", "mock", enableMock)
+	defer slog.Info("stopped")
 	var appData *data.AppData
+	var cfg *config.Config
+	remote := daemonController(enableMock)
+	var err error
 	if enableMock {
-		appData = config.InitMock()
+		appData, cfg, err = config.InitMock(remote)
 	} else {
-		appData = config.InitAppData()
+		appData, cfg, err = config.InitAppData(remote)
+	}
+	if err != nil {
+		// NOTE: A bad config or a failed login is shown as a message like commands show it, not a stack trace
+		slog.Error("startup failed", "err", err)
+		return &cli.ExitError{Code: cli.ExitSetup, Err: errors.New(spotify.ErrorReason(err))}
 	}
 
-	defer appData.Term.CloseTerminal()
-	go appData.Spotify.RefreshSchedular.Start()
+	appData.Spinner = output.NewSpinner(appData)
+	media := startMpris(remote)
+	defer media.Close()
+	go func() {
+		defer terminal.RestoreOnPanic()
+		appData.Spotify.RefreshSchedular.Start()
+	}()
+	configChanges := cfg.Watch()
+	resizes := terminal.Resizes()
+	stops := terminal.Stops()
+	playerPoll := poller{}
+	playerPoll.set(appData.Settings.PlayerRefresh)
 	for {
 		output.UpdateApp(appData)
-		appData.Mode.ProcessInput(appData)
+		media.Update(mode.MediaStatus(appData))
+		select {
+		case key := <-terminal.Keys():
+			if key.Code == consts.MOUSE {
+				mode.HandleMouse(appData, key.Mouse)
+				break
+			}
+			// The mode reads the key itself
+			terminal.UnreadKey(key)
+			appData.Mode.ProcessInput(appData)
+			if appData.Quitting {
+				return nil
+			}
+		case <-stops:
+			return nil
+		case <-resizes:
+			err := output.Resize(appData)
+			if err != nil {
+				slog.Warn("resize failed", "err", err)
+				break
+			}
+		case reload := <-configChanges:
+			// NOTE: The config that was loaded last stays in use until the file is fixed
+			if reload.Err != nil {
+				mode.Notify(appData, data.Warning, "Config changes ignored: "+config.Reason(reload.Err))
+				break
+			}
+			config.Apply(appData, reload.Config)
+			playerPoll.set(appData.Settings.PlayerRefresh)
+			mode.Notify(appData, data.Info, "Config reloaded")
+		case <-playerPoll.C():
+			// NOTE: Polling isn't shown in the status line, it fails every tick while nothing is playing
+			err := mode.RefreshPlayer(appData)
+			if err != nil {
+				slog.Debug("player refresh failed", "err", err)
+				break
+			}
+		case c := <-media.Commands():
+			mode.RunMediaCommand(appData, c)
+		case <-mode.StatusTimeout(appData):
+			// Clears the status line
+		case <-appData.Pending.Timeout():
+			// NOTE: Like vim, typed keys that don't become a binding in time are dropped
+			appData.Pending.Clear()
+		}
+	}
+}
+
+// The daemon's controller when it's running, so every TUI shares it's session
+func daemonController(enableMock bool) spotify.Controller {
+	socket := daemon.SocketPath(enableMock)
+	remote, err := daemon.Dial(socket)
+	if err != nil {
+		slog.Debug("no daemon, logging in", "err", err)
+		return nil
+	}
+	slog.Info("using the daemon", "socket", socket)
+	return remote
+}
+
+// Media keys & desktop widgets control the app over MPRIS, the daemon does it when the app uses it.
+// NOTE: Nil when there is no session bus (ssh, not linux), the server's methods work on nil
+func startMpris(remote spotify.Controller) *mpris.Server {
+	if remote != nil {
+		return nil
+	}
+	media, err := mpris.Start()
+	if err != nil {
+		slog.Debug("mpris not started", "err", err)
+		return nil
+	}
+	return media
+}
+
+// Ticks every interval, never ticks when the interval is 0
+type poller struct {
+	interval time.Duration
+	ticker   *time.Ticker
+}
+
+func (p *poller) set(interval time.Duration) {
+	if interval == p.interval {
+		return
+	}
+	p.interval = interval
+	if p.ticker != nil {
+		p.ticker.Stop()
+		p.ticker = nil
+	}
+	if interval > 0 {
+		p.ticker = time.NewTicker(interval)
+	}
+}
+
+func (p *poller) C() <-chan time.Time {
+	if p.ticker == nil {
+		return nil
 	}
+	return p.ticker.C
 }
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"neofy/internal/spotify"
+	"strings"
+)
+
+// Codes the app exits with when a command fails, 0 is success
+const (
+	ExitFailure  = 1 // The request to Spotify failed
+	ExitUsage    = 2 // Unknown command or bad arguments
+	ExitSetup    = 3 // The config is invalid, credentials are missing or the login failed
+	ExitNoDevice = 4 // No device is active or nothing is playing
+)
+
+// Error of a command with the code the app exits with
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// Returns the controller & tokens commands use, it's only called once the arguments are valid.
+// With direct the daemon isn't used even when it's running
+type Connect func(direct bool) (spotify.Controller, spotify.TokenSource, error)
+
+// What a command runs with
+type client struct {
+	accessToken string
+	controller  spotify.Controller
+	socket      string              // Where the daemon listens
+	tokens      spotify.TokenSource // Commands that run for a while get a fresh access token with it
+	w           io.Writer
+}
+
+// Runs a command with the validated arguments
+type action func(c *client) error
+
+type command struct {
+	args   string // Usage of the arguments, empty when there are none
+	direct bool   // Talks to spotify even when the daemon is running
+	help   string
+	name   string
+	parse  func(args []string) (action, error) // Validates the arguments before anything is sent to spotify
+}
+
+var commands = []command{
+	{name: "status", args: "[--format json|<tmpl>] [--watch]", help: "Show what is playing", parse: parseStatus},
+	{name: "play", help: "Resume playback", parse: noArgs(func(c *client) error {
+		return c.controller.StartResumePlayback(c.accessToken)
+	})},
+	{name: "pause", help: "Pause playback", parse: noArgs(func(c *client) error {
+		return c.controller.PausePlayback(c.accessToken)
+	})},
+	{name: "toggle", help: "Pause when playing, otherwise resume", parse: noArgs(toggleCommand)},
+	{name: "next", help: "Skip to the next track", parse: noArgs(func(c *client) error {
+		return c.controller.SkipToNext(c.accessToken)
+	})},
+	{name: "prev", help: "Skip to the previous track", parse: noArgs(func(c *client) error {
+		return c.controller.SkipToPrevious(c.accessToken)
+	})},
+	{name: "vol", args: "<0-100|+N|-N>", help: "Set the volume, +N & -N change it", parse: parseVolume},
+	{name: "shuffle", args: "[on|off]", help: "Set shuffle, toggles without an argument", parse: parseShuffle},
+	{name: "repeat", args: "<off|context|track>", help: "Set the repeat mode", parse: parseRepeat},
+	{name: "play-uri", args: "<uri>", help: "Play a track, playlist, album or artist uri", parse: parsePlayUri},
+	{name: "devices", help: "List the devices, the active one is marked with *", parse: noArgs(devicesCommand)},
+	{name: "transfer", args: "<name>", help: "Move playback to a device, a unique prefix is enough", parse: parseTransfer},
+	{name: "daemon", args: "[--interval <duration>]", direct: true, help: "Share one session with the TUI & commands, runs until ctrl-c", parse: parseDaemon},
+	{name: "http", args: "[--addr <host:port>] [--remote] [--new-token]", help: "Serve a local HTTP API for remotes, runs until ctrl-c", parse: parseHttp},
+	{name: "help", help: "Show the commands", parse: nil},
+}
+
+// Runs the command in args (next, vol 40) & writes it's output to w, socket is where the daemon listens.
+// Failures are returned as an *ExitError
+func Run(w io.Writer, args []string, connect Connect, socket string) error {
+	if len(args) == 0 {
+		return &ExitError{Code: ExitUsage, Err: errors.New("no command, see neofy help")}
+	}
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		return &ExitError{Code: ExitUsage, Err: errors.New("unknown command " + args[0] + ", see neofy help")}
+	}
+	if cmd.parse == nil {
+		PrintUsage(w)
+		return nil
+	}
+	run, err := cmd.parse(args[1:])
+	if err != nil {
+		return &ExitError{Code: ExitUsage, Err: fmt.Errorf("%s: %w\nusage: neofy %s", cmd.name, err, cmd.usage())}
+	}
+	controller, tokens, err := connect(cmd.direct)
+	if err != nil {
+		return &ExitError{Code: ExitSetup, Err: err}
+	}
+	accessToken, err := tokens()
+	if err != nil {
+		return &ExitError{Code: ExitSetup, Err: err}
+	}
+	err = run(&client{accessToken: accessToken, controller: controller, socket: socket, tokens: tokens, w: w})
+	if err != nil {
+		slog.Error("command failed", "command", cmd.name, "err", err)
+		code := ExitFailure
+		if spotify.IsNoDevice(err) {
+			code = ExitNoDevice
+		}
+		return &ExitError{Code: code, Err: errors.New(cmd.name + ": " + spotify.ErrorReason(err))}
+	}
+	return nil
+}
+
+// Lists the commands with their arguments
+func PrintUsage(w io.Writer) {
+	fmt.Fprintln(w, "Commands:")
+	width := 0
+	for _, cmd := range commands {
+		width = max(width, len(cmd.usage()))
+	}
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-*s  %s\n", width, cmd.usage(), cmd.help)
+	}
+}
+
+func (cmd *command) usage() string {
+	if cmd.args == "" {
+		return cmd.name
+	}
+	return cmd.name + " " + cmd.args
+}
+
+func findCommand(name string) *command {
+	for i, cmd := range commands {
+		if cmd.name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+func noArgs(run action) func([]string) (action, error) {
+	return func(args []string) (action, error) {
+		if len(args) > 0 {
+			return nil, errors.New("doesn't take arguments")
+		}
+		return run, nil
+	}
+}
+
+// Names can have spaces, the arguments are joined back together
+func joinArgs(args []string) string {
+	return strings.TrimSpace(strings.Join(args, " "))
+}
@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"neofy/internal/daemon"
+	"time"
+)
+
+// daemon [--interval 1s], playback is polled every interval
+func parseDaemon(args []string) (action, error) {
+	flags := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	interval := flags.Duration("interval", time.Second, "")
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, errors.New("unexpected argument " + flags.Arg(0))
+	}
+	if *interval < time.Second {
+		return nil, errors.New("interval must be at least 1s")
+	}
+	return func(c *client) error {
+		err := daemon.Serve(c.w, c.socket, c.controller, c.tokens, *interval)
+		if err != nil {
+			return fmt.Errorf("parseDaemon: %w", err)
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"neofy/internal/httpapi"
+	"time"
+)
+
+// http [--addr localhost:8091] [--remote] [--interval 1s] [--new-token], see the README for the endpoints
+func parseHttp(args []string) (action, error) {
+	flags := flag.NewFlagSet("http", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	addr := flags.String("addr", "localhost:8091", "")
+	remote := flags.Bool("remote", false, "")
+	interval := flags.Duration("interval", time.Second, "")
+	newToken := flags.Bool("new-token", false, "")
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, errors.New("unexpected argument " + flags.Arg(0))
+	}
+	if *interval < time.Second {
+		return nil, errors.New("interval must be at least 1s")
+	}
+	err = httpapi.CheckAddr(*addr)
+	if err != nil {
+		// NOTE: The func name means nothing to the user
+		return nil, errors.New("addr must be localhost or a loopback ip (127.0.0.1:8091)")
+	}
+	return func(c *client) error {
+		token, err := httpapi.Token(*newToken)
+		if err != nil {
+			return fmt.Errorf("parseHttp: %w", err)
+		}
+		opts := httpapi.Options{Addr: *addr, Interval: *interval, Remote: *remote, Token: token}
+		err = httpapi.Serve(c.w, opts, c.controller, c.tokens)
+		if err != nil {
+			return fmt.Errorf("parseHttp: %w", err)
+		}
+		return nil
+	}, nil
+}
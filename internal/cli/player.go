@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"neofy/internal/match"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+func toggleCommand(c *client) error {
+	state, err := c.controller.PlaybackState(c.accessToken)
+	if err != nil {
+		return fmt.Errorf("toggleCommand: %w", err)
+	}
+	if state.IsPlaying {
+		return c.controller.PausePlayback(c.accessToken)
+	}
+	return c.controller.StartResumePlayback(c.accessToken)
+}
+
+// vol 40 sets the volume, vol +5 & vol -5 change it. The volume is clamped to 0-100
+func parseVolume(args []string) (action, error) {
+	if len(args) != 1 {
+		return nil, errors.New("expected a volume")
+	}
+	arg := args[0]
+	volume, err := strconv.Atoi(arg)
+	if err != nil {
+		return nil, errors.New("not a number: " + arg)
+	}
+	relative := strings.HasPrefix(arg, "+") || strings.HasPrefix(arg, "-")
+	if !relative && volume > 100 {
+		return nil, errors.New("must be between 0 & 100")
+	}
+	return func(c *client) error {
+		if relative {
+			state, err := c.controller.PlaybackState(c.accessToken)
+			if err != nil {
+				return fmt.Errorf("parseVolume: %w", err)
+			}
+			volume += state.Volume
+		}
+		return c.controller.SetPlaybackVolume(c.accessToken, min(max(volume, 0), 100))
+	}, nil
+}
+
+func parseShuffle(args []string) (action, error) {
+	switch joinArgs(args) {
+	case "":
+		return func(c *client) error {
+			state, err := c.controller.PlaybackState(c.accessToken)
+			if err != nil {
+				return fmt.Errorf("parseShuffle: %w", err)
+			}
+			return c.controller.ShuffleMode(c.accessToken, !state.IsShuffled)
+		}, nil
+	case "on":
+		return func(c *client) error { return c.controller.ShuffleMode(c.accessToken, true) }, nil
+	case "off":
+		return func(c *client) error { return c.controller.ShuffleMode(c.accessToken, false) }, nil
+	}
+	return nil, errors.New("expected on or off")
+}
+
+func parseRepeat(args []string) (action, error) {
+	mode := joinArgs(args)
+	switch mode {
+	case "off", "context", "track":
+		return func(c *client) error { return c.controller.RepeatMode(c.accessToken, mode) }, nil
+	}
+	return nil, errors.New("expected off, context or track")
+}
+
+func parsePlayUri(args []string) (action, error) {
+	if len(args) != 1 {
+		return nil, errors.New("expected a uri")
+	}
+	uri := args[0]
+	if !strings.HasPrefix(uri, "spotify:") {
+		return nil, errors.New("not a spotify uri (spotify:track:<id>): " + uri)
+	}
+	return func(c *client) error { return c.controller.PlayUri(uri, c.accessToken) }, nil
+}
+
+func devicesCommand(c *client) error {
+	devices, err := c.controller.AvailableDevices(c.accessToken)
+	if err != nil {
+		return fmt.Errorf("devicesCommand: %w", err)
+	}
+	tw := tabwriter.NewWriter(c.w, 0, 0, 2, ' ', 0)
+	for _, d := range devices {
+		active := " "
+		if d.IsActive {
+			active = "*"
+		}
+		volume := "-"
+		if d.SupportsVolume {
+			volume = strconv.Itoa(d.Volume) + "%"
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\t%s\n", active, d.Name, d.Type, volume)
+	}
+	return tw.Flush()
+}
+
+func parseTransfer(args []string) (action, error) {
+	name := joinArgs(args)
+	if name == "" {
+		return nil, errors.New("expected a device name")
+	}
+	return func(c *client) error {
+		devices, err := c.controller.AvailableDevices(c.accessToken)
+		if err != nil {
+			return fmt.Errorf("parseTransfer: %w", err)
+		}
+		names := []string{}
+		for _, d := range devices {
+			names = append(names, d.Name)
+		}
+		index, err := match.Name(names, name)
+		if err != nil {
+			return fmt.Errorf("parseTransfer: %w", err)
+		}
+		// NOTE: Playback stays paused or playing like it was
+		return c.controller.TransferPlayback(c.accessToken, devices[index].Id, false)
+	}, nil
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
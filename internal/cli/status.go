@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"neofy/internal/spotify"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Writes a status in the format picked with --format
+type statusFormat func(w io.Writer, s spotify.Status) error
+
+// status [--format json|<template>] [--watch] [--interval 1s].
+// The template is text/template over spotify.Status, {{.Artist}} - {{.Name}} [{{.Progress}}]
+func parseStatus(args []string) (action, error) {
+	flags := flag.NewFlagSet("status", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	format := flags.String("format", "", "")
+	watch := flags.Bool("watch", false, "")
+	interval := flags.Duration("interval", time.Second, "")
+	err := flags.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, errors.New("unexpected argument " + flags.Arg(0))
+	}
+	if *interval < time.Second {
+		return nil, errors.New("interval must be at least 1s")
+	}
+	write, err := parseFormat(*format)
+	if err != nil {
+		return nil, err
+	}
+	if *watch {
+		return func(c *client) error { return watchStatus(c, write, *interval) }, nil
+	}
+	return func(c *client) error {
+		status, err := currentStatus(c)
+		if err != nil {
+			return fmt.Errorf("parseStatus: %w", err)
+		}
+		return write(c.w, status)
+	}, nil
+}
+
+func parseFormat(format string) (statusFormat, error) {
+	switch format {
+	case "":
+		return writeText, nil
+	case "json":
+		return func(w io.Writer, s spotify.Status) error {
+			// NOTE: Encode ends every status with a newline so --watch gives one per line
+			return json.NewEncoder(w).Encode(s)
+		}, nil
+	}
+	tmpl, err := template.New("status").Option("missingkey=error").Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("bad format: %w", err)
+	}
+	// Unknown fields only fail once the template runs, so it's tried before anything is sent to spotify
+	err = tmpl.Execute(io.Discard, spotify.StoppedStatus())
+	if err != nil {
+		return nil, fmt.Errorf("bad format: %w", err)
+	}
+	return func(w io.Writer, s spotify.Status) error {
+		var b strings.Builder
+		err := tmpl.Execute(&b, s)
+		if err != nil {
+			return fmt.Errorf("parseFormat: %w", err)
+		}
+		out := b.String()
+		if !strings.HasSuffix(out, "\n") {
+			out += "\n"
+		}
+		_, err = io.WriteString(w, out)
+		return err
+	}, nil
+}
+
+func writeText(w io.Writer, s spotify.Status) error {
+	if s.State == "stopped" {
+		_, err := fmt.Fprintln(w, "Stopped")
+		return err
+	}
+	playing := "Paused"
+	if s.Playing {
+		playing = "Playing"
+	}
+	volume := "unsupported"
+	if s.SupportsVolume {
+		volume = strconv.Itoa(s.Volume) + "%"
+	}
+	_, err := fmt.Fprintf(w, "%s: %s - %s\nProgress: %s / %s\nVolume: %s\nShuffle: %s\nRepeat: %s\n",
+		playing, s.Name, s.Artist, s.Progress, s.Duration, volume, onOff(s.Shuffle), s.Repeat)
+	return err
+}
+
+// Writes the status, then again every time playback changes. Runs until it's stopped (ctrl-c) or the output closes.
+// NOTE: Progress moving along doesn't count as a change, seeking does
+func watchStatus(c *client, write statusFormat, interval time.Duration) error {
+	var last *spotify.Status
+	var lastAt time.Time
+	for {
+		status, err := pollStatus(c)
+		if err != nil {
+			// Spotify going away for a bit shouldn't end the watch
+			slog.Warn("status poll failed", "err", err)
+		} else if last == nil || status.Changed(*last, time.Since(lastAt)) {
+			err = write(c.w, status)
+			if err != nil {
+				return fmt.Errorf("watchStatus: %w", err)
+			}
+			last = &status
+			lastAt = time.Now()
+		} else {
+			// Keeps the expected progress from drifting while nothing changes
+			last.ProgressMs = status.ProgressMs
+			lastAt = time.Now()
+		}
+		time.Sleep(interval)
+	}
+}
+
+// The access token is fetched every poll since a watch can outlive it
+func pollStatus(c *client) (spotify.Status, error) {
+	accessToken, err := c.tokens()
+	if err != nil {
+		return spotify.Status{}, fmt.Errorf("pollStatus: %w", err)
+	}
+	c.accessToken = accessToken
+	return currentStatus(c)
+}
+
+// Nothing playing is the stopped status, not a failure, so status bars get the same answer from status & --watch
+func currentStatus(c *client) (spotify.Status, error) {
+	state, err := c.controller.PlaybackState(c.accessToken)
+	if errors.Is(err, spotify.ErrNoPlayback) {
+		return spotify.StoppedStatus(), nil
+	}
+	if err != nil {
+		return spotify.Status{}, fmt.Errorf("currentStatus: %w", err)
+	}
+	return spotify.NewStatus(state), nil
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"fmt"
+	"neofy/internal/spotify"
+	"strings"
+	"testing"
+)
+
+// Only PlaybackState is used by status, anything else panics
+type stateController struct {
+	spotify.Controller
+	state *spotify.SlimPlayerData
+	err   error
+}
+
+func (c stateController) PlaybackState(string) (*spotify.SlimPlayerData, error) {
+	return c.state, c.err
+}
+
+func TestStatusStopped(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, "Stopped\n"},
+		{[]string{"--format", "json"}, `"state":"stopped"`},
+		{[]string{"--format", "{{.State}}"}, "stopped\n"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			var out strings.Builder
+			connect := func(bool) (spotify.Controller, spotify.TokenSource, error) {
+				controller := stateController{err: fmt.Errorf("PlaybackState: %w", spotify.ErrNoPlayback)}
+				return controller, func() (string, error) { return "token", nil }, nil
+			}
+			err := Run(&out, append([]string{"status"}, tt.args...), connect, "")
+			if err != nil {
+				t.Fatalf("Run() = %v, want no error when nothing is playing", err)
+			}
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("Run() wrote %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"io"
+	"log/slog"
+	"neofy/internal/cli"
+	"neofy/internal/config"
+	"neofy/internal/daemon"
+	"neofy/internal/spotify"
+)
+
+// Runs a command without the TUI (neofy next), see cli.Run.
+// Commands go through the daemon when it's running
+func RunCommand(w io.Writer, enableMock bool, args []string) error {
+	socket := daemon.SocketPath(enableMock)
+	connect := func(direct bool) (spotify.Controller, spotify.TokenSource, error) {
+		if !direct {
+			remote, err := daemon.Dial(socket)
+			if err == nil {
+				slog.Debug("using the daemon", "socket", socket)
+				return remote, daemon.Tokens, nil
+			}
+		}
+		if enableMock {
+			return config.InitMockController()
+		}
+		return config.InitController()
+	}
+	return cli.Run(w, args, connect, socket)
+}
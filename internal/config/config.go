@@ -1,37 +1,51 @@
 package config
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"neofy/internal/data"
 	"neofy/internal/display"
+	"neofy/internal/list"
 	"neofy/internal/mode"
+	"neofy/internal/scheduler"
 	"neofy/internal/spotify"
 	"neofy/internal/terminal"
 	"os"
 	"time"
 )
 
-func InitAppData() *data.AppData {
-	newTerm := terminal.InitAppTerm()
-
-	w, h, err := newTerm.GetTerminalSize()
+// Remote is the daemon's controller, nil when it isn't running & the app logs in itself
+func InitAppData(remote spotify.Controller) (*data.AppData, *Config, error) {
+	// NOTE: Loaded before the terminal is in raw mode so errors are readable
+	cfg, err := loadAppConfig()
 	if err != nil {
-		panic(fmt.Errorf("InitAppData: %w", err))
+		return nil, nil, fmt.Errorf("InitAppData: %w", err)
+	}
+	var controller spotify.Controller = spotify.SpotifyPlayer{}
+	// The daemon has the tokens, nothing needs refreshing
+	spotifyConfig := &spotify.Config{RefreshSchedular: *scheduler.CreateSchedular(time.Now(), time.Hour, nil)}
+	if remote != nil {
+		controller = remote
+	} else {
+		spotifyConfig, err = initSpotifyConfig(cfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("InitAppData: %w", err)
+		}
 	}
 
-	// TODO: Figure out how to handle runes with width 2 in terminal
-	newAppDislay := *display.InitDisplay(w-3, h)
+	newTerm := terminal.InitAppTerm()
 
-	controller := spotify.SpotifyPlayer{}
-	spotifyConfig, err := initSpotifyConfig()
+	w, h, err := newTerm.GetTerminalSize()
 	if err != nil {
-		panic(fmt.Errorf("InitAppData: %w", err))
+		return nil, nil, fmt.Errorf("InitAppData: %w", err)
 	}
 
+	newAppDislay := *display.InitDisplay(w, h)
+
 	playerData, err := controller.PlaybackState(spotifyConfig.UserTokens.AccessToken)
 	if err != nil {
-		panic(fmt.Errorf("InitAppData: player state: %w", err))
+		return nil, nil, fmt.Errorf("InitAppData: player state: %w", err)
 	}
 
 	var curSongProgress *time.Duration
@@ -42,48 +56,45 @@ func InitAppData() *data.AppData {
 
 	userPlaylists, err := controller.GetUserPlaylists(spotifyConfig.UserTokens.AccessToken)
 	if err != nil {
-		panic(fmt.Errorf("InitAppData: %w", err))
+		return nil, nil, fmt.Errorf("InitAppData: %w", err)
 	}
 	playlists := []data.PlaylistDetail{}
 	for _, p := range userPlaylists {
 		newP := data.PlaylistDetail{
-			Href:       p.TracksHref,
-			Name:       p.Name,
-			NumSongs:   p.TotalTracks,
-			ContextUri: p.ContextUri,
+			Collaborative: p.Collaborative,
+			ContextUri:    p.ContextUri,
+			Description:   p.Description,
+			Href:          p.TracksHref,
+			Id:            p.Id,
+			Name:          p.Name,
+			NumSongs:      p.TotalTracks,
+			Public:        p.Public,
+			SnapshotId:    p.SnapshotId,
 		}
 		playlists = append(playlists, newP)
 	}
 	// TODO: Handle if the current playlist is empty
 	curPlaylist, err := controller.GetPlaylist(playerData.PlaylistHref, spotifyConfig.UserTokens.AccessToken)
 	if err != nil {
-		panic(fmt.Errorf("InitAppData: %w", err))
+		return nil, nil, fmt.Errorf("InitAppData: %w", err)
 	}
 	tracks := []data.TrackDetail{}
 	for _, t := range curPlaylist.Tracks {
-		newT := data.TrackDetail{Name: t.Name, ContextUri: t.ContextUri}
+		artists := []data.ArtistDetail{}
+		for _, a := range t.Artist {
+			artists = append(artists, data.ArtistDetail{Name: a.Name})
+		}
+		newT := data.TrackDetail{Name: t.Name, ContextUri: t.ContextUri, DurationMs: t.DurationMs, Artists: artists}
 		tracks = append(tracks, newT)
 	}
-	curPlaylistDetail, posY := findSelectedPlaylist(playlists, curPlaylist.PlaylistName)
-	newPlaylist := data.Playlist{
-		CursorPosY: posY,
-		RowOffset:  0,
-		Display: data.Display{
-			Width:  int(float64(newAppDislay.Width)*0.25) - 1,
-			Height: int(float64(newAppDislay.Height)*0.9) - 1,
-		},
-		Playlists:        playlists,
-		SelectedPlaylist: curPlaylistDetail,
-	}
+	posY := findSelectedPlaylist(playlists, curPlaylist.PlaylistName)
+	// NOTE: Panes & the list viewports are sized once they are drawn
+	newPlaylist := data.Playlist{List: list.New(playlists, 0)}
+	newPlaylist.Selected = posY
+	newPlaylist.MoveToIndex(posY)
 
-	mpHeight := int(float64(newAppDislay.Height) * 0.10)
 	mp := data.MusicPlayer{
-		Controller: controller,
-		Display: data.Display{
-			Width:  newAppDislay.Width - 1,
-			Height: mpHeight,
-			Screen: make([]string, mpHeight),
-		},
+		Controller:     controller,
 		IsPlaying:      playerData.IsPlaying,
 		SupportsVolume: playerData.SupportsVolume,
 		IsShuffled:     playerData.IsShuffled,
@@ -92,22 +103,25 @@ func InitAppData() *data.AppData {
 			Artist:   playerData.Artist,
 			Progress: curSongProgress,
 			Duration: time.Duration(playerData.SongDuration * 1000000),
+			Uri:      playerData.SongUri,
 		},
 		Repeat: playerData.Repeat,
 		Volume: playerData.Volume,
 	}
 
-	newSongs := data.Tracks{
-		Display: data.Display{
-			Width:  int(float64(newAppDislay.Width) * 0.75),
-			Height: int(float64(newAppDislay.Height)*0.9) - 1,
-		},
-		SelectedTrack: &data.TrackDetail{Name: playerData.SongName},
-		Tracks:        tracks,
+	newSongs := data.Tracks{List: list.New(tracks, 0)}
+	for i, t := range tracks {
+		if t.Name == playerData.SongName {
+			newSongs.Selected = i
+			break
+		}
 	}
 
 	newConfig := data.AppData{
 		Display:  newAppDislay,
+		History:  data.History{List: list.New([]data.HistoryDetail{}, 0)},
+		Messages: data.Messages{List: list.New([]data.Message{}, 0)},
+		Picker:   data.PlaylistPicker{List: list.New([]data.PlaylistDetail{}, 0)},
 		Mode:     &mode.Player{},
 		Playlist: newPlaylist,
 		Player:   mp,
@@ -115,46 +129,90 @@ func InitAppData() *data.AppData {
 		Spotify:  *spotifyConfig,
 		Term:     newTerm,
 	}
+	Apply(&newConfig, cfg)
+	mode.Startup(&newConfig, cfg.StartupMode)
+
+	return &newConfig, cfg, nil
+}
+
+// Loads config.toml from the config dir
+func loadAppConfig() (*Config, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, fmt.Errorf("loadAppConfig: %w", err)
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return nil, fmt.Errorf("loadAppConfig: %w", err)
+	}
+	return cfg, nil
+}
 
-	return &newConfig
+// Controller & tokens for commands that run without the TUI, the cached access token is used until it expires.
+// Logs in when there are no cached tokens
+func InitController() (spotify.Controller, spotify.TokenSource, error) {
+	cfg, err := loadAppConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("InitController: %w", err)
+	}
+	clientId, clientSecret, err := credentials(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("InitController: %w", err)
+	}
+	tokens := func() (string, error) {
+		u, err := userTokens(clientId, clientSecret, true)
+		if err != nil {
+			return "", fmt.Errorf("InitController: %w", err)
+		}
+		return u.AccessToken, nil
+	}
+	_, err = tokens()
+	if err != nil {
+		return nil, nil, err
+	}
+	return spotify.SpotifyPlayer{}, tokens, nil
 }
 
-func initSpotifyConfig() (*spotify.Config, error) {
-	clientId := os.Getenv("SPOTIFY_CLIENT_ID")
+// Credentials in the environment (.env) are used over the ones in the config file
+func credentials(cfg *Config) (string, string, error) {
+	clientId := cmp.Or(os.Getenv("SPOTIFY_CLIENT_ID"), cfg.ClientId)
 	if clientId == "" {
-		return nil, errors.New("initSpotifyConfig: ClientId is empty")
+		return "", "", errors.New("credentials: ClientId is empty, set spotify.client_id in " + cfg.Path + " or SPOTIFY_CLIENT_ID")
 	}
-	clientSecret := os.Getenv("SPOTIFY_CLIENT_SECRET")
+	clientSecret := cmp.Or(os.Getenv("SPOTIFY_CLIENT_SECRET"), cfg.ClientSecret)
 	if clientSecret == "" {
-		return nil, errors.New("initSpotifyConfig: ClientSecret is empty")
+		return "", "", errors.New("credentials: ClientSecret is empty, set spotify.client_secret in " + cfg.Path + " or SPOTIFY_CLIENT_SECRET")
+	}
+	return clientId, clientSecret, nil
+}
+
+func initSpotifyConfig(cfg *Config) (*spotify.Config, error) {
+	clientId, clientSecret, err := credentials(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("initSpotifyConfig: %w", err)
 	}
 	c := spotify.Config{
 		ClientId:     clientId,
 		ClientSecret: clientSecret,
 		UserTokens:   spotify.User{},
 	}
-	code, err := spotify.LoginUser(clientId)
-	if err != nil {
-		return nil, err
-	}
-	accessT, refreshT, err := spotify.UserAccessAndRefreshToken(code, clientId, clientSecret)
+	u, err := userTokens(clientId, clientSecret, false)
 	if err != nil {
 		return nil, fmt.Errorf("initSpotifyConfig: %w", err)
 	}
-	c.UserTokens.AccessToken = accessT
-	c.UserTokens.RefreshToken = refreshT
+	c.UserTokens = u
 
-	tokenScheduler := spotify.RefreshHourlyScheduler(&c.UserTokens, clientId, clientSecret)
+	tokenScheduler := spotify.RefreshHourlyScheduler(&c.UserTokens, clientId, clientSecret, saveTokens)
 	c.RefreshSchedular = *tokenScheduler
 
 	return &c, nil
 }
 
-func findSelectedPlaylist(list []data.PlaylistDetail, playlistName string) (*data.PlaylistDetail, int) {
-	for i, p := range list {
+func findSelectedPlaylist(playlists []data.PlaylistDetail, playlistName string) int {
+	for i, p := range playlists {
 		if p.Name == playlistName {
-			return &p, i
+			return i
 		}
 	}
-	return nil, -1
+	return -1
 }
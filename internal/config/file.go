@@ -0,0 +1,376 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"neofy/internal/data"
+	"neofy/internal/keymap"
+	"neofy/internal/logging"
+	"neofy/internal/scheduler"
+	"neofy/internal/terminal"
+	"neofy/internal/theme"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Contents of config.toml, every setting is optional
+type fileConfig struct {
+	StartupMode string `toml:"startup_mode"` // player, playlists, tracks or history
+	VolumeStep  int    `toml:"volume_step"`
+	Spotify     struct {
+		ClientId     string `toml:"client_id"`
+		ClientSecret string `toml:"client_secret"`
+	} `toml:"spotify"`
+	Layout struct {
+		Player         paneConfig `toml:"player"`
+		PlayerPosition string     `toml:"player_position"` // top or bottom
+		Playlists      paneConfig `toml:"playlists"`
+		Tracks         paneConfig `toml:"tracks"`
+	} `toml:"layout"`
+	Polling struct {
+		ConfigReload  time.Duration `toml:"config_reload"`
+		PlayerRefresh time.Duration `toml:"player_refresh"`
+	} `toml:"polling"`
+	Log struct {
+		Level string `toml:"level"` // debug, info, warn or error
+	} `toml:"log"`
+	Theme  themeConfig                  `toml:"theme"`
+	Keymap map[string]map[string]string `toml:"keymap"` // mode -> keys -> action
+}
+
+// Styles that are set are used over the ones of the named theme, see theme.ParseStyle
+type themeConfig struct {
+	Border   string            `toml:"border"`
+	Colors   string            `toml:"colors"` // auto, none, 16, 256 or truecolor
+	Cursor   string            `toml:"cursor"`
+	Error    string            `toml:"error"`
+	Header   string            `toml:"header"`
+	Info     string            `toml:"info"`
+	Modes    map[string]string `toml:"modes"`
+	Name     string            `toml:"name"`
+	Playing  string            `toml:"playing"`
+	Selected string            `toml:"selected"`
+	Success  string            `toml:"success"`
+	Warning  string            `toml:"warning"`
+}
+
+// Size of a pane, player sizes are rows & list sizes are columns
+type paneConfig struct {
+	Hidden bool    `toml:"hidden"`
+	Max    int     `toml:"max"`
+	Min    int     `toml:"min"`
+	Size   float64 `toml:"size"`
+}
+
+// Validated config, the settings can be applied while the app runs
+type Config struct {
+	ClientId     string
+	ClientSecret string
+	ConfigReload time.Duration
+	Keymap       *keymap.Keymap
+	LogLevel     slog.Level
+	modTime      time.Time
+	keymapTime   time.Time // Mod time of the keymap file, zero when there is none
+	Path         string
+	Settings     data.Settings
+	StartupMode  string
+}
+
+// Names used for modes in the config, by ShortDisplay
+var modeNames = map[string]rune{
+	"command":   'C',
+	"help":      '?',
+	"history":   'H',
+	"messages":  'M',
+	"picker":    'A',
+	"player":    'P',
+	"playlists": 'U',
+	"prompt":    'I',
+	"tracks":    'T',
+}
+
+func defaultFileConfig() fileConfig {
+	f := fileConfig{
+		StartupMode: "player",
+		VolumeStep:  10,
+	}
+	f.Layout.Player = paneConfig{Min: 3, Size: 0.10}
+	f.Layout.PlayerPosition = "bottom"
+	f.Layout.Playlists = paneConfig{Min: 10, Size: 0.25}
+	f.Layout.Tracks = paneConfig{Min: 20}
+	f.Polling.ConfigReload = 2 * time.Second
+	f.Polling.PlayerRefresh = 5 * time.Second
+	f.Log.Level = "info"
+	f.Theme.Colors = "auto"
+	f.Theme.Name = "default"
+	return f
+}
+
+// The config file can't be used, Err has every problem on it's own line
+type InvalidError struct {
+	Err  error
+	Path string
+}
+
+func (e *InvalidError) Error() string {
+	return "invalid config " + e.Path + ":\n" + e.Err.Error()
+}
+
+func (e *InvalidError) Unwrap() error {
+	return e.Err
+}
+
+// Why the config can't be used on one line, for the status line
+func Reason(err error) string {
+	var invalid *InvalidError
+	if errors.As(err, &invalid) {
+		return strings.ReplaceAll(invalid.Err.Error(), "\n", "; ")
+	}
+	return err.Error()
+}
+
+// Path of config.toml in the config dir
+func configPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", fmt.Errorf("configPath: %w", err)
+	}
+	return filepath.Join(dir, "config.toml"), nil
+}
+
+// Reads & validates the config at path, a missing file gives the defaults.
+// Every problem is reported at once so they can be fixed together
+func loadConfig(path string) (*Config, error) {
+	f := defaultFileConfig()
+	modTime := time.Time{}
+	info, err := os.Stat(path)
+	if err == nil {
+		modTime = info.ModTime()
+		meta, err := toml.DecodeFile(path, &f)
+		if err != nil {
+			return nil, fmt.Errorf("loadConfig: %w", &InvalidError{Err: err, Path: path})
+		}
+		errs := []error{}
+		for _, key := range meta.Undecoded() {
+			errs = append(errs, errors.New("unknown setting "+key.String()))
+		}
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("loadConfig: %w", &InvalidError{Err: errors.Join(errs...), Path: path})
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("loadConfig: %w", err)
+	}
+
+	c := Config{
+		ClientId:     f.Spotify.ClientId,
+		ClientSecret: f.Spotify.ClientSecret,
+		ConfigReload: f.Polling.ConfigReload,
+		modTime:      modTime,
+		Path:         path,
+		StartupMode:  f.StartupMode,
+		Settings: data.Settings{
+			Layout: data.Layout{
+				Player:      data.PaneLayout(f.Layout.Player),
+				PlayerOnTop: f.Layout.PlayerPosition == "top",
+				Playlists:   data.PaneLayout(f.Layout.Playlists),
+				Tracks:      data.PaneLayout(f.Layout.Tracks),
+			},
+			PlayerRefresh: f.Polling.PlayerRefresh,
+			VolumeStep:    f.VolumeStep,
+		},
+	}
+	errs := []error{}
+	switch f.StartupMode {
+	case "player", "playlists", "tracks", "history":
+	default:
+		errs = append(errs, errors.New("startup_mode: must be player, playlists, tracks or history"))
+	}
+	if f.VolumeStep < 1 || f.VolumeStep > 100 {
+		errs = append(errs, errors.New("volume_step: must be between 1 & 100"))
+	}
+	if f.Layout.PlayerPosition != "top" && f.Layout.PlayerPosition != "bottom" {
+		errs = append(errs, errors.New("layout.player_position: must be top or bottom"))
+	}
+	if f.Layout.Player.Size < 0.05 || f.Layout.Player.Size > 0.5 {
+		errs = append(errs, errors.New("layout.player.size: must be between 0.05 & 0.5"))
+	}
+	// NOTE: The player needs 3 rows to be drawn
+	if f.Layout.Player.Min < 3 {
+		errs = append(errs, errors.New("layout.player.min: must be at least 3"))
+	}
+	if f.Layout.Playlists.Size < 0.1 || f.Layout.Playlists.Size > 0.9 {
+		errs = append(errs, errors.New("layout.playlists.size: must be between 0.1 & 0.9"))
+	}
+	if f.Layout.Tracks.Size != 0 {
+		errs = append(errs, errors.New("layout.tracks.size: can't be set, the tracks take what the playlists don't"))
+	}
+	errs = append(errs, validatePane("player", f.Layout.Player)...)
+	errs = append(errs, validatePane("playlists", f.Layout.Playlists)...)
+	errs = append(errs, validatePane("tracks", f.Layout.Tracks)...)
+	if f.Polling.ConfigReload < 0 {
+		errs = append(errs, errors.New("polling.config_reload: must be 0 (off) or more"))
+	}
+	// NOTE: Spotify rate limits apps that poll too often
+	if f.Polling.PlayerRefresh != 0 && f.Polling.PlayerRefresh < time.Second {
+		errs = append(errs, errors.New("polling.player_refresh: must be 0 (off) or at least 1s"))
+	}
+	c.LogLevel, err = logging.ParseLevel(f.Log.Level)
+	if err != nil {
+		errs = append(errs, errors.New("log.level: must be debug, info, warn or error"))
+	}
+	c.Settings.Theme, err = loadTheme(f.Theme)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	// NOTE: Checked before the keymap is read so a change while it's read is picked up by the watcher
+	if path, err := keymapPath(); err == nil {
+		c.keymapTime = fileModTime(path)
+	}
+	c.Keymap, err = loadKeymap(f.Keymap)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("loadConfig: %w", &InvalidError{Err: errors.Join(errs...), Path: path})
+	}
+	return &c, nil
+}
+
+func validatePane(name string, p paneConfig) []error {
+	errs := []error{}
+	if p.Min < 0 {
+		errs = append(errs, fmt.Errorf("layout.%s.min: must be 0 or more", name))
+	}
+	if p.Max < 0 {
+		errs = append(errs, fmt.Errorf("layout.%s.max: must be 0 (no max) or more", name))
+	} else if p.Max > 0 && p.Max < p.Min {
+		errs = append(errs, fmt.Errorf("layout.%s.max: must be at least min (%d)", name, p.Min))
+	}
+	return errs
+}
+
+// Styles of the named theme in the colors the terminal supports
+func loadTheme(c themeConfig) (data.Theme, error) {
+	errs := []error{}
+	mode, err := theme.ParseColorMode(c.Colors)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("theme.colors: %w", err))
+	}
+	styles, ok := theme.Bundled[c.Name]
+	if !ok {
+		errs = append(errs, errors.New("theme.name: must be one of "+strings.Join(theme.Names(), ", ")))
+		styles = theme.Bundled["default"]
+	}
+	sgr := func(setting, style, override string) string {
+		if override != "" {
+			style = override
+		}
+		s, err := theme.ParseStyle(style)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("theme.%s: %w", setting, err))
+			return ""
+		}
+		return s.SGR(mode)
+	}
+	t := data.Theme{
+		Border:   sgr("border", styles.Border, c.Border),
+		Cursor:   sgr("cursor", styles.Cursor, c.Cursor),
+		Error:    sgr("error", styles.Error, c.Error),
+		Header:   sgr("header", styles.Header, c.Header),
+		Info:     sgr("info", styles.Info, c.Info),
+		Modes:    map[rune]string{},
+		Playing:  sgr("playing", styles.Playing, c.Playing),
+		Selected: sgr("selected", styles.Selected, c.Selected),
+		Success:  sgr("success", styles.Success, c.Success),
+		Warning:  sgr("warning", styles.Warning, c.Warning),
+	}
+	for r, style := range styles.Modes {
+		t.Modes[r] = sgr("modes", style, "")
+	}
+	for name, style := range c.Modes {
+		r, ok := modeNames[name]
+		if !ok {
+			errs = append(errs, errors.New("theme.modes: unknown mode "+name))
+			continue
+		}
+		t.Modes[r] = sgr("modes."+name, style, "")
+	}
+	return t, errors.Join(errs...)
+}
+
+// A change to the config file, Err is why it was ignored (Config is nil then)
+type Reload struct {
+	Config *Config
+	Err    error
+}
+
+// Checks the config & keymap files for changes, the config is loaded again when either changed & sent to changes
+type configWatcher struct {
+	changes    chan Reload
+	keymapPath string // Empty when there is no config dir
+	keymapTime time.Time
+	modTime    time.Time
+	path       string
+}
+
+func (w *configWatcher) Execute() {
+	configTime, keymapTime := fileModTime(w.path), fileModTime(w.keymapPath)
+	if configTime.Equal(w.modTime) && keymapTime.Equal(w.keymapTime) {
+		return
+	}
+	w.modTime, w.keymapTime = configTime, keymapTime
+	c, err := loadConfig(w.path)
+	if err != nil {
+		// NOTE: Invalid changes are ignored until the file is fixed, the app tells the user
+		slog.Warn("config changes ignored", "err", err)
+		w.changes <- Reload{Err: err}
+		return
+	}
+	slog.Info("config reloaded", "path", w.path)
+	w.changes <- Reload{Config: c}
+}
+
+// Mod time of the file at path, zero when it doesn't exist
+func fileModTime(path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// Polls the config & keymap files every ConfigReload, nil when reloading is off
+func (c *Config) Watch() <-chan Reload {
+	if c.ConfigReload == 0 {
+		return nil
+	}
+	keymapPath, _ := keymapPath()
+	w := configWatcher{
+		changes:    make(chan Reload),
+		keymapPath: keymapPath,
+		keymapTime: c.keymapTime,
+		modTime:    c.modTime,
+		path:       c.Path,
+	}
+	s := scheduler.CreateSchedular(time.Now(), c.ConfigReload, []scheduler.Job{&w})
+	go func() {
+		defer terminal.RestoreOnPanic()
+		s.Start()
+	}()
+	return w.changes
+}
+
+// Applies the settings, keymap & log level of c, credentials & the startup mode are only used when the app starts
+func Apply(d *data.AppData, c *Config) {
+	d.Keymap = c.Keymap
+	d.Settings = c.Settings
+	logging.SetLevel(c.LogLevel)
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"neofy/internal/keymap"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Edits to the keymap file are reloaded like edits to the config
+func TestWatchKeymap(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	err := os.Mkdir(filepath.Join(dir, "neofy"), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "neofy", "config.toml")
+	keymapFile := filepath.Join(dir, "neofy", "keymap")
+	c, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("loadConfig() = %v", err)
+	}
+	w := configWatcher{
+		changes:    make(chan Reload, 1),
+		keymapPath: keymapFile,
+		keymapTime: c.keymapTime,
+		modTime:    c.modTime,
+		path:       path,
+	}
+	// Each write gets a mod time of it's own, writes in the same tick can share one
+	at := time.Now()
+	write := func(path, text string) {
+		t.Helper()
+		err := os.WriteFile(path, []byte(text), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		at = at.Add(time.Second)
+		err = os.Chtimes(path, at, at)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	reload := func() *Reload {
+		t.Helper()
+		w.Execute()
+		select {
+		case r := <-w.changes:
+			return &r
+		default:
+			return nil
+		}
+	}
+
+	if r := reload(); r != nil {
+		t.Errorf("Execute() without changes = %+v, want no reload", r)
+	}
+	write(keymapFile, "player x app.quit\n")
+	r := reload()
+	if r == nil || r.Err != nil {
+		t.Fatalf("Execute() after the keymap changed = %+v, want a reload", r)
+	}
+	if keys := r.Config.Keymap.Keys(keymap.Player, keymap.AppQuit); !slices.Contains(keys, "x") {
+		t.Errorf("reloaded keymap binds %v to app.quit, want x too", keys)
+	}
+	write(keymapFile, "player x nope\n")
+	if r := reload(); r == nil || r.Err == nil {
+		t.Errorf("Execute() after a bad keymap = %+v, want an error", r)
+	}
+	if r := reload(); r != nil {
+		t.Errorf("Execute() without changes = %+v, want no reload", r)
+	}
+	os.Remove(keymapFile)
+	if r := reload(); r == nil || r.Err != nil {
+		t.Errorf("Execute() after the keymap was removed = %+v, want a reload", r)
+	}
+	write(path, "volume_step = 5\n")
+	r = reload()
+	if r == nil || r.Err != nil || r.Config.Settings.VolumeStep != 5 {
+		t.Errorf("Execute() after the config changed = %+v, want a reload", r)
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"neofy/internal/keymap"
+	"os"
+	"path/filepath"
+)
+
+// Directory neofy's config files are kept in, $XDG_CONFIG_HOME/neofy or ~/.config/neofy
+func configDir() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("configDir: %w", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "neofy"), nil
+}
+
+// Keymap file with bindings that go on top of the default ones
+func keymapPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", fmt.Errorf("keymapPath: %w", err)
+	}
+	return filepath.Join(dir, "keymap"), nil
+}
+
+// Default keymap with the bindings of the keymap file & the config on top, the file is optional
+func loadKeymap(bindings map[string]map[string]string) (*keymap.Keymap, error) {
+	k := keymap.Default()
+	path, err := keymapPath()
+	if err != nil {
+		return nil, fmt.Errorf("loadKeymap: %w", err)
+	}
+	_, err = os.Stat(path)
+	if err == nil {
+		err = k.Load(path)
+		if err != nil {
+			return nil, fmt.Errorf("loadKeymap: %w", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("loadKeymap: %w", err)
+	}
+	errs := []error{}
+	for mode, modeBindings := range bindings {
+		for keysStr, action := range modeBindings {
+			keys, err := keymap.ParseKeys(keysStr)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("keymap.%s: %w", mode, err))
+				continue
+			}
+			err = k.Bind(mode, keys, keymap.Action(action))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("keymap.%s.%s: %w", mode, keysStr, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	err = k.Conflicts()
+	if err != nil {
+		return nil, fmt.Errorf("keymap has conflicts:\n%w", err)
+	}
+	return k, nil
+}
@@ -6,33 +6,42 @@ import (
 	"math/rand/v2"
 	"neofy/internal/data"
 	"neofy/internal/display"
+	"neofy/internal/list"
 	"neofy/internal/mode"
 	"neofy/internal/scheduler"
 	"neofy/internal/spotify"
 	"neofy/internal/terminal"
+	"slices"
 	"strconv"
 	"time"
 )
 
-// Below is a mock config
-func InitMock() *data.AppData {
+// Below is a mock config, remote is the mock daemon's controller (nil when it isn't running)
+func InitMock(remote spotify.Controller) (*data.AppData, *Config, error) {
+	// NOTE: Loaded before the terminal is in raw mode so errors are readable
+	cfg, err := loadAppConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("InitMock: %w", err)
+	}
 
 	newTerm := terminal.InitAppTerm()
 
 	w, h, err := newTerm.GetTerminalSize()
 	if err != nil {
-		panic(fmt.Errorf("InitAppData: %w", err))
+		return nil, nil, fmt.Errorf("InitMock: %w", err)
 	}
 	newDisplay := *display.InitDisplay(w, h)
-	mpHeight := int(float64(newDisplay.Height) * 0.10)
 	progressMs := time.Millisecond * 1000 * 7
-	prog := 30000
+	mockPlaylists := createRandPlaylist()
+	var controller spotify.Controller = newMockController(mockPlaylists)
+	if remote != nil {
+		controller = remote
+		mockPlaylists, err = remote.GetUserPlaylists("")
+		if err != nil {
+			return nil, nil, fmt.Errorf("InitMock: %w", err)
+		}
+	}
 	mp := data.MusicPlayer{
-		Display: data.Display{
-			Width:  newDisplay.Width - 2,
-			Height: mpHeight,
-			Screen: make([]string, mpHeight),
-		},
 		IsPlaying:      true,
 		IsShuffled:     false,
 		SupportsVolume: true,
@@ -44,49 +53,57 @@ func InitMock() *data.AppData {
 			Duration: time.Millisecond * 1000 * 60,
 			Progress: &progressMs,
 		},
-		Controller: &mockController{
-			isPlaying:  true,
-			isShuffled: false,
-			volume:     77,
-			repeat:     "off",
-			songName:   "Init Song",
-			songArtist: "Init Art",
-			duration:   60000,
-			progress:   &prog,
-		},
-	}
-	playlists := createRandPlaylist()
-	posY := 0
-	curPlaylist := playlists[posY]
-	newPlaylist := data.Playlist{
-		CursorPosY:       posY,
-		RowOffset:        0,
-		SelectedPlaylist: &curPlaylist,
-		Display: data.Display{
-			Width:  int(float64(newDisplay.Width)*0.25) - 1,
-			Height: int(float64(newDisplay.Height)*0.9) - 1,
-		},
-		Playlists: playlists,
+		Controller: controller,
 	}
-	newSongs := data.Tracks{
-		Display: data.Display{
-			Width:  int(float64(newDisplay.Width)*0.75) - 1,
-			Height: int(float64(newDisplay.Height)*0.9) - 1,
-		},
-		Tracks:    []data.TrackDetail{{Name: "T1"}, {Name: "T2"}, {Name: "T3"}},
-		RowOffset: 0,
+	playlists := []data.PlaylistDetail{}
+	for _, p := range mockPlaylists {
+		playlists = append(playlists, toMockPlaylistDetail(p))
 	}
+	newPlaylist := data.Playlist{List: list.New(playlists, 0)}
+	newPlaylist.Selected = 0
+	newSongs := data.Tracks{List: list.New([]data.TrackDetail{{Name: "T1"}, {Name: "T2"}, {Name: "T3"}}, 0)}
 	newConfig := data.AppData{
 		Display:  newDisplay,
+		History:  data.History{List: list.New([]data.HistoryDetail{}, 0)},
+		Messages: data.Messages{List: list.New([]data.Message{}, 0)},
 		Mode:     &mode.Player{},
+		Picker:   data.PlaylistPicker{List: list.New([]data.PlaylistDetail{}, 0)},
 		Playlist: newPlaylist,
 		Player:   mp,
 		Songs:    newSongs,
 		Spotify:  spotify.Config{RefreshSchedular: *scheduler.CreateSchedular(time.Now(), time.Hour, nil)},
 		Term:     newTerm,
 	}
+	Apply(&newConfig, cfg)
+	mode.Startup(&newConfig, cfg.StartupMode)
 
-	return &newConfig
+	return &newConfig, cfg, nil
+}
+
+// Controller & tokens for commands that run without the TUI, nothing is kept between runs
+func InitMockController() (spotify.Controller, spotify.TokenSource, error) {
+	tokens := func() (string, error) { return "mock-token", nil }
+	return newMockController(createRandPlaylist()), tokens, nil
+}
+
+func newMockController(playlists []spotify.SlimPlaylistData) *mockController {
+	prog := 30000
+	return &mockController{
+		isPlaying:  true,
+		isShuffled: false,
+		volume:     77,
+		repeat:     "off",
+		songName:   "Init Song",
+		songArtist: "Init Art",
+		duration:   60000,
+		progress:   &prog,
+		playlists:  playlists,
+		devices: []spotify.SlimDevice{
+			{Id: "mock-computer", IsActive: true, Name: "Mock Computer", SupportsVolume: true, Type: "Computer", Volume: 77},
+			{Id: "mock-phone", Name: "Mock Phone", Type: "Smartphone"},
+			{Id: "mock-speaker", Name: "Living Room Speaker", SupportsVolume: true, Type: "Speaker", Volume: 40},
+		},
+	}
 }
 
 type mockController struct {
@@ -96,6 +113,10 @@ type mockController struct {
 	repeat     string
 	songName   string
 	songArtist string
+	songUri    string
+	snapshot   int
+	playlists  []spotify.SlimPlaylistData
+	devices    []spotify.SlimDevice
 	duration   int
 	progress   *int
 }
@@ -107,6 +128,7 @@ func (m *mockController) PlaybackState(string) (*spotify.SlimPlayerData, error)
 		SupportsVolume: true,
 		Volume:         m.volume,
 		SongName:       m.songName,
+		SongUri:        m.songUri,
 		Artist:         m.songArtist,
 		Repeat:         m.repeat,
 		SongDuration:   m.duration,
@@ -129,6 +151,7 @@ func (m *mockController) SkipToNext(string) error {
 	num := rand.IntN(100)
 	m.songName = "Song " + strconv.Itoa(num)
 	m.songArtist = "Artist for " + strconv.Itoa(num)
+	m.songUri = "spotify:track:" + strconv.Itoa(num)
 	return nil
 }
 
@@ -136,13 +159,14 @@ func (m *mockController) SkipToPrevious(string) error {
 	num := rand.IntN(100) - 100
 	m.songName = "Song " + strconv.Itoa(num)
 	m.songArtist = "Artist for " + strconv.Itoa(num)
+	m.songUri = "spotify:track:" + strconv.Itoa(num)
 	return nil
 }
 
 func (m *mockController) SetPlaybackVolume(_ string, volume int) error {
 	if volume > 100 {
 		return nil
-	} else if volume < 100 {
+	} else if volume < 0 {
 		return nil
 	}
 	m.volume = volume
@@ -154,6 +178,7 @@ func (m *mockController) CurrentPlayingTrack(string) (*spotify.SlimCurrentSongDa
 		IsPlaying:    m.isPlaying,
 		IsShuffled:   m.isShuffled,
 		SongName:     m.songName,
+		SongUri:      m.songUri,
 		Artist:       m.songArtist,
 		Repeat:       m.repeat,
 		SongDuration: m.duration,
@@ -177,34 +202,200 @@ func (m *mockController) ShuffleMode(_ string, b bool) error {
 }
 
 func (m *mockController) GetUserPlaylists(accessToken string) ([]spotify.SlimPlaylistData, error) {
-	randLen := rand.IntN(50) + 1
-	playlists := []spotify.SlimPlaylistData{}
-	for i := 1; i <= randLen; i++ {
-		newP := spotify.SlimPlaylistData{Name: "P" + strconv.Itoa(i)}
-		playlists = append(playlists, newP)
-	}
-	return playlists, nil
+	return slices.Clone(m.playlists), nil
 }
 
 func (m *mockController) GetTracksFromPlaylist(string, string, int) ([]spotify.SlimTrackInfo, error) {
 	randLen := rand.IntN(50) + 1
 	mocks := []spotify.SlimTrackInfo{}
 	for i := 1; i <= randLen; i++ {
-		mocks = append(mocks, spotify.SlimTrackInfo{Name: "Song" + strconv.Itoa(i)})
+		mocks = append(mocks, spotify.SlimTrackInfo{
+			Name:       "Song" + strconv.Itoa(i),
+			ContextUri: "spotify:track:" + strconv.Itoa(i),
+			Artist:     []spotify.SlimArtistInfo{{Name: "Artist " + strconv.Itoa(rand.IntN(10))}},
+		})
 	}
 	return mocks, nil
 }
 
+func (m *mockController) GetPlaylist(hrefUrl, accessToken string) (*spotify.SlimPlaylistWithTracks, error) {
+	for _, p := range m.playlists {
+		if p.DetailRefUrl == hrefUrl || p.TracksHref == hrefUrl {
+			tracks, _ := m.GetTracksFromPlaylist(p.TracksHref, accessToken, p.TotalTracks)
+			return &spotify.SlimPlaylistWithTracks{PlaylistName: p.Name, Tracks: tracks, ContextUri: p.ContextUri}, nil
+		}
+	}
+	return nil, errors.New("GetPlaylist: playlist not found")
+}
+
 func (m *mockController) StartTrack(contextUri, accessToken string, i int) error {
 	return nil
 }
 
-func createRandPlaylist() []data.PlaylistDetail {
+func createRandPlaylist() []spotify.SlimPlaylistData {
 	randLen := rand.IntN(77) + 1
-	p := []data.PlaylistDetail{}
+	p := []spotify.SlimPlaylistData{}
 	for i := 1; i <= randLen; i++ {
-		newP := data.PlaylistDetail{Name: "P" + strconv.Itoa(i)}
-		p = append(p, newP)
+		p = append(p, newMockPlaylist(strconv.Itoa(i), "P"+strconv.Itoa(i)))
 	}
 	return p
 }
+
+func newMockPlaylist(id, name string) spotify.SlimPlaylistData {
+	return spotify.SlimPlaylistData{
+		Id:         id,
+		Name:       name,
+		TracksHref: "mock/playlists/" + id + "/tracks",
+		ContextUri: "spotify:playlist:" + id,
+		OwnerId:    "mock-user",
+	}
+}
+
+func toMockPlaylistDetail(p spotify.SlimPlaylistData) data.PlaylistDetail {
+	return data.PlaylistDetail{
+		Collaborative: p.Collaborative,
+		ContextUri:    p.ContextUri,
+		Description:   p.Description,
+		Href:          p.TracksHref,
+		Id:            p.Id,
+		Name:          p.Name,
+		NumSongs:      p.TotalTracks,
+		Public:        p.Public,
+		SnapshotId:    p.SnapshotId,
+	}
+}
+
+func (m *mockController) PlayUri(uri, accessToken string) error {
+	m.songName = "Uri " + uri
+	m.songArtist = "Uri Artist"
+	m.songUri = uri
+	return nil
+}
+
+func (m *mockController) RecentlyPlayed(accessToken, before, after string, limit int) (*spotify.SlimRecentlyPlayed, error) {
+	if after != "" {
+		return &spotify.SlimRecentlyPlayed{After: after}, nil
+	}
+	end := time.Now()
+	if before != "" {
+		ms, err := strconv.ParseInt(before, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("RecentlyPlayed: %w", err)
+		}
+		end = time.UnixMilli(ms)
+	}
+	items := []spotify.SlimPlayHistory{}
+	for i := 1; i <= limit; i++ {
+		num := rand.IntN(100)
+		items = append(items, spotify.SlimPlayHistory{
+			Track: spotify.SlimTrackInfo{
+				Name:       "Song " + strconv.Itoa(num),
+				ContextUri: "spotify:track:" + strconv.Itoa(num),
+				DurationMs: 60000,
+				Artist:     []spotify.SlimArtistInfo{{Name: "Artist for " + strconv.Itoa(num)}},
+			},
+			PlayedAt:    end.Add(-time.Duration(i) * 3 * time.Minute),
+			ContextType: "playlist",
+			ContextUri:  "spotify:playlist:" + strconv.Itoa(rand.IntN(5)+1),
+		})
+	}
+	r := spotify.SlimRecentlyPlayed{
+		Items:  items,
+		Before: strconv.FormatInt(items[len(items)-1].PlayedAt.UnixMilli(), 10),
+		After:  strconv.FormatInt(items[0].PlayedAt.UnixMilli(), 10),
+	}
+	return &r, nil
+}
+
+func (m *mockController) AddTracksToPlaylist(tracksHref, accessToken string, uris []string, position int) (string, error) {
+	for i := range m.playlists {
+		if m.playlists[i].TracksHref == tracksHref {
+			m.playlists[i].TotalTracks += len(uris)
+		}
+	}
+	m.snapshot++
+	return "mock-snapshot-" + strconv.Itoa(m.snapshot), nil
+}
+
+func (m *mockController) RemoveTracksFromPlaylist(tracksHref, accessToken, snapshotId string, uris []string) (string, error) {
+	m.snapshot++
+	return "mock-snapshot-" + strconv.Itoa(m.snapshot), nil
+}
+
+func (m *mockController) ReorderPlaylistTracks(tracksHref, accessToken, snapshotId string, rangeStart, insertBefore, rangeLength int) (string, error) {
+	m.snapshot++
+	return "mock-snapshot-" + strconv.Itoa(m.snapshot), nil
+}
+
+func (m *mockController) CurrentUserId(accessToken string) (string, error) {
+	return "mock-user", nil
+}
+
+func (m *mockController) CreatePlaylist(userId, accessToken string, changes spotify.PlaylistChanges) (*spotify.SlimPlaylistData, error) {
+	if changes.Name == nil || *changes.Name == "" {
+		return nil, errors.New("CreatePlaylist: playlist name is required")
+	}
+	m.snapshot++
+	p := newMockPlaylist("new-"+strconv.Itoa(m.snapshot), *changes.Name)
+	m.playlists = append(m.playlists, p)
+	return &p, nil
+}
+
+func (m *mockController) ChangePlaylistDetails(playlistId, accessToken string, changes spotify.PlaylistChanges) error {
+	for i := range m.playlists {
+		if m.playlists[i].Id != playlistId {
+			continue
+		}
+		if changes.Name != nil {
+			m.playlists[i].Name = *changes.Name
+		}
+		if changes.Description != nil {
+			m.playlists[i].Description = *changes.Description
+		}
+		if changes.Public != nil {
+			m.playlists[i].Public = *changes.Public
+		}
+		if changes.Collaborative != nil {
+			m.playlists[i].Collaborative = *changes.Collaborative
+		}
+		return nil
+	}
+	return errors.New("ChangePlaylistDetails: playlist not found")
+}
+
+func (m *mockController) UnfollowPlaylist(playlistId, accessToken string) error {
+	for i := range m.playlists {
+		if m.playlists[i].Id == playlistId {
+			m.playlists = slices.Delete(m.playlists, i, i+1)
+			return nil
+		}
+	}
+	return errors.New("UnfollowPlaylist: playlist not found")
+}
+
+func (m *mockController) SeekToPosition(accessToken string, positionMs int) error {
+	if positionMs < 0 || positionMs > m.duration {
+		return errors.New("SeekToPosition: position out of range")
+	}
+	m.progress = &positionMs
+	return nil
+}
+
+func (m *mockController) AvailableDevices(accessToken string) ([]spotify.SlimDevice, error) {
+	return slices.Clone(m.devices), nil
+}
+
+func (m *mockController) TransferPlayback(accessToken, deviceId string, play bool) error {
+	found := false
+	for i := range m.devices {
+		m.devices[i].IsActive = m.devices[i].Id == deviceId
+		found = found || m.devices[i].IsActive
+	}
+	if !found {
+		return errors.New("TransferPlayback: device not found")
+	}
+	if play {
+		m.isPlaying = true
+	}
+	return nil
+}
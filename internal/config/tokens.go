@@ -0,0 +1,142 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"neofy/internal/spotify"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// NOTE: Spotify access tokens last an hour, the cached one is used for a bit less
+const accessTokenLifetime = 55 * time.Minute
+
+// Tokens of the last login, kept so the browser login is only needed once
+type tokenCache struct {
+	AccessToken  string    `json:"access_token"`
+	ExpiresAt    time.Time `json:"expires_at"` // When the access token stops being used
+	RefreshToken string    `json:"refresh_token"`
+}
+
+// $XDG_CACHE_HOME/neofy, ~/.cache/neofy when it isn't set
+func cacheDir() (string, error) {
+	dir := os.Getenv("XDG_CACHE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cacheDir: %w", err)
+		}
+		dir = filepath.Join(home, ".cache")
+	}
+	return filepath.Join(dir, "neofy"), nil
+}
+
+func tokenCachePath() (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", fmt.Errorf("tokenCachePath: %w", err)
+	}
+	return filepath.Join(dir, "tokens.json"), nil
+}
+
+func readTokenCache() (*tokenCache, error) {
+	path, err := tokenCachePath()
+	if err != nil {
+		return nil, fmt.Errorf("readTokenCache: %w", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("readTokenCache: %w", err)
+	}
+	var c tokenCache
+	err = json.Unmarshal(b, &c)
+	if err != nil {
+		return nil, fmt.Errorf("readTokenCache: %s: %w", path, err)
+	}
+	if c.RefreshToken == "" {
+		return nil, errors.New("readTokenCache: " + path + ": no refresh token")
+	}
+	return &c, nil
+}
+
+// Saves the tokens of u, the access token was just handed out
+func writeTokenCache(u spotify.User) error {
+	path, err := tokenCachePath()
+	if err != nil {
+		return fmt.Errorf("writeTokenCache: %w", err)
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return fmt.Errorf("writeTokenCache: %w", err)
+	}
+	b, err := json.Marshal(tokenCache{
+		AccessToken:  u.AccessToken,
+		ExpiresAt:    time.Now().Add(accessTokenLifetime),
+		RefreshToken: u.RefreshToken,
+	})
+	if err != nil {
+		return fmt.Errorf("writeTokenCache: %w", err)
+	}
+	// NOTE: Only the user can read the tokens
+	err = os.WriteFile(path, b, 0o600)
+	if err != nil {
+		return fmt.Errorf("writeTokenCache: %w", err)
+	}
+	return nil
+}
+
+// Tokens from the cache, the browser login is used when there are none or they stopped working.
+// With reuseAccess a cached access token that hasn't expired is used as is, otherwise new tokens are
+// always fetched (the refresh scheduler expects a fresh access token)
+func userTokens(clientId, clientSecret string, reuseAccess bool) (spotify.User, error) {
+	cache, err := readTokenCache()
+	if err == nil {
+		if reuseAccess && time.Now().Before(cache.ExpiresAt) {
+			return spotify.User{AccessToken: cache.AccessToken, RefreshToken: cache.RefreshToken}, nil
+		}
+		u, err := refreshTokens(cache.RefreshToken, clientId, clientSecret)
+		if err == nil {
+			return u, nil
+		}
+		slog.Warn("cached tokens stopped working, logging in again", "err", err)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("token cache ignored", "err", err)
+	}
+
+	code, err := spotify.LoginUser(clientId)
+	if err != nil {
+		return spotify.User{}, fmt.Errorf("userTokens: %w", err)
+	}
+	accessT, refreshT, err := spotify.UserAccessAndRefreshToken(code, clientId, clientSecret)
+	if err != nil {
+		return spotify.User{}, fmt.Errorf("userTokens: %w", err)
+	}
+	u := spotify.User{AccessToken: accessT, RefreshToken: refreshT}
+	saveTokens(u)
+	return u, nil
+}
+
+func refreshTokens(refreshToken, clientId, clientSecret string) (spotify.User, error) {
+	accessT, refreshT, err := spotify.RefreshUserTokens(refreshToken, clientId, clientSecret)
+	if err != nil {
+		return spotify.User{}, fmt.Errorf("refreshTokens: %w", err)
+	}
+	// NOTE: When a refresh token is not returned, continue using the existing token
+	if refreshT == "" {
+		refreshT = refreshToken
+	}
+	u := spotify.User{AccessToken: accessT, RefreshToken: refreshT}
+	saveTokens(u)
+	return u, nil
+}
+
+// A cache that can't be written means logging in again next time, the app keeps going
+func saveTokens(u spotify.User) {
+	err := writeTokenCache(u)
+	if err != nil {
+		slog.Warn("tokens not cached", "err", err)
+	}
+}
@@ -1,22 +1,91 @@
 package consts
 
+// NOTE: Key codes live in a private use plane of unicode so they never collide with typed text
+const keyCodeBase = 0xF0000
+
+const (
+	NOTHINGKEY       = keyCodeBase + iota //This key will do nothing when pressed
+	CONTROLCASCII                         // CTRL-C
+	CONTROLFIRSTBYTE                      // Unused
+	LEFT_ARROW
+	RIGHT_ARROW
+	DOWN_ARROW
+	UP_ARROW
+	PAGE_UP
+	PAGE_DOWN
+	HOME_KEY
+	END_KEY
+	DEL_KEY
+	ESC
+	BACKSPACE
+	CONTROL_S
+	CONTROL_F
+	CONTROL_U
+	CONTROL_D
+	TAB
+	INSERT_KEY
+	PASTE // Bracketed paste, the text comes with the key event
+	MOUSE // Click or wheel, where it happened comes with the key event
+	F1
+	F2
+	F3
+	F4
+	F5
+	F6
+	F7
+	F8
+	F9
+	F10
+	F11
+	F12
+)
+
+// Modifier keys held down with a key, see WithMods
+type Modifier int
+
 const (
-	NOTHINGKEY       = 999 //This key will do nothing when pressed
-	CONTROLCASCII    = 1000
-	CONTROLFIRSTBYTE = 1001
-	LEFT_ARROW       = 1002
-	RIGHT_ARROW      = 1003
-	DOWN_ARROW       = 1004
-	UP_ARROW         = 1005
-	PAGE_UP          = 1006
-	PAGE_DOWN        = 1007
-	HOME_KEY         = 1008
-	END_KEY          = 1009
-	DEL_KEY          = 1010
-	ESC              = 1011
-	BACKSPACE        = 1012
-	CONTROL_S        = 1013
-	CONTROL_F        = 1014
-	CONTROL_U        = 1015
-	CONTROL_D        = 1016
+	ModShift Modifier = 1 << iota
+	ModAlt
+	ModCtrl
 )
+
+// Typed runes with modifiers (<A-x>, <C-a>) live in the plane after the key codes
+const modRuneBase = 0x100000
+
+// Largest rune that can carry modifiers, the modifiers go in the bits above it
+const maxModRune = 0xFFF
+
+// Returns true when r is one of the key codes above or a key with modifiers instead of typed text
+func IsKeyCode(r rune) bool {
+	return r >= keyCodeBase
+}
+
+// Folds mods into r so a key with modifiers is still a single rune.
+// NOTE: Runes above 0xFFF lose their modifiers, Shift is left out of typed runes
+// since the terminal sends the shifted rune (A) instead
+func WithMods(r rune, mods Modifier) rune {
+	base, held := SplitMods(r)
+	mods |= held
+	if base >= keyCodeBase {
+		if mods == 0 {
+			return base
+		}
+		return keyCodeBase + rune(mods)<<12 + (base - keyCodeBase)
+	}
+	mods &^= ModShift
+	if mods == 0 || base > maxModRune {
+		return base
+	}
+	return modRuneBase + rune(mods)<<12 + base
+}
+
+// Splits a rune from WithMods back into the key & the modifiers held with it
+func SplitMods(r rune) (rune, Modifier) {
+	switch {
+	case r >= modRuneBase:
+		return (r - modRuneBase) & maxModRune, Modifier((r - modRuneBase) >> 12)
+	case r >= keyCodeBase:
+		return keyCodeBase + (r-keyCodeBase)&maxModRune, Modifier((r - keyCodeBase) >> 12)
+	}
+	return r, 0
+}
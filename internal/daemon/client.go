@@ -0,0 +1,242 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"neofy/internal/spotify"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"sync"
+	"time"
+)
+
+// Controller that sends every request to the daemon, the access tokens passed in are ignored
+type Controller struct {
+	client  *rpc.Client
+	mu      sync.Mutex
+	path    string
+	timeout time.Duration // How long a request waits for the daemon's answer
+}
+
+// The daemon runs one spotify request at a time, a slow one holds up the rest.
+// NOTE: Long enough for a slow request & the ones queued behind it
+const callTimeout = 10 * time.Second
+
+// Connects to the daemon listening at path, fails when there is none
+func Dial(path string) (*Controller, error) {
+	client, err := dial(path)
+	if err != nil {
+		return nil, fmt.Errorf("Dial: %w", err)
+	}
+	return &Controller{client: client, path: path, timeout: callTimeout}, nil
+}
+
+func dial(path string) (*rpc.Client, error) {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("dial: %w", err)
+	}
+	return rpc.NewClientWithCodec(jsonrpc.NewClientCodec(conn)), nil
+}
+
+// Token source of clients, the daemon has the tokens
+func Tokens() (string, error) {
+	return "", nil
+}
+
+// Calls Player.<method>, connecting again when the daemon was restarted
+func (c *Controller) call(method string, args any, reply any) error {
+	c.mu.Lock()
+	client := c.client
+	c.mu.Unlock()
+	err := c.wait(client, method, args, reply)
+	// NOTE: Requests that were sent when the connection broke aren't sent again, they could have ran
+	if errors.Is(err, rpc.ErrShutdown) {
+		client, err = c.redial(client)
+		if err != nil {
+			return fmt.Errorf("%s: daemon: %w", method, err)
+		}
+		err = c.wait(client, method, args, reply)
+	}
+	var serverErr rpc.ServerError
+	if errors.As(err, &serverErr) {
+		return decodeError(string(serverErr))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: daemon: %w", method, err)
+	}
+	return nil
+}
+
+// Sends the request & waits for the answer until the timeout, an answer that comes later is dropped
+func (c *Controller) wait(client *rpc.Client, method string, args any, reply any) error {
+	call := client.Go("Player."+method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+		return call.Error
+	case <-time.After(c.timeout):
+		return errors.New("the daemon didn't answer in " + c.timeout.String())
+	}
+}
+
+// NOTE: Another request could have connected again already
+func (c *Controller) redial(broken *rpc.Client) (*rpc.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client != broken {
+		return c.client, nil
+	}
+	client, err := dial(c.path)
+	if err != nil {
+		return nil, fmt.Errorf("redial: %w", err)
+	}
+	broken.Close()
+	c.client = client
+	return client, nil
+}
+
+func (c *Controller) PlaybackState(string) (*spotify.SlimPlayerData, error) {
+	var reply spotify.SlimPlayerData
+	err := c.call("PlaybackState", Empty{}, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *Controller) CurrentPlayingTrack(string) (*spotify.SlimCurrentSongData, error) {
+	var reply spotify.SlimCurrentSongData
+	err := c.call("CurrentPlayingTrack", Empty{}, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *Controller) StartResumePlayback(string) error {
+	return c.call("StartResumePlayback", Empty{}, &Empty{})
+}
+
+func (c *Controller) PausePlayback(string) error {
+	return c.call("PausePlayback", Empty{}, &Empty{})
+}
+
+func (c *Controller) SkipToNext(string) error {
+	return c.call("SkipToNext", Empty{}, &Empty{})
+}
+
+func (c *Controller) SkipToPrevious(string) error {
+	return c.call("SkipToPrevious", Empty{}, &Empty{})
+}
+
+func (c *Controller) SetPlaybackVolume(_ string, volume int) error {
+	return c.call("SetPlaybackVolume", VolumeArgs{Volume: volume}, &Empty{})
+}
+
+func (c *Controller) SeekToPosition(_ string, positionMs int) error {
+	return c.call("SeekToPosition", SeekArgs{PositionMs: positionMs}, &Empty{})
+}
+
+func (c *Controller) RepeatMode(_ string, state string) error {
+	return c.call("RepeatMode", RepeatArgs{State: state}, &Empty{})
+}
+
+func (c *Controller) ShuffleMode(_ string, isShuffled bool) error {
+	return c.call("ShuffleMode", ShuffleArgs{Shuffled: isShuffled}, &Empty{})
+}
+
+func (c *Controller) StartTrack(contextUri, _ string, songIndex int) error {
+	return c.call("StartTrack", StartTrackArgs{ContextUri: contextUri, Index: songIndex}, &Empty{})
+}
+
+func (c *Controller) PlayUri(uri, _ string) error {
+	return c.call("PlayUri", UriArgs{Uri: uri}, &Empty{})
+}
+
+func (c *Controller) TransferPlayback(_, deviceId string, play bool) error {
+	return c.call("TransferPlayback", TransferArgs{DeviceId: deviceId, Play: play}, &Empty{})
+}
+
+func (c *Controller) AvailableDevices(string) ([]spotify.SlimDevice, error) {
+	var reply []spotify.SlimDevice
+	err := c.call("AvailableDevices", Empty{}, &reply)
+	return reply, err
+}
+
+func (c *Controller) GetUserPlaylists(string) ([]spotify.SlimPlaylistData, error) {
+	var reply []spotify.SlimPlaylistData
+	err := c.call("GetUserPlaylists", Empty{}, &reply)
+	return reply, err
+}
+
+func (c *Controller) GetTracksFromPlaylist(hrefUrl, _ string, numSongs int) ([]spotify.SlimTrackInfo, error) {
+	var reply []spotify.SlimTrackInfo
+	err := c.call("GetTracksFromPlaylist", HrefArgs{Href: hrefUrl, NumSongs: numSongs}, &reply)
+	return reply, err
+}
+
+func (c *Controller) GetPlaylist(hrefUrl, _ string) (*spotify.SlimPlaylistWithTracks, error) {
+	var reply spotify.SlimPlaylistWithTracks
+	err := c.call("GetPlaylist", HrefArgs{Href: hrefUrl}, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *Controller) AddTracksToPlaylist(tracksHref, _ string, uris []string, position int) (string, error) {
+	var reply string
+	err := c.call("AddTracksToPlaylist", AddTracksArgs{Position: position, TracksHref: tracksHref, Uris: uris}, &reply)
+	return reply, err
+}
+
+func (c *Controller) RemoveTracksFromPlaylist(tracksHref, _, snapshotId string, uris []string) (string, error) {
+	var reply string
+	err := c.call("RemoveTracksFromPlaylist", RemoveTracksArgs{SnapshotId: snapshotId, TracksHref: tracksHref, Uris: uris}, &reply)
+	return reply, err
+}
+
+func (c *Controller) ReorderPlaylistTracks(tracksHref, _, snapshotId string, rangeStart, insertBefore, rangeLength int) (string, error) {
+	var reply string
+	err := c.call("ReorderPlaylistTracks", ReorderArgs{
+		InsertBefore: insertBefore,
+		RangeLength:  rangeLength,
+		RangeStart:   rangeStart,
+		SnapshotId:   snapshotId,
+		TracksHref:   tracksHref,
+	}, &reply)
+	return reply, err
+}
+
+func (c *Controller) CurrentUserId(string) (string, error) {
+	var reply string
+	err := c.call("CurrentUserId", Empty{}, &reply)
+	return reply, err
+}
+
+func (c *Controller) CreatePlaylist(userId, _ string, changes spotify.PlaylistChanges) (*spotify.SlimPlaylistData, error) {
+	var reply spotify.SlimPlaylistData
+	err := c.call("CreatePlaylist", PlaylistArgs{Changes: changes, UserId: userId}, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+func (c *Controller) ChangePlaylistDetails(playlistId, _ string, changes spotify.PlaylistChanges) error {
+	return c.call("ChangePlaylistDetails", PlaylistArgs{Changes: changes, PlaylistId: playlistId}, &Empty{})
+}
+
+func (c *Controller) UnfollowPlaylist(playlistId, _ string) error {
+	return c.call("UnfollowPlaylist", PlaylistArgs{PlaylistId: playlistId}, &Empty{})
+}
+
+func (c *Controller) RecentlyPlayed(_, before, after string, limit int) (*spotify.SlimRecentlyPlayed, error) {
+	var reply spotify.SlimRecentlyPlayed
+	err := c.call("RecentlyPlayed", RecentlyPlayedArgs{After: after, Before: before, Limit: limit}, &reply)
+	if err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
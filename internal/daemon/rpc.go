@@ -0,0 +1,121 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"neofy/internal/spotify"
+)
+
+// NOTE: The api is JSON-RPC 1.0 (net/rpc/jsonrpc), methods are Player.<Controller method> (Player.SkipToNext)
+// & take one of the args below. Access tokens are never sent, the daemon has it's own
+
+// Args & reply of methods without any
+type Empty struct{}
+
+type VolumeArgs struct {
+	Volume int
+}
+
+type SeekArgs struct {
+	PositionMs int
+}
+
+type RepeatArgs struct {
+	State string // off, context or track
+}
+
+type ShuffleArgs struct {
+	Shuffled bool
+}
+
+type HrefArgs struct {
+	Href     string
+	NumSongs int // Only used by GetTracksFromPlaylist
+}
+
+type StartTrackArgs struct {
+	ContextUri string
+	Index      int
+}
+
+type UriArgs struct {
+	Uri string
+}
+
+type AddTracksArgs struct {
+	Position   int // -1 adds them to the end
+	TracksHref string
+	Uris       []string
+}
+
+type RemoveTracksArgs struct {
+	SnapshotId string
+	TracksHref string
+	Uris       []string
+}
+
+type ReorderArgs struct {
+	InsertBefore int
+	RangeLength  int
+	RangeStart   int
+	SnapshotId   string
+	TracksHref   string
+}
+
+type PlaylistArgs struct {
+	Changes    spotify.PlaylistChanges
+	PlaylistId string // Only used to change & unfollow
+	UserId     string // Only used to create
+}
+
+type RecentlyPlayedArgs struct {
+	After  string
+	Before string
+	Limit  int
+}
+
+type TransferArgs struct {
+	DeviceId string
+	Play     bool
+}
+
+// Errors are sent as json in the error of the response so clients can tell why a request failed
+type failure struct {
+	Message    string `json:"message"`
+	NoPlayback bool   `json:"no_playback,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Status     int    `json:"status,omitempty"` // Set when spotify answered with an error
+}
+
+func encodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	f := failure{Message: err.Error(), NoPlayback: errors.Is(err, spotify.ErrNoPlayback)}
+	var apiErr *spotify.APIError
+	if errors.As(err, &apiErr) {
+		f.Message, f.Reason, f.Status = apiErr.Message, apiErr.Reason, apiErr.Status
+	}
+	b, jsonErr := json.Marshal(f)
+	if jsonErr != nil {
+		return err
+	}
+	return errors.New(string(b))
+}
+
+// Turns an error sent by the daemon back into the error the controller returned
+func decodeError(msg string) error {
+	var f failure
+	err := json.Unmarshal([]byte(msg), &f)
+	if err != nil {
+		return errors.New(msg)
+	}
+	switch {
+	case f.NoPlayback:
+		return fmt.Errorf("daemon: %w", spotify.ErrNoPlayback)
+	case f.Status != 0:
+		return &spotify.APIError{Message: f.Message, Reason: f.Reason, Status: f.Status}
+	}
+	return errors.New(f.Message)
+}
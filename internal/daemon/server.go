@@ -0,0 +1,500 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"neofy/internal/mpris"
+	"neofy/internal/spotify"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Playlists & their tracks are fetched again after this, edits made through the daemon clear them right away
+const playlistLifetime = time.Minute
+
+// Serves the controller on the socket at path until ctrl-c or SIGTERM, playback is polled every interval.
+// Only one daemon can listen on a socket
+func Serve(w io.Writer, path string, controller spotify.Controller, tokens spotify.TokenSource, interval time.Duration) error {
+	// NOTE: A socket that is left behind by a daemon that crashed can't be connected to
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err == nil {
+		conn.Close()
+		return errors.New("Serve: a daemon is already running on " + path)
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return fmt.Errorf("Serve: %w", err)
+	}
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("Serve: %w", err)
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("Serve: %w", err)
+	}
+	defer l.Close()
+	// Only the user can control their playback
+	err = os.Chmod(path, 0o600)
+	if err != nil {
+		return fmt.Errorf("Serve: %w", err)
+	}
+
+	p := newPlayer(controller, tokens, interval)
+	p.media, err = mpris.Start()
+	if err != nil {
+		slog.Debug("mpris not started", "err", err)
+	} else {
+		defer p.media.Close()
+		go p.runMedia()
+	}
+	server := rpc.NewServer()
+	err = server.RegisterName("Player", p)
+	if err != nil {
+		return fmt.Errorf("Serve: %w", err)
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go p.poll(stop)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		sig := <-signals
+		slog.Info("daemon stopping", "signal", sig)
+		l.Close()
+	}()
+
+	slog.Info("daemon listening", "socket", path, "interval", interval)
+	fmt.Fprintln(w, "Listening on "+path)
+	for {
+		conn, err := l.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Serve: %w", err)
+		}
+		slog.Debug("daemon client connected")
+		go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+// Runs the controller for every client, one request at a time.
+// NOTE: The exported methods are the rpc api, see rpc.go
+type Player struct {
+	calls      sync.Mutex // One request to spotify at a time, the mock isn't safe to share. Taken before mu
+	controller spotify.Controller
+	interval   time.Duration
+	media      *mpris.Server // Nil when there is no session bus
+	tokens     spotify.TokenSource
+
+	// NOTE: mu guards the caches & is never held while waiting on spotify, clients reading them aren't held up by a slow request
+	mu          sync.Mutex
+	playlists   []spotify.SlimPlaylistData // Nil when they need to be fetched
+	playlistsAt time.Time
+	state       *spotify.SlimPlayerData
+	stateAt     time.Time               // Zero when the state needs to be fetched
+	stateErr    error                   // Why the last poll failed, clients get it until the next poll
+	tracks      map[string]cachedTracks // By tracks href
+}
+
+type cachedTracks struct {
+	at     time.Time
+	tracks []spotify.SlimTrackInfo
+}
+
+func newPlayer(controller spotify.Controller, tokens spotify.TokenSource, interval time.Duration) *Player {
+	return &Player{
+		controller: controller,
+		interval:   interval,
+		tokens:     tokens,
+		tracks:     map[string]cachedTracks{},
+	}
+}
+
+// Keeps the playback state fresh so clients polling it don't each hit spotify
+func (p *Player) poll(stop <-chan struct{}) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.safePublish()
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			p.forgetState()
+		}
+	}
+}
+
+// A response spotify sends that the controller can't handle shouldn't take every client down with the daemon.
+// Used by the poll & after media commands
+func (p *Player) safePublish() {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("daemon publish panicked", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+	p.publish()
+}
+
+// Sends the state to MPRIS, fetching it when it's old
+func (p *Player) publish() {
+	state, err := p.playbackState()
+	switch {
+	case errors.Is(err, spotify.ErrNoPlayback):
+		p.media.Update(spotify.StoppedStatus())
+	case err != nil:
+		slog.Debug("daemon poll failed", "err", err)
+	default:
+		p.media.Update(spotify.NewStatus(state))
+	}
+}
+
+// Runs what media keys & desktop widgets asked for, the new state is published right away
+func (p *Player) runMedia() {
+	for c := range p.media.Commands() {
+		p.mu.Lock()
+		state := p.state
+		p.mu.Unlock()
+		err := p.call(func(accessToken string) error {
+			defer p.forgetState()
+			return p.mediaCommand(accessToken, state, c)
+		})
+		if err != nil {
+			slog.Error("mpris command failed", "action", c.Action, "err", err)
+		}
+		p.safePublish()
+	}
+}
+
+// State is what was last polled, nil when nothing is playing
+func (p *Player) mediaCommand(accessToken string, state *spotify.SlimPlayerData, c mpris.Command) error {
+	progress, playing := 0, false
+	if state != nil {
+		playing = state.IsPlaying
+		if state.SongProgress != nil {
+			progress = *state.SongProgress
+		}
+	}
+	switch c.Action {
+	case mpris.Play:
+		return p.controller.StartResumePlayback(accessToken)
+	case mpris.Pause, mpris.Stop:
+		return p.controller.PausePlayback(accessToken)
+	case mpris.PlayPause:
+		if playing {
+			return p.controller.PausePlayback(accessToken)
+		}
+		return p.controller.StartResumePlayback(accessToken)
+	case mpris.Next:
+		return p.controller.SkipToNext(accessToken)
+	case mpris.Previous:
+		return p.controller.SkipToPrevious(accessToken)
+	case mpris.Seek:
+		return p.controller.SeekToPosition(accessToken, max(progress+int(c.Position.Milliseconds()), 0))
+	case mpris.SetPosition:
+		return p.controller.SeekToPosition(accessToken, int(c.Position.Milliseconds()))
+	case mpris.SetVolume:
+		return p.controller.SetPlaybackVolume(accessToken, c.Volume)
+	case mpris.SetShuffle:
+		return p.controller.ShuffleMode(accessToken, c.Shuffle)
+	case mpris.SetRepeat:
+		return p.controller.RepeatMode(accessToken, c.Repeat)
+	case mpris.OpenUri:
+		return p.controller.PlayUri(c.Uri, accessToken)
+	}
+	return nil
+}
+
+// The cached state while it's from this interval, otherwise it's fetched
+func (p *Player) playbackState() (*spotify.SlimPlayerData, error) {
+	state, fresh, err := p.cachedState()
+	if fresh {
+		return state, err
+	}
+	err = p.call(func(accessToken string) error {
+		// Another client may have fetched it while this one waited
+		var fresh bool
+		state, fresh, err = p.cachedState()
+		if fresh {
+			return err
+		}
+		state, err = p.controller.PlaybackState(accessToken)
+		// NOTE: Stored before calls is let go, so an action that comes after can't be undone by it
+		p.mu.Lock()
+		p.state, p.stateErr, p.stateAt = state, err, time.Now()
+		p.mu.Unlock()
+		return err
+	})
+	return state, err
+}
+
+// Fresh is false when the state needs to be fetched
+func (p *Player) cachedState() (*spotify.SlimPlayerData, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stateAt.IsZero() || time.Since(p.stateAt) >= p.interval {
+		return nil, false, nil
+	}
+	return p.state, true, p.stateErr
+}
+
+// Playback changed, it's fetched again on the next request
+func (p *Player) forgetState() {
+	p.mu.Lock()
+	p.stateAt = time.Time{}
+	p.mu.Unlock()
+}
+
+// Runs a request that changes playback, the state is fetched again after it
+func (p *Player) action(run func(accessToken string) error) error {
+	err := p.call(func(accessToken string) error {
+		// NOTE: Forgotten before calls is let go, no client can get the state from before the action
+		defer p.forgetState()
+		return run(accessToken)
+	})
+	return encodeError(err)
+}
+
+// Runs a request to spotify with a fresh access token, the token can need a request of it's own
+func (p *Player) call(run func(accessToken string) error) error {
+	p.calls.Lock()
+	defer p.calls.Unlock()
+	accessToken, err := p.tokens()
+	if err != nil {
+		return fmt.Errorf("call: %w", err)
+	}
+	return run(accessToken)
+}
+
+// Runs a request that returns something, methods can't have type parameters
+func fetch[T any](p *Player, reply *T, run func(accessToken string) (T, error)) error {
+	err := p.call(func(accessToken string) error {
+		v, err := run(accessToken)
+		if err != nil {
+			return err
+		}
+		*reply = v
+		return nil
+	})
+	return encodeError(err)
+}
+
+// Playlists changed, they are fetched again on the next request
+func (p *Player) forgetPlaylists(tracksHref string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.playlists = nil
+	delete(p.tracks, tracksHref)
+}
+
+func (p *Player) PlaybackState(_ Empty, reply *spotify.SlimPlayerData) error {
+	state, err := p.playbackState()
+	if err != nil {
+		return encodeError(err)
+	}
+	*reply = *state
+	return nil
+}
+
+// The TUI polls this, it's the polled state so every pane shares one request
+func (p *Player) CurrentPlayingTrack(_ Empty, reply *spotify.SlimCurrentSongData) error {
+	state, err := p.playbackState()
+	if err != nil {
+		return encodeError(err)
+	}
+	*reply = spotify.SlimCurrentSongData{
+		IsPlaying:    state.IsPlaying,
+		IsShuffled:   state.IsShuffled,
+		SongName:     state.SongName,
+		SongUri:      state.SongUri,
+		Artist:       state.Artist,
+		Repeat:       state.Repeat,
+		SongDuration: state.SongDuration,
+		SongProgress: state.SongProgress,
+	}
+	return nil
+}
+
+func (p *Player) StartResumePlayback(_ Empty, _ *Empty) error {
+	return p.action(p.controller.StartResumePlayback)
+}
+
+func (p *Player) PausePlayback(_ Empty, _ *Empty) error {
+	return p.action(p.controller.PausePlayback)
+}
+
+func (p *Player) SkipToNext(_ Empty, _ *Empty) error {
+	return p.action(p.controller.SkipToNext)
+}
+
+func (p *Player) SkipToPrevious(_ Empty, _ *Empty) error {
+	return p.action(p.controller.SkipToPrevious)
+}
+
+func (p *Player) SetPlaybackVolume(args VolumeArgs, _ *Empty) error {
+	return p.action(func(accessToken string) error {
+		return p.controller.SetPlaybackVolume(accessToken, args.Volume)
+	})
+}
+
+func (p *Player) SeekToPosition(args SeekArgs, _ *Empty) error {
+	return p.action(func(accessToken string) error {
+		return p.controller.SeekToPosition(accessToken, args.PositionMs)
+	})
+}
+
+func (p *Player) RepeatMode(args RepeatArgs, _ *Empty) error {
+	return p.action(func(accessToken string) error {
+		return p.controller.RepeatMode(accessToken, args.State)
+	})
+}
+
+func (p *Player) ShuffleMode(args ShuffleArgs, _ *Empty) error {
+	return p.action(func(accessToken string) error {
+		return p.controller.ShuffleMode(accessToken, args.Shuffled)
+	})
+}
+
+func (p *Player) StartTrack(args StartTrackArgs, _ *Empty) error {
+	return p.action(func(accessToken string) error {
+		return p.controller.StartTrack(args.ContextUri, accessToken, args.Index)
+	})
+}
+
+func (p *Player) PlayUri(args UriArgs, _ *Empty) error {
+	return p.action(func(accessToken string) error {
+		return p.controller.PlayUri(args.Uri, accessToken)
+	})
+}
+
+func (p *Player) TransferPlayback(args TransferArgs, _ *Empty) error {
+	return p.action(func(accessToken string) error {
+		return p.controller.TransferPlayback(accessToken, args.DeviceId, args.Play)
+	})
+}
+
+func (p *Player) AvailableDevices(_ Empty, reply *[]spotify.SlimDevice) error {
+	return fetch(p, reply, p.controller.AvailableDevices)
+}
+
+func (p *Player) GetUserPlaylists(_ Empty, reply *[]spotify.SlimPlaylistData) error {
+	return fetch(p, reply, func(accessToken string) ([]spotify.SlimPlaylistData, error) {
+		p.mu.Lock()
+		playlists, at := p.playlists, p.playlistsAt
+		p.mu.Unlock()
+		if playlists != nil && time.Since(at) < playlistLifetime {
+			return playlists, nil
+		}
+		playlists, err := p.controller.GetUserPlaylists(accessToken)
+		if err != nil {
+			return nil, err
+		}
+		p.mu.Lock()
+		p.playlists, p.playlistsAt = playlists, time.Now()
+		p.mu.Unlock()
+		return playlists, nil
+	})
+}
+
+func (p *Player) GetTracksFromPlaylist(args HrefArgs, reply *[]spotify.SlimTrackInfo) error {
+	return fetch(p, reply, func(accessToken string) ([]spotify.SlimTrackInfo, error) {
+		p.mu.Lock()
+		cached, ok := p.tracks[args.Href]
+		p.mu.Unlock()
+		if ok && time.Since(cached.at) < playlistLifetime {
+			return cached.tracks, nil
+		}
+		tracks, err := p.controller.GetTracksFromPlaylist(args.Href, accessToken, args.NumSongs)
+		if err != nil {
+			return nil, err
+		}
+		p.mu.Lock()
+		p.tracks[args.Href] = cachedTracks{at: time.Now(), tracks: tracks}
+		p.mu.Unlock()
+		return tracks, nil
+	})
+}
+
+func (p *Player) GetPlaylist(args HrefArgs, reply *spotify.SlimPlaylistWithTracks) error {
+	return fetch(p, reply, func(accessToken string) (spotify.SlimPlaylistWithTracks, error) {
+		playlist, err := p.controller.GetPlaylist(args.Href, accessToken)
+		if err != nil {
+			return spotify.SlimPlaylistWithTracks{}, err
+		}
+		return *playlist, nil
+	})
+}
+
+func (p *Player) AddTracksToPlaylist(args AddTracksArgs, reply *string) error {
+	return fetch(p, reply, func(accessToken string) (string, error) {
+		p.forgetPlaylists(args.TracksHref)
+		return p.controller.AddTracksToPlaylist(args.TracksHref, accessToken, args.Uris, args.Position)
+	})
+}
+
+func (p *Player) RemoveTracksFromPlaylist(args RemoveTracksArgs, reply *string) error {
+	return fetch(p, reply, func(accessToken string) (string, error) {
+		p.forgetPlaylists(args.TracksHref)
+		return p.controller.RemoveTracksFromPlaylist(args.TracksHref, accessToken, args.SnapshotId, args.Uris)
+	})
+}
+
+func (p *Player) ReorderPlaylistTracks(args ReorderArgs, reply *string) error {
+	return fetch(p, reply, func(accessToken string) (string, error) {
+		p.forgetPlaylists(args.TracksHref)
+		return p.controller.ReorderPlaylistTracks(args.TracksHref, accessToken, args.SnapshotId, args.RangeStart, args.InsertBefore, args.RangeLength)
+	})
+}
+
+func (p *Player) CurrentUserId(_ Empty, reply *string) error {
+	return fetch(p, reply, p.controller.CurrentUserId)
+}
+
+func (p *Player) CreatePlaylist(args PlaylistArgs, reply *spotify.SlimPlaylistData) error {
+	return fetch(p, reply, func(accessToken string) (spotify.SlimPlaylistData, error) {
+		p.forgetPlaylists("")
+		playlist, err := p.controller.CreatePlaylist(args.UserId, accessToken, args.Changes)
+		if err != nil {
+			return spotify.SlimPlaylistData{}, err
+		}
+		return *playlist, nil
+	})
+}
+
+func (p *Player) ChangePlaylistDetails(args PlaylistArgs, _ *Empty) error {
+	return p.action(func(accessToken string) error {
+		p.forgetPlaylists("")
+		return p.controller.ChangePlaylistDetails(args.PlaylistId, accessToken, args.Changes)
+	})
+}
+
+func (p *Player) UnfollowPlaylist(args PlaylistArgs, _ *Empty) error {
+	return p.action(func(accessToken string) error {
+		p.forgetPlaylists("")
+		return p.controller.UnfollowPlaylist(args.PlaylistId, accessToken)
+	})
+}
+
+func (p *Player) RecentlyPlayed(args RecentlyPlayedArgs, reply *spotify.SlimRecentlyPlayed) error {
+	return fetch(p, reply, func(accessToken string) (spotify.SlimRecentlyPlayed, error) {
+		history, err := p.controller.RecentlyPlayed(accessToken, args.Before, args.After, args.Limit)
+		if err != nil {
+			return spotify.SlimRecentlyPlayed{}, err
+		}
+		return *history, nil
+	})
+}
@@ -0,0 +1,157 @@
+package daemon
+
+import (
+	"neofy/internal/spotify"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Counts the state requests, release holds them until it's closed when it's set
+type countingController struct {
+	spotify.Controller
+	panics  atomic.Bool
+	release chan struct{}
+	states  atomic.Int32
+}
+
+func (c *countingController) PlaybackState(string) (*spotify.SlimPlayerData, error) {
+	c.states.Add(1)
+	if c.release != nil {
+		<-c.release
+	}
+	if c.panics.Load() {
+		panic("bad response")
+	}
+	progress := 1000
+	return &spotify.SlimPlayerData{Artist: "Artist", IsPlaying: true, SongName: "Song", SongProgress: &progress}, nil
+}
+
+func (c *countingController) SkipToNext(string) error {
+	return nil
+}
+
+func testTokens() (string, error) {
+	return "token", nil
+}
+
+// Every TUI pane polls CurrentPlayingTrack, they share the polled state
+func TestCurrentPlayingTrackIsCached(t *testing.T) {
+	c := &countingController{}
+	p := newPlayer(c, testTokens, time.Minute)
+	for range 5 {
+		var song spotify.SlimCurrentSongData
+		err := p.CurrentPlayingTrack(Empty{}, &song)
+		if err != nil {
+			t.Fatalf("CurrentPlayingTrack() = %v", err)
+		}
+		if song.SongName != "Song" || song.Artist != "Artist" || !song.IsPlaying || *song.SongProgress != 1000 {
+			t.Errorf("CurrentPlayingTrack() = %+v, want the polled state", song)
+		}
+	}
+	if n := c.states.Load(); n != 1 {
+		t.Errorf("spotify was asked for the state %d times, want 1", n)
+	}
+	// Actions make the next request fetch it again
+	err := p.SkipToNext(Empty{}, &Empty{})
+	if err != nil {
+		t.Fatalf("SkipToNext() = %v", err)
+	}
+	var song spotify.SlimCurrentSongData
+	err = p.CurrentPlayingTrack(Empty{}, &song)
+	if err != nil {
+		t.Fatalf("CurrentPlayingTrack() = %v", err)
+	}
+	if n := c.states.Load(); n != 2 {
+		t.Errorf("spotify was asked for the state %d times after an action, want 2", n)
+	}
+}
+
+// A client reading the cached state isn't held up by a request that is waiting on spotify
+func TestCachedStateWhileFetching(t *testing.T) {
+	c := &countingController{}
+	p := newPlayer(c, testTokens, time.Minute)
+	_, err := p.playbackState()
+	if err != nil {
+		t.Fatalf("playbackState() = %v", err)
+	}
+	c.release = make(chan struct{})
+	defer close(c.release)
+	p.SkipToNext(Empty{}, &Empty{})
+	go p.playbackState()
+	for c.states.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	done := make(chan struct{})
+	go func() {
+		p.mu.Lock()
+		p.mu.Unlock()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("mu is held while waiting on spotify")
+	}
+}
+
+// A poll that panics is logged, the daemon keeps running
+func TestPollRecovers(t *testing.T) {
+	c := &countingController{}
+	c.panics.Store(true)
+	p := newPlayer(c, testTokens, time.Minute)
+	p.safePublish()
+	c.panics.Store(false)
+	p.forgetState()
+	state, err := p.playbackState()
+	if err != nil || state.SongName != "Song" {
+		t.Errorf("playbackState() after a panic = %+v, %v", state, err)
+	}
+}
+
+// A daemon stuck on spotify fails the request after the timeout instead of freezing the client
+func TestCallTimeout(t *testing.T) {
+	c := &countingController{release: make(chan struct{})}
+	defer close(c.release)
+	server := rpc.NewServer()
+	err := server.RegisterName("Player", newPlayer(c, testTokens, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "daemon.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+		}
+	}()
+
+	remote, err := Dial(path)
+	if err != nil {
+		t.Fatalf("Dial() = %v", err)
+	}
+	remote.timeout = 50 * time.Millisecond
+	start := time.Now()
+	_, err = remote.PlaybackState("")
+	if err == nil {
+		t.Fatal("PlaybackState() on a stuck daemon = nil, want an error")
+	}
+	if took := time.Since(start); took > time.Second {
+		t.Errorf("PlaybackState() took %v, want it to give up after the timeout", took)
+	}
+	if reason := spotify.ErrorReason(err); reason != "the daemon didn't answer in 50ms" {
+		t.Errorf("ErrorReason() = %q, want the daemon's timeout", reason)
+	}
+}
@@ -0,0 +1,23 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Where the daemon listens, $XDG_RUNTIME_DIR/neofy or a dir for the user in the temp dir when it isn't set.
+// The mock daemon (-t) has it's own socket so it's never mistaken for spotify
+func SocketPath(mock bool) string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "neofy-"+strconv.Itoa(os.Getuid()))
+	} else {
+		dir = filepath.Join(dir, "neofy")
+	}
+	name := "daemon.sock"
+	if mock {
+		name = "daemon-mock.sock"
+	}
+	return filepath.Join(dir, name)
+}
@@ -2,6 +2,8 @@ package data
 
 import (
 	"neofy/internal/display"
+	"neofy/internal/keymap"
+	"neofy/internal/list"
 	"neofy/internal/spotify"
 	"neofy/internal/terminal"
 	"time"
@@ -10,31 +12,73 @@ import (
 // TODO: Abstract Spotify & Music Player into a interface
 
 type AppData struct {
-	Display  display.Display
-	Mode     Mode
-	Playlist Playlist
-	Player   MusicPlayer
-	Songs    Tracks
-	Spotify  spotify.Config
-	Term     terminal.AppTerm
+	Command   CommandLine
+	Display   display.Display
+	Help      Help
+	History   History
+	Keymap    *keymap.Keymap
+	LastClick Click
+	Messages  Messages
+	Mode      Mode
+	Pending   keymap.Pending // Keys typed that wait for the rest of a binding
+	Picker    PlaylistPicker
+	Playlist  Playlist
+	Player    MusicPlayer
+	Prompt    Prompt
+	Quitting  bool // The app stops once the input that set it is handled
+	Settings  Settings
+	Songs     Tracks
+	Spinner   Spinner // Nil until the app sets it up
+	Spotify   spotify.Config
+	Term      terminal.AppTerm
 }
 
+// Selected is the playlist the tracks are loaded from
 type Playlist struct {
-	CursorPosY       int
-	Display          Display
-	Playlists        []PlaylistDetail
-	RowOffset        int
-	SelectedPlaylist *PlaylistDetail //Display only
+	list.List[PlaylistDetail]
+	Display Display
+}
+
+type History struct {
+	list.List[HistoryDetail]
+	After   string // Cursor used to fetch newer items
+	Before  string // Cursor used to fetch older items, empty when exhausted
+	Display Display
+}
+
+type HistoryDetail struct {
+	ContextType string // playlist, album, artist or empty
+	ContextUri  string
+	PlayedAt    time.Time
+	Track       TrackDetail
+}
+
+// Used to pick a playlist that a track will be added to
+type PlaylistPicker struct {
+	list.List[PlaylistDetail]
+	Display Display
+	Track   TrackDetail
 }
 
 type PlaylistDetail struct {
-	Href       string
-	Name       string
-	NumSongs   int
-	ContextUri string
+	Collaborative bool
+	ContextUri    string
+	Description   string
+	Href          string
+	Id            string
+	Name          string
+	NumSongs      int
+	Public        bool
+	SnapshotId    string // Changes every time the playlist is edited
+}
+
+// Text that is shown & filtered on for a playlist
+func (p PlaylistDetail) Label() string {
+	return p.Name
 }
 
 type MusicPlayer struct {
+	Buttons        []PlayerButton // Where the controls were drawn
 	Controller     spotify.Controller
 	Display        Display // What to show in cli
 	IsPlaying      bool    // Is something playing
@@ -45,12 +89,19 @@ type MusicPlayer struct {
 	Volume         int    // 0-100
 }
 
+// Control of the player that can be clicked, X & Y are from the top left of the player
+type PlayerButton struct {
+	Action keymap.Action // Empty for the volume bar
+	Volume int           // Volume a click on the volume bar sets
+	Width  int
+	X      int
+	Y      int
+}
+
+// Selected is the track that was last played from the list
 type Tracks struct {
-	CursorPosY    int
-	Display       Display
-	RowOffset     int
-	SelectedTrack *TrackDetail
-	Tracks        []TrackDetail
+	list.List[TrackDetail]
+	Display Display
 }
 
 type TrackDetail struct {
@@ -60,14 +111,110 @@ type TrackDetail struct {
 	Name       string
 }
 
+// Text that is shown & filtered on for a track
+func (t TrackDetail) Label() string {
+	label := t.Name
+	for i, a := range t.Artists {
+		if i == 0 {
+			label += " - " + a.Name
+		} else {
+			label += ", " + a.Name
+		}
+	}
+	return label
+}
+
 type ArtistDetail struct {
 	Name string
 }
 
+// Pane of the screen, X & Y are where it's drawn from the top left of the screen
 type Display struct {
 	Height int
 	Screen []string
 	Width  int
+	X      int
+	Y      int
+}
+
+// Where & when the last left click was, to tell double clicks apart
+type Click struct {
+	At time.Time
+	X  int
+	Y  int
+}
+
+// Commands ran from the command line, oldest first
+type CommandLine struct {
+	History []string
+}
+
+// Feedback for the user, the newest message is shown in the status line for a while
+type Messages struct {
+	list.List[Message] // Oldest first
+	Display            Display
+}
+
+// How important a message is, sets the style it's shown in
+type Level int
+
+const (
+	Info Level = iota
+	Success
+	Warning
+	Error
+)
+
+func (l Level) String() string {
+	switch l {
+	case Success:
+		return "success"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+	return "info"
+}
+
+type Message struct {
+	At    time.Time
+	Count int // Times it was sent in a row
+	Level Level
+	Text  string
+}
+
+// Tags of the levels in the messages, the same width so the text lines up
+var levelTags = map[Level]string{
+	Info:    "INFO ",
+	Success: "OK   ",
+	Warning: "WARN ",
+	Error:   "ERROR",
+}
+
+// NOTE: Has the time & level so they can be filtered on too
+func (m Message) Label() string {
+	return m.At.Format("15:04:05") + " " + levelTags[m.Level] + " " + m.Text
+}
+
+// Shows that the app is waiting on a request, the app doesn't draw until it's done
+type Spinner interface {
+	Start(label string)
+	Stop()
+}
+
+// Key binds shown over the panes while in help mode
+type Help struct {
+	Height int // Lines that fit in the box, set once it's drawn
+	Lines  []string
+	Offset int // First line shown
+	Title  string
+}
+
+// Line of text the user is typing
+type Prompt struct {
+	Input []rune
+	Label string
 }
 
 type Song struct {
@@ -75,6 +222,45 @@ type Song struct {
 	Duration time.Duration
 	Name     string
 	Progress *time.Duration
+	Uri      string
+}
+
+// Behavior & looks that can be changed in the config file while the app runs
+type Settings struct {
+	Layout        Layout
+	PlayerRefresh time.Duration // 0 when playback isn't polled
+	Theme         Theme
+	VolumeStep    int
+}
+
+// How the panes are placed, the playlists & tracks sit side by side above or below the player
+type Layout struct {
+	Player      PaneLayout // Sized by height
+	PlayerOnTop bool
+	Playlists   PaneLayout // Sized by width
+	Tracks      PaneLayout // Sized by width, takes what the playlists don't
+}
+
+// Size of a pane in rows or columns, see layout.Pane
+type PaneLayout struct {
+	Hidden bool // Hidden panes are still shown while their mode is active
+	Max    int  // 0 when there is no max
+	Min    int
+	Size   float64
+}
+
+// Styles are SGR parameters (1;30;44), used as \033[<style>m. Parts with a style of "" are drawn as is
+type Theme struct {
+	Border   string
+	Cursor   string
+	Error    string
+	Header   string
+	Info     string          // Messages & the spinner
+	Modes    map[rune]string // Mode badge in the header by ShortDisplay
+	Playing  string
+	Selected string
+	Success  string
+	Warning  string
 }
 
 type Mode interface {
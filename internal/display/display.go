@@ -8,6 +8,9 @@ type Display struct {
 	Buffer strings.Builder
 	Width  int
 	Height int
+	Redraw bool // Clears the whole screen on the next draw
+	frame  *Frame
+	last   *Frame // What the terminal shows
 }
 
 func InitDisplay(w, h int) *Display {
@@ -20,3 +23,21 @@ func InitDisplay(w, h int) *Display {
 	}
 	return &display
 }
+
+// Sizes the display to a terminal that is w by h, everything is drawn again
+func (d *Display) Resize(w, h int) {
+	d.Width = w
+	d.Height = h
+	d.Redraw = true
+}
+
+// Row y is written again on the next draw, for when something other than Render drew over it
+func (d *Display) ForgetRow(y int) {
+	if d.last == nil || y < 0 || y >= d.last.Height {
+		return
+	}
+	for x := 0; x < d.last.Width; x++ {
+		// NOTE: No cell of a frame has a Char of \x00 so every cell of the row counts as changed
+		*d.last.at(x, y) = Cell{Char: "\x00"}
+	}
+}
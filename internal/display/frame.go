@@ -0,0 +1,253 @@
+package display
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// Looks of a cell, colors are SGR parameters (44, 48;5;25) & "" is the terminal's default
+type Style struct {
+	Bg        string
+	Bold      bool
+	Fg        string
+	Reverse   bool
+	Underline bool
+}
+
+// Char is a whole character (é, 👩‍💻), the cell after a wide character has a Char of ""
+type Cell struct {
+	Char  string
+	Style Style
+}
+
+var blankCell = Cell{Char: " "}
+
+// Cells of the screen the app draws a frame into, rows are stored one after another
+type Frame struct {
+	cells  []Cell
+	Height int
+	Width  int
+}
+
+func newFrame(w, h int) *Frame {
+	f := Frame{
+		cells:  make([]Cell, w*h),
+		Height: h,
+		Width:  w,
+	}
+	f.Clear()
+	return &f
+}
+
+func (f *Frame) Clear() {
+	for i := range f.cells {
+		f.cells[i] = blankCell
+	}
+}
+
+func (f *Frame) at(x, y int) *Cell {
+	return &f.cells[y*f.Width+x]
+}
+
+// Writes str from x, y until the end of the row. SGR escapes (\033[44m) in str style
+// the cells after them, str starts with the default style
+func (f *Frame) Put(x, y int, str string) {
+	if y < 0 || y >= f.Height {
+		return
+	}
+	style := Style{}
+	state := -1
+	for len(str) > 0 && x < f.Width {
+		if strings.HasPrefix(str, "\033[") {
+			end := strings.IndexFunc(str[2:], func(r rune) bool { return r >= '@' && r <= '~' })
+			if end < 0 {
+				return
+			}
+			if str[2+end] == 'm' {
+				style = applySGR(style, str[2:2+end])
+			}
+			str = str[3+end:]
+			state = -1
+			continue
+		}
+		var char string
+		var width int
+		char, str, width, state = uniseg.FirstGraphemeClusterInString(str, state)
+		// NOTE: Control characters have no width & would move the terminal's cursor
+		if width == 0 {
+			continue
+		}
+		if x+width > f.Width {
+			return
+		}
+		f.set(x, y, Cell{Char: char, Style: style})
+		if width == 2 {
+			f.set(x+1, y, Cell{Style: style})
+		}
+		x += width
+	}
+}
+
+// Sets a cell, wide characters that are partly covered are blanked out
+func (f *Frame) set(x, y int, c Cell) {
+	old := f.at(x, y)
+	if old.Char == "" && c.Char != "" && x > 0 {
+		*f.at(x-1, y) = blankCell
+	}
+	// NOTE: The right half of a wide character can cover the left half of another one too
+	if old.Char != "" && x+1 < f.Width && f.at(x+1, y).Char == "" {
+		*f.at(x+1, y) = blankCell
+	}
+	*old = c
+}
+
+// Applies the parameters of an SGR escape (the 1;44 of \033[1;44m) to s
+func applySGR(s Style, params string) Style {
+	p := strings.Split(params, ";")
+	for i := 0; i < len(p); i++ {
+		switch code := p[i]; code {
+		case "", "0":
+			s = Style{}
+		case "1":
+			s.Bold = true
+		case "22":
+			s.Bold = false
+		case "4":
+			s.Underline = true
+		case "24":
+			s.Underline = false
+		case "7":
+			s.Reverse = true
+		case "27":
+			s.Reverse = false
+		case "39":
+			s.Fg = ""
+		case "49":
+			s.Bg = ""
+		case "38", "48":
+			// 256 colors (38;5;n) & true color (38;2;r;g;b) take up more than 1 parameter
+			n := 0
+			if i+1 < len(p) && p[i+1] == "5" {
+				n = 2
+			} else if i+1 < len(p) && p[i+1] == "2" {
+				n = 4
+			}
+			if i+n >= len(p) {
+				return s
+			}
+			color := strings.Join(p[i:i+n+1], ";")
+			if code == "38" {
+				s.Fg = color
+			} else {
+				s.Bg = color
+			}
+			i += n
+		default:
+			if len(code) == 2 && (code[0] == '3' || code[0] == '9') && code[1] <= '7' {
+				s.Fg = code
+			} else if (len(code) == 2 && code[0] == '4' && code[1] <= '7') || (len(code) == 3 && code[:2] == "10" && code[2] <= '7') {
+				s.Bg = code
+			}
+		}
+	}
+	return s
+}
+
+// SGR escape that sets the terminal to s from any style
+func (s Style) sgr() string {
+	params := []string{"0"}
+	if s.Bold {
+		params = append(params, "1")
+	}
+	if s.Underline {
+		params = append(params, "4")
+	}
+	if s.Reverse {
+		params = append(params, "7")
+	}
+	if s.Fg != "" {
+		params = append(params, s.Fg)
+	}
+	if s.Bg != "" {
+		params = append(params, s.Bg)
+	}
+	return "\033[" + strings.Join(params, ";") + "m"
+}
+
+// Blank frame the size of the display to draw the next screen in
+func (d *Display) NewFrame() *Frame {
+	if d.frame == nil || d.frame.Width != d.Width || d.frame.Height != d.Height {
+		d.frame = newFrame(d.Width, d.Height)
+	} else {
+		d.frame.Clear()
+	}
+	return d.frame
+}
+
+// NOTE: Rewriting a few unchanged cells is shorter than moving the cursor past them
+const maxSkip = 6
+
+// Writes the cells of the new frame that changed since the last one to Buffer, the cursor
+// is only moved between runs of changes. The whole frame is written after a Redraw
+func (d *Display) Render() {
+	if d.frame == nil {
+		d.NewFrame()
+	}
+	cur := d.frame
+	if d.Redraw || d.last == nil || d.last.Width != cur.Width || d.last.Height != cur.Height {
+		// A cleared screen matches a blank frame
+		d.Buffer.WriteString("\033[0m\033[2J")
+		d.last = newFrame(cur.Width, cur.Height)
+		d.Redraw = false
+	}
+	style := Style{}
+	d.Buffer.WriteString(style.sgr())
+	for y := 0; y < cur.Height; y++ {
+		row := cur.cells[y*cur.Width : (y+1)*cur.Width]
+		lastRow := d.last.cells[y*cur.Width : (y+1)*cur.Width]
+		x := 0
+		for x < cur.Width {
+			if row[x] == lastRow[x] {
+				x++
+				continue
+			}
+			// Wide characters are written from their first cell
+			if row[x].Char == "" && x > 0 {
+				x--
+			}
+			end := changedRun(row, lastRow, x)
+			d.Buffer.WriteString("\033[" + strconv.Itoa(y+1) + ";" + strconv.Itoa(x+1) + "H")
+			for ; x < end; x++ {
+				if row[x].Style != style {
+					style = row[x].Style
+					d.Buffer.WriteString(style.sgr())
+				}
+				d.Buffer.WriteString(row[x].Char)
+			}
+		}
+	}
+	d.Buffer.WriteString("\033[0m")
+	d.frame, d.last = d.last, d.frame
+}
+
+// End of the changes that start at x, changes with up to maxSkip unchanged cells between them are joined
+func changedRun(row, lastRow []Cell, x int) int {
+	end := x
+	for end < len(row) {
+		if row[end] != lastRow[end] {
+			end++
+			continue
+		}
+		next := end
+		for next < len(row) && next-end <= maxSkip && row[next] == lastRow[next] {
+			next++
+		}
+		if next == len(row) || next-end > maxSkip {
+			break
+		}
+		end = next
+	}
+	return end
+}
@@ -0,0 +1,177 @@
+package display
+
+import (
+	"strings"
+	"testing"
+)
+
+// Characters of row y, the cells after wide characters add nothing
+func rowText(f *Frame, y int) string {
+	var b strings.Builder
+	for x := 0; x < f.Width; x++ {
+		b.WriteString(f.at(x, y).Char)
+	}
+	return b.String()
+}
+
+func TestPut(t *testing.T) {
+	type put struct {
+		x   int
+		str string
+	}
+	tests := []struct {
+		name string
+		puts []put
+		want string
+	}{
+		{"text", []put{{0, "abc"}}, "abc   "},
+		{"past the end", []put{{4, "abc"}}, "    ab"},
+		{"wide", []put{{0, "日本"}}, "日本  "},
+		{"wide past the end", []put{{5, "日"}}, "      "},
+		{"combining", []put{{0, "e\u0301x"}}, "e\u0301x    "},
+		{"zwj emoji", []put{{0, "👩‍💻x"}}, "👩‍💻x   "},
+		{"control characters", []put{{0, "a\tb"}}, "ab    "},
+		{"escapes", []put{{0, "\033[1ma\033[2Kb"}}, "ab    "},
+		{"first half of a wide char", []put{{0, "日本"}, {0, "a"}}, "a 本  "},
+		{"second half of a wide char", []put{{0, "日本"}, {1, "a"}}, " a本  "},
+		{"wide over the second half of a wide char", []put{{0, "日本"}, {1, "x"}, {1, "語"}}, " 語   "},
+		{"wide over two wide chars", []put{{0, "日本"}, {1, "語"}}, " 語   "},
+		{"wide over the first half of a wide char", []put{{2, "日"}, {1, "語"}}, " 語   "},
+		{"text over a wide char", []put{{0, "日本"}, {0, "a本"}}, "a本   "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFrame(6, 1)
+			for _, p := range tt.puts {
+				f.Put(p.x, 0, p.str)
+			}
+			if got := rowText(f, 0); got != tt.want {
+				t.Errorf("row = %q, want %q", got, tt.want)
+			}
+			// Every empty cell has to belong to the wide character before it
+			for x := 0; x < f.Width; x++ {
+				if f.at(x, 0).Char == "" && (x == 0 || f.at(x-1, 0).Char == "") {
+					t.Errorf("cell %d of %q is the end of a wide character that isn't there", x, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPutStyle(t *testing.T) {
+	f := newFrame(4, 1)
+	f.Put(0, 0, "\033[1;38;5;25ma\033[48;2;1;2;3mb\033[0mc")
+	want := []Style{
+		{Bold: true, Fg: "38;5;25"},
+		{Bold: true, Fg: "38;5;25", Bg: "48;2;1;2;3"},
+		{},
+		{},
+	}
+	for x, style := range want {
+		if got := f.at(x, 0).Style; got != style {
+			t.Errorf("cell %d style = %+v, want %+v", x, got, style)
+		}
+	}
+}
+
+func TestApplySGR(t *testing.T) {
+	tests := []struct {
+		from   Style
+		params string
+		want   Style
+	}{
+		{Style{}, "1;44", Style{Bold: true, Bg: "44"}},
+		{Style{}, "4;7;31", Style{Underline: true, Reverse: true, Fg: "31"}},
+		{Style{}, "91;104", Style{Fg: "91", Bg: "104"}},
+		{Style{}, "38;5;25", Style{Fg: "38;5;25"}},
+		{Style{}, "48;5;196;1", Style{Bg: "48;5;196", Bold: true}},
+		{Style{}, "38;2;255;0;10", Style{Fg: "38;2;255;0;10"}},
+		{Style{}, "38;2;1;2;3;48;5;4", Style{Fg: "38;2;1;2;3", Bg: "48;5;4"}},
+		{Style{Fg: "31"}, "38;5", Style{Fg: "31"}},
+		{Style{Fg: "31"}, "38;2;1;2", Style{Fg: "31"}},
+		{Style{Bold: true, Fg: "31", Bg: "44"}, "0", Style{}},
+		{Style{Bold: true, Fg: "31", Bg: "44"}, "", Style{}},
+		{Style{Bold: true, Fg: "31", Bg: "44"}, "22;39", Style{Bg: "44"}},
+		{Style{Underline: true, Reverse: true, Bg: "48;5;4"}, "24;27;49", Style{}},
+		{Style{Fg: "31"}, "0;32", Style{Fg: "32"}},
+		{Style{}, "38;5;25;0", Style{}},
+		{Style{}, "5;97", Style{Fg: "97"}},
+		{Style{}, "108;3", Style{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.params, func(t *testing.T) {
+			if got := applySGR(tt.from, tt.params); got != tt.want {
+				t.Errorf("applySGR(%+v, %q) = %+v, want %+v", tt.from, tt.params, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	d := InitDisplay(20, 2)
+	// Draws lines in a new frame & returns what Render writes for it
+	render := func(lines ...string) string {
+		f := d.NewFrame()
+		for y, line := range lines {
+			f.Put(0, y, line)
+		}
+		d.Buffer.Reset()
+		d.Render()
+		return d.Buffer.String()
+	}
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		// The first frame clears the screen & skips the blank cells
+		{"first", []string{"hello", "  x"}, "\033[0m\033[2J\033[0m\033[1;1Hhello\033[2;3Hx\033[0m"},
+		{"unchanged", []string{"hello", "  x"}, "\033[0m\033[0m"},
+		{"one cell", []string{"help", "  x"}, "\033[0m\033[1;4Hp \033[0m"},
+		{"changes close together are joined", []string{"help", "y x  z"}, "\033[0m\033[2;1Hy x  z\033[0m"},
+		{"changes far apart", []string{"Help", "y x  z        w"}, "\033[0m\033[1;1HH\033[2;15Hw\033[0m"},
+		{"style", []string{"H\033[1mel\033[0mp", "y x  z        w"}, "\033[0m\033[1;2H\033[0;1mel\033[0m"},
+		{"removed", []string{"", ""}, "\033[0m\033[1;1H    \033[2;1H      \033[2;15H \033[0m"},
+		{"wide", []string{"日本"}, "\033[0m\033[1;1H日本\033[0m"},
+		{"wide over wide", []string{"a本"}, "\033[0m\033[1;1Ha本 \033[0m"},
+		{"wide moved", []string{"a 本"}, "\033[0m\033[1;2H 本\033[0m"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := render(tt.lines...); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// After a Redraw the screen is cleared & the whole frame is written, whatever was drawn before
+func TestRenderRedraw(t *testing.T) {
+	d := InitDisplay(8, 2)
+	d.NewFrame().Put(0, 0, "abc")
+	d.Render()
+
+	f := d.NewFrame()
+	f.Put(0, 0, "abc")
+	f.Put(2, 1, "\033[7mdef")
+	d.Redraw = true
+	d.Buffer.Reset()
+	d.Render()
+	want := "\033[0m\033[2J\033[0m\033[1;1Habc\033[2;3H\033[0;7mdef\033[0m"
+	if got := d.Buffer.String(); got != want {
+		t.Errorf("Render() after a Redraw = %q, want %q", got, want)
+	}
+	if d.Redraw {
+		t.Error("Redraw is still set after Render()")
+	}
+
+	// A resize draws everything again too
+	d.Resize(6, 1)
+	d.NewFrame().Put(0, 0, "abc")
+	d.Buffer.Reset()
+	d.Render()
+	want = "\033[0m\033[2J\033[0m\033[1;1Habc\033[0m"
+	if got := d.Buffer.String(); got != want {
+		t.Errorf("Render() after a Resize = %q, want %q", got, want)
+	}
+}
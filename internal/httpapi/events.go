@@ -0,0 +1,143 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"neofy/internal/spotify"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Events are sent at least this often so proxies & browsers don't close the stream
+const keepAlive = 15 * time.Second
+
+// Sends the status polled for events to every stream, so more remotes don't mean more requests to spotify
+type broadcaster struct {
+	mu      sync.Mutex
+	last    *spotify.Status // Last status that was sent, nil while no one is listening
+	lastAt  time.Time
+	streams map[chan spotify.Status]bool
+	wake    chan struct{} // Polls right away for a stream that has nothing to start with
+}
+
+func newBroadcaster() *broadcaster {
+	return &broadcaster{streams: map[chan spotify.Status]bool{}, wake: make(chan struct{}, 1)}
+}
+
+// Adds a stream, it gets the last status right away or after the next poll
+func (b *broadcaster) subscribe() chan spotify.Status {
+	stream := make(chan spotify.Status, 1)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.streams[stream] = true
+	if b.last != nil {
+		stream <- *b.last
+		return stream
+	}
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+	return stream
+}
+
+func (b *broadcaster) unsubscribe(stream chan spotify.Status) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.streams, stream)
+	if len(b.streams) == 0 {
+		b.last = nil
+	}
+}
+
+func (b *broadcaster) listening() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.streams) > 0
+}
+
+// Sends status to every stream when playback changed, a stream that is behind only gets the newest
+func (b *broadcaster) publish(status spotify.Status) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.last != nil && !status.Changed(*b.last, time.Since(b.lastAt)) {
+		// Keeps the expected progress from drifting while nothing changes
+		b.last.ProgressMs, b.lastAt = status.ProgressMs, time.Now()
+		return
+	}
+	b.last, b.lastAt = &status, time.Now()
+	for stream := range b.streams {
+		select {
+		case <-stream:
+		default:
+		}
+		stream <- status
+	}
+}
+
+// Polls playback every Interval while a stream is open, until stop is closed
+func (s *server) pollEvents(stop <-chan struct{}) {
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-s.events.wake:
+		}
+		if !s.events.listening() {
+			continue
+		}
+		status, err := s.status()
+		if err != nil {
+			// Spotify going away for a bit shouldn't end the streams
+			slog.Warn("http events poll failed", "err", err)
+			continue
+		}
+		s.events.publish(status)
+	}
+}
+
+// Sends the status as a "status" event when it connects & every time playback changes
+func (s *server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming isn't supported")
+		return
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	slog.Debug("http events connected", "remote", r.RemoteAddr)
+
+	stream := s.events.subscribe()
+	defer s.events.unsubscribe(stream)
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			slog.Debug("http events closed", "remote", r.RemoteAddr)
+			return
+		case status := <-stream:
+			data, jsonErr := json.Marshal(status)
+			if jsonErr != nil {
+				return
+			}
+			_, err = fmt.Fprintf(w, "event: status\ndata: %s\n\n", data)
+			ticker.Reset(keepAlive)
+		case <-ticker.C:
+			_, err = io.WriteString(w, ": keep-alive\n\n")
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
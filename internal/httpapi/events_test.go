@@ -0,0 +1,123 @@
+package httpapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"neofy/internal/spotify"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Opens an event stream, the statuses it sends come on the channel until ctx is done
+func openEvents(t *testing.T, ctx context.Context, url string) <-chan spotify.Status {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, "GET", url+"/api/events?token="+testToken, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /api/events = %v", err)
+	}
+	statuses := make(chan spotify.Status, 16)
+	go func() {
+		defer resp.Body.Close()
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var status spotify.Status
+			if json.Unmarshal([]byte(data), &status) == nil {
+				statuses <- status
+			}
+		}
+	}()
+	return statuses
+}
+
+func nextStatus(t *testing.T, statuses <-chan spotify.Status) spotify.Status {
+	t.Helper()
+	select {
+	case status := <-statuses:
+		return status
+	case <-time.After(2 * time.Second):
+		t.Fatal("no status event")
+		return spotify.Status{}
+	}
+}
+
+// Every stream shares one poll & gets every change
+func TestEventsSharePoll(t *testing.T) {
+	c := newFakeController()
+	s := newTestServer(c)
+	stop := make(chan struct{})
+	defer close(stop)
+	go s.pollEvents(stop)
+	ts := httptest.NewServer(s.routes())
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	streams := []<-chan spotify.Status{}
+	for range 4 {
+		streams = append(streams, openEvents(t, ctx, ts.URL))
+	}
+	for i, stream := range streams {
+		if status := nextStatus(t, stream); status.State != "playing" {
+			t.Errorf("stream %d started with %+v, want the playing status", i, status)
+		}
+	}
+
+	start := c.pollCount()
+	ticks := 10
+	time.Sleep(time.Duration(ticks) * s.opts.Interval)
+	// NOTE: A stream of it's own would poll 4 times a tick
+	if polls := c.pollCount() - start; polls > ticks+3 {
+		t.Errorf("%d streams polled %d times in %d ticks, want one poll a tick", len(streams), polls, ticks)
+	}
+
+	c.setPlaying(false)
+	for i, stream := range streams {
+		if status := nextStatus(t, stream); status.State != "paused" {
+			t.Errorf("stream %d got %+v, want the paused status", i, status)
+		}
+	}
+
+	cancel()
+	for s.events.listening() {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(s.opts.Interval)
+	stopped := c.pollCount()
+	time.Sleep(5 * s.opts.Interval)
+	if polls := c.pollCount() - stopped; polls > 0 {
+		t.Errorf("polled %d times with no stream open, want none", polls)
+	}
+}
+
+// A stream that connects later starts with the last status without waiting for a poll
+func TestEventsLateStream(t *testing.T) {
+	c := newFakeController()
+	s := newTestServer(c)
+	s.opts.Interval = time.Hour
+	stop := make(chan struct{})
+	defer close(stop)
+	go s.pollEvents(stop)
+	ts := httptest.NewServer(s.routes())
+	defer ts.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nextStatus(t, openEvents(t, ctx, ts.URL))
+	if status := nextStatus(t, openEvents(t, ctx, ts.URL)); status.State != "playing" {
+		t.Errorf("late stream started with %+v, want the playing status", status)
+	}
+	if polls := c.pollCount(); polls != 1 {
+		t.Errorf("polled %d times for 2 streams, want 1", polls)
+	}
+}
@@ -0,0 +1,350 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"neofy/internal/spotify"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+//go:embed remote.html
+var remotePage []byte
+
+type Options struct {
+	Addr     string        // Has to be localhost or a loopback ip
+	Interval time.Duration // How often playback is polled for events
+	Remote   bool          // Serve the web remote on /
+	Token    string        // Bearer token clients have to send
+}
+
+type server struct {
+	controller spotify.Controller
+	events     *broadcaster
+	// NOTE: Toggle & volume changes read the state before they change it, two remotes doing it at
+	// once would both read the same state (both pause instead of pause & play). Requests run one at a time
+	mu     sync.Mutex
+	opts   Options
+	tokens spotify.TokenSource
+}
+
+// Serves the api until ctrl-c or SIGTERM, see the README for the endpoints
+func Serve(w io.Writer, opts Options, controller spotify.Controller, tokens spotify.TokenSource) error {
+	err := CheckAddr(opts.Addr)
+	if err != nil {
+		return fmt.Errorf("Serve: %w", err)
+	}
+	s := &server{controller: controller, events: newBroadcaster(), opts: opts, tokens: tokens}
+	l, err := net.Listen("tcp", opts.Addr)
+	if err != nil {
+		return fmt.Errorf("Serve: %w", err)
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go s.pollEvents(stop)
+	srv := &http.Server{Handler: s.routes(), ReadHeaderTimeout: 10 * time.Second}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		sig := <-signals
+		slog.Info("http stopping", "signal", sig)
+		// Event streams never end by themselves, they are cut off
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		err := srv.Shutdown(ctx)
+		if err != nil {
+			srv.Close()
+		}
+	}()
+
+	url := "http://" + l.Addr().String()
+	slog.Info("http listening", "addr", l.Addr().String(), "remote", opts.Remote)
+	fmt.Fprintln(w, "Listening on "+url)
+	fmt.Fprintln(w, "Token: "+opts.Token)
+	if opts.Remote {
+		fmt.Fprintln(w, "Remote: "+url+"/#token="+opts.Token)
+	}
+	err = srv.Serve(l)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return fmt.Errorf("Serve: %w", err)
+}
+
+// Only this machine can reach the api, ":8091" would listen on every interface
+func CheckAddr(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("CheckAddr: %w", err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !ip.IsLoopback() {
+		return errors.New("CheckAddr: " + addr + " isn't local, use localhost or 127.0.0.1")
+	}
+	return nil
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/status", s.handleStatus)
+	mux.HandleFunc("GET /api/events", s.handleEvents)
+	mux.HandleFunc("POST /api/play", s.action(func(accessToken string) error {
+		return s.controller.StartResumePlayback(accessToken)
+	}))
+	mux.HandleFunc("POST /api/pause", s.action(func(accessToken string) error {
+		return s.controller.PausePlayback(accessToken)
+	}))
+	mux.HandleFunc("POST /api/toggle", s.action(s.toggle))
+	mux.HandleFunc("POST /api/next", s.action(func(accessToken string) error {
+		return s.controller.SkipToNext(accessToken)
+	}))
+	mux.HandleFunc("POST /api/previous", s.action(func(accessToken string) error {
+		return s.controller.SkipToPrevious(accessToken)
+	}))
+	mux.HandleFunc("POST /api/volume", s.handleVolume)
+	mux.HandleFunc("POST /api/seek", s.handleSeek)
+	mux.HandleFunc("POST /api/shuffle", s.handleShuffle)
+	mux.HandleFunc("POST /api/repeat", s.handleRepeat)
+	mux.HandleFunc("POST /api/play-uri", s.handlePlayUri)
+
+	api := s.authorize(mux)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// NOTE: Any origin can call the api, the token is what keeps others out (no cookies are used)
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST")
+		switch {
+		case r.Method == http.MethodOptions:
+			w.WriteHeader(http.StatusNoContent)
+		case r.URL.Path == "/" && s.opts.Remote:
+			// The page asks for the token itself, it's in the fragment that isn't sent to the server
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write(remotePage)
+		default:
+			api.ServeHTTP(w, r)
+		}
+	})
+}
+
+// The token is the Authorization: Bearer header, or ?token= for EventSource that can't set headers
+func (s *server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+			token = bearer
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, "missing or wrong token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Runs a request with a fresh access token, one at a time
+func (s *server) run(run func(accessToken string) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	accessToken, err := s.tokens()
+	if err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+	return run(accessToken)
+}
+
+// Answers player actions with 204, the new status comes from /api/status or the events
+func (s *server) action(run func(accessToken string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := s.run(run)
+		if err != nil {
+			writeRequestError(w, r, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *server) toggle(accessToken string) error {
+	state, err := s.controller.PlaybackState(accessToken)
+	if err != nil {
+		return fmt.Errorf("toggle: %w", err)
+	}
+	if state.IsPlaying {
+		return s.controller.PausePlayback(accessToken)
+	}
+	return s.controller.StartResumePlayback(accessToken)
+}
+
+// Nothing playing is a status too (stopped)
+func (s *server) status() (spotify.Status, error) {
+	var status spotify.Status
+	err := s.run(func(accessToken string) error {
+		state, err := s.controller.PlaybackState(accessToken)
+		if errors.Is(err, spotify.ErrNoPlayback) {
+			status = spotify.StoppedStatus()
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("status: %w", err)
+		}
+		status = spotify.NewStatus(state)
+		return nil
+	})
+	return status, err
+}
+
+func (s *server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.status()
+	if err != nil {
+		writeRequestError(w, r, err)
+		return
+	}
+	writeJson(w, http.StatusOK, status)
+}
+
+// {"volume": 40} sets the volume, {"change": -5} changes it. It's clamped to 0-100
+func (s *server) handleVolume(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Change int  `json:"change"`
+		Volume *int `json:"volume"`
+	}
+	if !readJson(w, r, &body) {
+		return
+	}
+	if body.Volume == nil && body.Change == 0 {
+		writeError(w, http.StatusBadRequest, "expected volume or change")
+		return
+	}
+	s.action(func(accessToken string) error {
+		volume := 0
+		if body.Volume != nil {
+			volume = *body.Volume
+		} else {
+			state, err := s.controller.PlaybackState(accessToken)
+			if err != nil {
+				return fmt.Errorf("handleVolume: %w", err)
+			}
+			volume = state.Volume
+		}
+		volume += body.Change
+		return s.controller.SetPlaybackVolume(accessToken, min(max(volume, 0), 100))
+	})(w, r)
+}
+
+// {"position_ms": 80000}
+func (s *server) handleSeek(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		PositionMs *int `json:"position_ms"`
+	}
+	if !readJson(w, r, &body) {
+		return
+	}
+	if body.PositionMs == nil || *body.PositionMs < 0 {
+		writeError(w, http.StatusBadRequest, "expected position_ms of 0 or more")
+		return
+	}
+	s.action(func(accessToken string) error {
+		return s.controller.SeekToPosition(accessToken, *body.PositionMs)
+	})(w, r)
+}
+
+// {"shuffle": true}
+func (s *server) handleShuffle(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Shuffle *bool `json:"shuffle"`
+	}
+	if !readJson(w, r, &body) {
+		return
+	}
+	if body.Shuffle == nil {
+		writeError(w, http.StatusBadRequest, "expected shuffle")
+		return
+	}
+	s.action(func(accessToken string) error {
+		return s.controller.ShuffleMode(accessToken, *body.Shuffle)
+	})(w, r)
+}
+
+// {"repeat": "off"}, off, context or track
+func (s *server) handleRepeat(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Repeat string `json:"repeat"`
+	}
+	if !readJson(w, r, &body) {
+		return
+	}
+	switch body.Repeat {
+	case "off", "context", "track":
+	default:
+		writeError(w, http.StatusBadRequest, "expected repeat of off, context or track")
+		return
+	}
+	s.action(func(accessToken string) error {
+		return s.controller.RepeatMode(accessToken, body.Repeat)
+	})(w, r)
+}
+
+// {"uri": "spotify:album:<id>"}
+func (s *server) handlePlayUri(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Uri string `json:"uri"`
+	}
+	if !readJson(w, r, &body) {
+		return
+	}
+	if !strings.HasPrefix(body.Uri, "spotify:") {
+		writeError(w, http.StatusBadRequest, "expected a spotify uri (spotify:track:<id>)")
+		return
+	}
+	s.action(func(accessToken string) error {
+		return s.controller.PlayUri(body.Uri, accessToken)
+	})(w, r)
+}
+
+// Bodies are small, anything bigger is a mistake
+func readJson(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(v)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad json: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJson(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, reason string) {
+	writeJson(w, code, map[string]string{"error": reason})
+}
+
+// No active device is 409, spotify failing is 502
+func writeRequestError(w http.ResponseWriter, r *http.Request, err error) {
+	slog.Error("http request failed", "path", r.URL.Path, "err", err)
+	code := http.StatusBadGateway
+	if spotify.IsNoDevice(err) {
+		code = http.StatusConflict
+	}
+	writeError(w, code, spotify.ErrorReason(err))
+}
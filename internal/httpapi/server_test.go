@@ -0,0 +1,216 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"neofy/internal/spotify"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Plays along with the requests the api makes, anything else panics
+type fakeController struct {
+	spotify.Controller
+	err    error // Returned by every request when it's set
+	mu     sync.Mutex
+	polls  int
+	state  spotify.SlimPlayerData
+	volume int // Last volume that was set, -1 until then
+}
+
+func newFakeController() *fakeController {
+	progress := 1000
+	return &fakeController{
+		state:  spotify.SlimPlayerData{Artist: "Artist", IsPlaying: true, SongName: "Song", SongProgress: &progress, Volume: 50},
+		volume: -1,
+	}
+}
+
+func (c *fakeController) PlaybackState(string) (*spotify.SlimPlayerData, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.polls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	state := c.state
+	return &state, nil
+}
+
+func (c *fakeController) SkipToNext(string) error {
+	return c.err
+}
+
+func (c *fakeController) SetPlaybackVolume(_ string, volume int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.volume = volume
+	return c.err
+}
+
+func (c *fakeController) setPlaying(playing bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.state.IsPlaying = playing
+}
+
+func (c *fakeController) pollCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.polls
+}
+
+const testToken = "secret"
+
+func newTestServer(c spotify.Controller) *server {
+	return &server{
+		controller: c,
+		events:     newBroadcaster(),
+		opts:       Options{Interval: 20 * time.Millisecond, Remote: true, Token: testToken},
+		tokens:     func() (string, error) { return "access", nil },
+	}
+}
+
+func TestCheckAddr(t *testing.T) {
+	tests := []struct {
+		addr    string
+		wantErr bool
+	}{
+		{"localhost:8091", false},
+		{"127.0.0.1:8091", false},
+		{"127.0.0.2:8091", false},
+		{"[::1]:8091", false},
+		{":8091", true},
+		{"0.0.0.0:8091", true},
+		{"[::]:8091", true},
+		{"192.168.1.2:8091", true},
+		{"example.com:8091", true},
+		{"localhost", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			err := CheckAddr(tt.addr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckAddr(%q) = %v, want an error %v", tt.addr, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		want   int
+	}{
+		{"no token", "/api/status", "", http.StatusUnauthorized},
+		{"bearer", "/api/status", "Bearer " + testToken, http.StatusOK},
+		{"wrong bearer", "/api/status", "Bearer nope", http.StatusUnauthorized},
+		{"not bearer", "/api/status", testToken, http.StatusUnauthorized},
+		{"query", "/api/status?token=" + testToken, "", http.StatusOK},
+		{"wrong query", "/api/status?token=nope", "", http.StatusUnauthorized},
+		{"bearer over query", "/api/status?token=" + testToken, "Bearer nope", http.StatusUnauthorized},
+		{"empty query", "/api/status?token=", "", http.StatusUnauthorized},
+		// The page asks for the token itself
+		{"remote page", "/", "", http.StatusOK},
+	}
+	handler := newTestServer(newFakeController()).routes()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("GET %s = %d, want %d", tt.path, rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func post(handler http.Handler, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestVolume(t *testing.T) {
+	tests := []struct {
+		body   string
+		code   int
+		volume int // -1 when it shouldn't be set
+	}{
+		{`{"volume": 40}`, http.StatusNoContent, 40},
+		{`{"volume": 0}`, http.StatusNoContent, 0},
+		{`{"volume": 140}`, http.StatusNoContent, 100},
+		{`{"volume": -5}`, http.StatusNoContent, 0},
+		{`{"change": -5}`, http.StatusNoContent, 45},
+		{`{"change": 80}`, http.StatusNoContent, 100},
+		{`{"change": -80}`, http.StatusNoContent, 0},
+		{`{"volume": 40, "change": 5}`, http.StatusNoContent, 45},
+		{`{}`, http.StatusBadRequest, -1},
+		{`{"change": 0}`, http.StatusBadRequest, -1},
+		{`{"volume": "loud"}`, http.StatusBadRequest, -1},
+		{`not json`, http.StatusBadRequest, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			c := newFakeController()
+			rec := post(newTestServer(c).routes(), "/api/volume", tt.body)
+			if rec.Code != tt.code {
+				t.Errorf("POST /api/volume %s = %d, want %d", tt.body, rec.Code, tt.code)
+			}
+			if c.volume != tt.volume {
+				t.Errorf("POST /api/volume %s set %d, want %d", tt.body, c.volume, tt.volume)
+			}
+		})
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		path string
+		want int
+	}{
+		{"ok", nil, "/api/next", http.StatusNoContent},
+		{"no device", fmt.Errorf("SkipToNext: %w", &spotify.APIError{Reason: "NO_ACTIVE_DEVICE", Status: 404}), "/api/next", http.StatusConflict},
+		{"not found", &spotify.APIError{Status: 404}, "/api/next", http.StatusConflict},
+		{"nothing playing", fmt.Errorf("toggle: %w", spotify.ErrNoPlayback), "/api/volume", http.StatusConflict},
+		{"spotify failed", &spotify.APIError{Status: 500, Message: "Server error"}, "/api/next", http.StatusBadGateway},
+		{"network", errors.New("SkipToNext: client: connection refused"), "/api/next", http.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newFakeController()
+			c.err = tt.err
+			rec := post(newTestServer(c).routes(), tt.path, `{"change": 5}`)
+			if rec.Code != tt.want {
+				t.Errorf("POST %s with %v = %d, want %d", tt.path, tt.err, rec.Code, tt.want)
+			}
+			if tt.err != nil && !strings.Contains(rec.Body.String(), `"error"`) {
+				t.Errorf("POST %s with %v = %s, want the reason", tt.path, tt.err, rec.Body.String())
+			}
+		})
+	}
+	// Nothing playing is a status, not an error
+	c := newFakeController()
+	c.err = spotify.ErrNoPlayback
+	req := httptest.NewRequest("GET", "/api/status", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec := httptest.NewRecorder()
+	newTestServer(c).routes().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"state":"stopped"`) {
+		t.Errorf("GET /api/status with nothing playing = %d %s, want the stopped status", rec.Code, rec.Body.String())
+	}
+}
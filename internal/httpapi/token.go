@@ -0,0 +1,47 @@
+package httpapi
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"neofy/internal/logging"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Bearer token clients send, it's kept so remotes keep working after a restart.
+// Renew makes a new one, the old one stops working
+func Token(renew bool) (string, error) {
+	dir, err := logging.StateDir()
+	if err != nil {
+		return "", fmt.Errorf("Token: %w", err)
+	}
+	path := filepath.Join(dir, "http-token")
+	if !renew {
+		b, err := os.ReadFile(path)
+		if err == nil && strings.TrimSpace(string(b)) != "" {
+			return strings.TrimSpace(string(b)), nil
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("Token: %w", err)
+		}
+	}
+	b := make([]byte, 32)
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", fmt.Errorf("Token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+	err = os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return "", fmt.Errorf("Token: %w", err)
+	}
+	// NOTE: Only the user can read the token
+	err = os.WriteFile(path, []byte(token+"\n"), 0o600)
+	if err != nil {
+		return "", fmt.Errorf("Token: %w", err)
+	}
+	return token, nil
+}
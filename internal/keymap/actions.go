@@ -0,0 +1,84 @@
+package keymap
+
+// Named thing a key does, <mode>.<what>
+type Action string
+
+// NOTE: Remove a binding by binding it's keys to None
+const None Action = "none"
+
+const (
+	AppCommand Action = "app.command"
+	AppHelp    Action = "app.help"
+	AppPanic   Action = "app.panic"
+	AppQuit    Action = "app.quit"
+
+	ModeHistory   Action = "mode.history"
+	ModeMessages  Action = "mode.messages"
+	ModePlayer    Action = "mode.player"
+	ModePlaylists Action = "mode.playlists"
+	ModeTracks    Action = "mode.tracks"
+
+	PlayerNext       Action = "player.next"
+	PlayerPause      Action = "player.pause"
+	PlayerPlay       Action = "player.play"
+	PlayerPrevious   Action = "player.previous"
+	PlayerRefresh    Action = "player.refresh"
+	PlayerRepeat     Action = "player.repeat"
+	PlayerShuffle    Action = "player.shuffle"
+	PlayerVolumeDown Action = "player.volume_down"
+	PlayerVolumeUp   Action = "player.volume_up"
+
+	ListBack         Action = "list.back" // Removes the filter, otherwise leaves the mode
+	ListBottom       Action = "list.bottom"
+	ListCenter       Action = "list.center"
+	ListDown         Action = "list.down"
+	ListFilter       Action = "list.filter"
+	ListHalfPageDown Action = "list.half_page_down"
+	ListHalfPageUp   Action = "list.half_page_up"
+	ListNextMatch    Action = "list.next_match"
+	ListPageBottom   Action = "list.page_bottom"
+	ListPageDown     Action = "list.page_down"
+	ListPageMiddle   Action = "list.page_middle"
+	ListPageTop      Action = "list.page_top"
+	ListPageUp       Action = "list.page_up"
+	ListPrevMatch    Action = "list.prev_match"
+	ListTop          Action = "list.top"
+	ListUp           Action = "list.up"
+
+	PlaylistCreate              Action = "playlist.create"
+	PlaylistDelete              Action = "playlist.delete"
+	PlaylistDescribe            Action = "playlist.describe"
+	PlaylistRename              Action = "playlist.rename"
+	PlaylistSelect              Action = "playlist.select"
+	PlaylistToggleCollaborative Action = "playlist.toggle_collaborative"
+	PlaylistTogglePublic        Action = "playlist.toggle_public"
+
+	TrackAddToPlaylist Action = "track.add_to_playlist"
+	TrackMoveDown      Action = "track.move_down"
+	TrackMoveUp        Action = "track.move_up"
+	TrackPlay          Action = "track.play"
+	TrackRemove        Action = "track.remove"
+
+	PickerAdd    Action = "picker.add"
+	PickerCancel Action = "picker.cancel"
+
+	HistoryFetch        Action = "history.fetch"
+	HistoryOpenPlaylist Action = "history.open_playlist"
+	HistoryPlay         Action = "history.play"
+
+	MessagesClear Action = "messages.clear"
+)
+
+// Every action that can be bound
+var Actions = []Action{
+	AppCommand, AppHelp, AppPanic, AppQuit,
+	ModeHistory, ModeMessages, ModePlayer, ModePlaylists, ModeTracks,
+	PlayerNext, PlayerPause, PlayerPlay, PlayerPrevious, PlayerRefresh, PlayerRepeat, PlayerShuffle, PlayerVolumeDown, PlayerVolumeUp,
+	ListBack, ListBottom, ListCenter, ListDown, ListFilter, ListHalfPageDown, ListHalfPageUp, ListNextMatch,
+	ListPageBottom, ListPageDown, ListPageMiddle, ListPageTop, ListPageUp, ListPrevMatch, ListTop, ListUp,
+	PlaylistCreate, PlaylistDelete, PlaylistDescribe, PlaylistRename, PlaylistSelect, PlaylistToggleCollaborative, PlaylistTogglePublic,
+	TrackAddToPlaylist, TrackMoveDown, TrackMoveUp, TrackPlay, TrackRemove,
+	PickerAdd, PickerCancel,
+	HistoryFetch, HistoryOpenPlaylist, HistoryPlay,
+	MessagesClear,
+}
@@ -0,0 +1,142 @@
+package keymap
+
+// Bindings used when there is no keymap file, the README lists them
+var defaultBindings = []struct {
+	mode   string
+	keys   string
+	action Action
+}{
+	{Player, "<C-c>", AppQuit},
+	{Player, ":", AppCommand},
+	{Player, "?", AppHelp},
+	{Player, "u", ModePlaylists},
+	{Player, "U", ModePlaylists},
+	{Player, "t", ModeTracks},
+	{Player, "T", ModeTracks},
+	{Player, "h", ModeHistory},
+	{Player, "H", ModeHistory},
+	{Player, "m", ModeMessages},
+	{Player, "s", PlayerShuffle},
+	{Player, "S", PlayerShuffle},
+	{Player, "b", PlayerPrevious},
+	{Player, "B", PlayerPrevious},
+	{Player, "p", PlayerPlay},
+	{Player, "P", PlayerPlay},
+	{Player, "x", PlayerPause},
+	{Player, "X", PlayerPause},
+	{Player, "n", PlayerNext},
+	{Player, "N", PlayerNext},
+	{Player, "r", PlayerRepeat},
+	{Player, "R", PlayerRepeat},
+	{Player, "-", PlayerVolumeDown},
+	{Player, "+", PlayerVolumeUp},
+	{Player, "=", PlayerVolumeUp},
+	{Player, "f", PlayerRefresh},
+	{Player, "F", PlayerRefresh},
+	{Player, "w", AppPanic},
+	{Player, "W", AppPanic},
+
+	{Playlist, "<C-c>", ModePlayer},
+	{Playlist, "<Esc>", ListBack},
+	{Playlist, ":", AppCommand},
+	{Playlist, "?", AppHelp},
+	{Playlist, "t", ModeTracks},
+	{Playlist, "T", ModeTracks},
+	{Playlist, "h", ModeHistory},
+	{Playlist, "m", ModeMessages},
+	{Playlist, "/", ListFilter},
+	{Playlist, "s", PlaylistSelect},
+	{Playlist, "S", PlaylistSelect},
+	{Playlist, "c", PlaylistCreate},
+	{Playlist, "r", PlaylistRename},
+	{Playlist, "e", PlaylistDescribe},
+	{Playlist, "v", PlaylistTogglePublic},
+	{Playlist, "C", PlaylistToggleCollaborative},
+	{Playlist, "dd", PlaylistDelete},
+
+	{Track, "<C-c>", ModePlayer},
+	{Track, "<Esc>", ListBack},
+	{Track, ":", AppCommand},
+	{Track, "?", AppHelp},
+	{Track, "u", ModePlaylists},
+	{Track, "U", ModePlaylists},
+	{Track, "h", ModeHistory},
+	{Track, "m", ModeMessages},
+	{Track, "/", ListFilter},
+	{Track, "s", TrackPlay},
+	{Track, "S", TrackPlay},
+	{Track, "J", TrackMoveDown},
+	{Track, "K", TrackMoveUp},
+	{Track, "dd", TrackRemove},
+	{Track, "a", TrackAddToPlaylist},
+	{Track, "A", TrackAddToPlaylist},
+
+	{Picker, "<C-c>", PickerCancel},
+	{Picker, "<Esc>", PickerCancel},
+	{Picker, "s", PickerAdd},
+	{Picker, "S", PickerAdd},
+	{Picker, "<Enter>", PickerAdd},
+	{Picker, "?", AppHelp},
+
+	{History, "<C-c>", ModePlayer},
+	{History, "<Esc>", ListBack},
+	{History, ":", AppCommand},
+	{History, "?", AppHelp},
+	{History, "u", ModePlaylists},
+	{History, "U", ModePlaylists},
+	{History, "t", ModeTracks},
+	{History, "T", ModeTracks},
+	{History, "m", ModeMessages},
+	{History, "s", HistoryPlay},
+	{History, "S", HistoryPlay},
+	{History, "o", HistoryOpenPlaylist},
+	{History, "O", HistoryOpenPlaylist},
+	{History, "f", HistoryFetch},
+	{History, "F", HistoryFetch},
+
+	{Messages, "<C-c>", ModePlayer},
+	{Messages, "<Esc>", ListBack},
+	{Messages, ":", AppCommand},
+	{Messages, "?", AppHelp},
+	{Messages, "u", ModePlaylists},
+	{Messages, "U", ModePlaylists},
+	{Messages, "t", ModeTracks},
+	{Messages, "T", ModeTracks},
+	{Messages, "h", ModeHistory},
+	{Messages, "/", ListFilter},
+	{Messages, "c", MessagesClear},
+
+	{List, "j", ListDown},
+	{List, "<Down>", ListDown},
+	{List, "k", ListUp},
+	{List, "<Up>", ListUp},
+	{List, "<C-d>", ListHalfPageDown},
+	{List, "<C-u>", ListHalfPageUp},
+	{List, "<PageDown>", ListPageDown},
+	{List, "<PageUp>", ListPageUp},
+	{List, "gg", ListTop},
+	{List, "<Home>", ListTop},
+	{List, "G", ListBottom},
+	{List, "<End>", ListBottom},
+	{List, "H", ListPageTop},
+	{List, "M", ListPageMiddle},
+	{List, "L", ListPageBottom},
+	{List, "zz", ListCenter},
+	{List, "n", ListNextMatch},
+	{List, "N", ListPrevMatch},
+}
+
+func Default() *Keymap {
+	k := New()
+	for _, b := range defaultBindings {
+		keys, err := ParseKeys(b.keys)
+		if err != nil {
+			panic(err)
+		}
+		err = k.Bind(b.mode, keys, b.action)
+		if err != nil {
+			panic(err)
+		}
+	}
+	return k
+}
@@ -0,0 +1,281 @@
+package keymap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"neofy/internal/consts"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Modes that have their own bindings
+const (
+	History  = "history"
+	List     = "list" // Motions shared by every list mode
+	Messages = "messages"
+	Picker   = "picker"
+	Player   = "player"
+	Playlist = "playlist"
+	Track    = "track"
+)
+
+var Modes = []string{Player, Playlist, Track, History, Picker, Messages, List}
+
+// Modes that also use the list bindings, a count can be typed before a binding (5j)
+var listModes = []string{Playlist, Track, History, Picker, Messages}
+
+// Key sequences to actions for every mode, keys are stored as a string of runes
+type Keymap struct {
+	bindings map[string]map[string]Action
+}
+
+func New() *Keymap {
+	k := Keymap{bindings: map[string]map[string]Action{}}
+	for _, mode := range Modes {
+		k.bindings[mode] = map[string]Action{}
+	}
+	return &k
+}
+
+// Binds keys to action in mode, binding None removes the keys
+func (k *Keymap) Bind(mode string, keys []rune, action Action) error {
+	if _, ok := k.bindings[mode]; !ok {
+		return errors.New("Bind: unknown mode: " + mode)
+	}
+	if len(keys) == 0 {
+		return errors.New("Bind: no keys")
+	}
+	if action == None {
+		delete(k.bindings[mode], string(keys))
+		return nil
+	}
+	if !slices.Contains(Actions, action) {
+		return errors.New("Bind: unknown action: " + string(action))
+	}
+	if !allowed(mode, action) {
+		return fmt.Errorf("Bind: %s can't be used in %s mode", action, mode)
+	}
+	k.bindings[mode][string(keys)] = action
+	return nil
+}
+
+// App, mode & player actions work in every mode, the rest only in their own
+func allowed(mode string, action Action) bool {
+	group, _, _ := strings.Cut(string(action), ".")
+	switch group {
+	case "app", "mode", "player":
+		return true
+	case List:
+		return mode == List || slices.Contains(listModes, mode)
+	}
+	return group == mode
+}
+
+// Bindings of mode including the list bindings it uses
+func (k *Keymap) modeBindings(mode string) map[string]Action {
+	if !slices.Contains(listModes, mode) {
+		return k.bindings[mode]
+	}
+	bindings := map[string]Action{}
+	for keys, action := range k.bindings[List] {
+		bindings[keys] = action
+	}
+	for keys, action := range k.bindings[mode] {
+		bindings[keys] = action
+	}
+	return bindings
+}
+
+// Keys typed in a mode that don't make up a binding yet, the app reads one key at a time
+// so signals, resizes & polls are still handled while the rest of a binding (gg, 5j) is typed
+type Pending struct {
+	At    time.Time // When the last key was typed
+	Count int       // Typed before the keys in a list mode, 0 when there is none
+	Keys  string
+	Mode  string
+}
+
+// How long typed keys wait for the rest of a binding, like vim's timeoutlen
+const PendingTimeout = time.Second
+
+// Returns true while keys or a count wait for the rest of a binding
+func (p *Pending) Active() bool {
+	return p.Count > 0 || p.Keys != ""
+}
+
+func (p *Pending) Clear() {
+	*p = Pending{}
+}
+
+// Fires once the typed keys waited too long for the rest of a binding, nil when nothing is typed
+func (p *Pending) Timeout() <-chan time.Time {
+	if !p.Active() {
+		return nil
+	}
+	return time.After(time.Until(p.At.Add(PendingTimeout)))
+}
+
+// Adds key to the keys typed in mode. Action is "" while the keys can still become a binding
+// & once they can't, the typed keys are cleared when there is an action or there can't be one.
+// A count (5j, 20G) can be typed before the keys in a list mode, count is 0 when there is none.
+// Esc drops the keys that were typed
+func (k *Keymap) Feed(p *Pending, mode string, key rune) (Action, int) {
+	if p.Mode != mode {
+		p.Clear()
+	}
+	p.Mode = mode
+	p.At = time.Now()
+	if key == consts.ESC && p.Active() {
+		p.Clear()
+		return "", 0
+	}
+	isDigit := (key >= '1' && key <= '9') || (p.Count > 0 && key == '0')
+	if p.Keys == "" && isDigit && slices.Contains(listModes, mode) {
+		p.Count = p.Count*10 + int(key-'0')
+		return "", 0
+	}
+	p.Keys += string(key)
+	bindings := k.modeBindings(mode)
+	action, ok := bindings[p.Keys]
+	if !ok {
+		for b := range bindings {
+			if strings.HasPrefix(b, p.Keys) {
+				return "", 0
+			}
+		}
+	}
+	count := p.Count
+	p.Clear()
+	return action, count
+}
+
+// Returns the keys bound to action in mode, written in keymap notation
+func (k *Keymap) Keys(mode string, action Action) []string {
+	keys := []string{}
+	for b, a := range k.modeBindings(mode) {
+		if a == action {
+			keys = append(keys, FormatKeys([]rune(b)))
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Keys bound to an action, written in keymap notation
+type Binding struct {
+	Action Action
+	Keys   []string
+}
+
+// Bindings of mode without the list bindings it uses, in the order of Actions
+func (k *Keymap) Bindings(mode string) []Binding {
+	keys := map[Action][]string{}
+	for b, a := range k.bindings[mode] {
+		keys[a] = append(keys[a], FormatKeys([]rune(b)))
+	}
+	bindings := []Binding{}
+	for _, a := range Actions {
+		if len(keys[a]) == 0 {
+			continue
+		}
+		slices.Sort(keys[a])
+		bindings = append(bindings, Binding{Action: a, Keys: keys[a]})
+	}
+	return bindings
+}
+
+// Returns true when mode also uses the list bindings
+func UsesList(mode string) bool {
+	return slices.Contains(listModes, mode)
+}
+
+// Reports bindings that can never be reached: keys bound twice, keys that start
+// another binding (g & gg) & keys that start with a count in a list mode
+func (k *Keymap) Conflicts() error {
+	errs := []error{}
+	for _, mode := range Modes {
+		if slices.Contains(listModes, mode) {
+			for keys, action := range k.bindings[mode] {
+				if listAction, ok := k.bindings[List][keys]; ok {
+					errs = append(errs, fmt.Errorf("%s: %s is bound to %s & %s", mode, FormatKeys([]rune(keys)), action, listAction))
+				}
+			}
+		}
+		own := k.bindings[mode]
+		if mode == List || slices.Contains(listModes, mode) {
+			for keys := range own {
+				if keys[0] >= '1' && keys[0] <= '9' {
+					errs = append(errs, fmt.Errorf("%s: %s starts with a count", mode, FormatKeys([]rune(keys))))
+				}
+			}
+		}
+		bindings := k.modeBindings(mode)
+		sorted := []string{}
+		for keys := range bindings {
+			sorted = append(sorted, keys)
+		}
+		slices.Sort(sorted)
+		for i, keys := range sorted {
+			// NOTE: Sorted keys that start with keys come right after it
+			for _, other := range sorted[i+1:] {
+				if !strings.HasPrefix(other, keys) {
+					break
+				}
+				// List conflicts are reported once for the list mode
+				_, ownKeys := own[keys]
+				_, ownOther := own[other]
+				if !ownKeys && !ownOther {
+					continue
+				}
+				errs = append(errs, fmt.Errorf("%s: %s (%s) hides %s (%s)", mode, FormatKeys([]rune(keys)), bindings[keys], FormatKeys([]rune(other)), bindings[other]))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Applies the bindings in the file at path on top of k. Every line is <mode> <keys> <action>,
+// lines starting with # are ignored
+func (k *Keymap) Load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Load: %w", err)
+	}
+	defer file.Close()
+
+	seen := map[string]int{}
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return fmt.Errorf("Load: %s:%d: expected <mode> <keys> <action>", path, lineNum)
+		}
+		keys, err := ParseKeys(fields[1])
+		if err != nil {
+			return fmt.Errorf("Load: %s:%d: %w", path, lineNum, err)
+		}
+		id := fields[0] + " " + string(keys)
+		if prevLine, ok := seen[id]; ok {
+			return fmt.Errorf("Load: %s:%d: %s is already bound on line %d", path, lineNum, fields[1], prevLine)
+		}
+		seen[id] = lineNum
+		err = k.Bind(fields[0], keys, Action(fields[2]))
+		if err != nil {
+			return fmt.Errorf("Load: %s:%d: %w", path, lineNum, err)
+		}
+	}
+	err = scanner.Err()
+	if err != nil {
+		return fmt.Errorf("Load: %w", err)
+	}
+	return nil
+}
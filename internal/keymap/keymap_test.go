@@ -0,0 +1,247 @@
+package keymap
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type bind struct {
+	mode   string
+	keys   string
+	action Action
+}
+
+func newKeymap(t *testing.T, binds []bind) *Keymap {
+	t.Helper()
+	k := New()
+	for _, b := range binds {
+		keys, err := ParseKeys(b.keys)
+		if err != nil {
+			t.Fatalf("ParseKeys(%q) = %v", b.keys, err)
+		}
+		err = k.Bind(b.mode, keys, b.action)
+		if err != nil {
+			t.Fatalf("Bind(%s, %s, %s) = %v", b.mode, b.keys, b.action, err)
+		}
+	}
+	return k
+}
+
+func TestBind(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		keys    string
+		action  Action
+		wantErr bool
+	}{
+		{"app action", Player, "x", AppQuit, false},
+		{"player action in a list mode", Track, "x", PlayerNext, false},
+		{"list action in a list mode", Track, "x", ListDown, false},
+		{"own action", Messages, "x", MessagesClear, false},
+		{"unknown mode", "nope", "x", AppQuit, true},
+		{"no keys", Player, "", AppQuit, true},
+		{"unknown action", Player, "x", "app.nope", true},
+		{"action of another mode", Player, "x", TrackPlay, true},
+		{"list action outside a list", Player, "j", ListDown, true},
+		{"mode action in list", List, "x", TrackPlay, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New().Bind(tt.mode, []rune(tt.keys), tt.action)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Bind(%s, %q, %s) = %v, want an error %v", tt.mode, tt.keys, tt.action, err, tt.wantErr)
+			}
+		})
+	}
+
+	k := newKeymap(t, []bind{{Player, "x", AppQuit}, {Player, "x", None}})
+	if keys := k.Keys(Player, AppQuit); len(keys) != 0 {
+		t.Errorf("Keys(player, app.quit) after binding x to none = %v, want none", keys)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	tests := []struct {
+		name  string
+		binds []bind
+		want  []string
+	}{
+		{"none", []bind{{List, "j", ListDown}, {List, "gg", ListTop}, {Track, "d", TrackRemove}, {Player, "g", PlayerNext}}, nil},
+		{"bound in list & mode", []bind{{List, "j", ListDown}, {Track, "j", TrackRemove}}, []string{
+			"track: j is bound to track.remove & list.down",
+		}},
+		{"count in list", []bind{{List, "5x", ListDown}}, []string{"list: 5x starts with a count"}},
+		{"count in list mode", []bind{{Track, "1", TrackPlay}}, []string{"track: 1 starts with a count"}},
+		{"count outside a list", []bind{{Player, "5", PlayerNext}}, nil},
+		{"0 isn't a count", []bind{{List, "0", ListTop}}, nil},
+		{"prefix in list", []bind{{List, "g", ListTop}, {List, "gg", ListBottom}}, []string{
+			"list: g (list.top) hides gg (list.bottom)",
+		}},
+		{"prefix in mode", []bind{{Player, "<Space>", PlayerPause}, {Player, "<Space><Space>", PlayerPlay}}, []string{
+			"player: <Space> (player.pause) hides <Space><Space> (player.play)",
+		}},
+		{"mode key hides list keys", []bind{{List, "gg", ListTop}, {Track, "g", TrackRemove}}, []string{
+			"track: g (track.remove) hides gg (list.top)",
+		}},
+		{"prefix of a longer prefix", []bind{{List, "g", ListTop}, {List, "gg", ListBottom}, {List, "ggg", ListUp}}, []string{
+			"list: g (list.top) hides gg (list.bottom)",
+			"list: g (list.top) hides ggg (list.up)",
+			"list: gg (list.bottom) hides ggg (list.up)",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newKeymap(t, tt.binds).Conflicts()
+			got := []string{}
+			if err != nil {
+				got = strings.Split(err.Error(), "\n")
+			}
+			slices.Sort(got)
+			want := slices.Clone(tt.want)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("Conflicts() = %q, want %q", got, want)
+			}
+		})
+	}
+
+	err := Default().Conflicts()
+	if err != nil {
+		t.Errorf("Default().Conflicts() = %v, want none", err)
+	}
+}
+
+func TestFeed(t *testing.T) {
+	k := newKeymap(t, []bind{
+		{List, "j", ListDown},
+		{List, "gg", ListTop},
+		{List, "G", ListBottom},
+		{Track, "gd", TrackRemove},
+		{Player, "5", PlayerNext},
+		{Player, "<Esc>", AppQuit},
+	})
+	tests := []struct {
+		name        string
+		mode        string
+		typed       string
+		want        Action
+		wantCount   int
+		wantPending bool
+	}{
+		{"key", Track, "j", ListDown, 0, false},
+		{"start of a sequence", Track, "g", "", 0, true},
+		{"sequence", Track, "gg", ListTop, 0, false},
+		{"mode sequence sharing a prefix", Track, "gd", TrackRemove, 0, false},
+		{"sequence that can't match", Track, "gx", "", 0, false},
+		{"unbound key", Track, "x", "", 0, false},
+		{"count", Track, "12j", ListDown, 12, false},
+		{"count with 0", Track, "10G", ListBottom, 10, false},
+		{"count before a sequence", Track, "3gg", ListTop, 3, false},
+		{"count waiting", Track, "12", "", 0, true},
+		{"0 isn't a count", Track, "0", "", 0, false},
+		{"count without a binding", Track, "5x", "", 5, false},
+		{"count in a sequence", Track, "g5", "", 0, false},
+		{"esc drops a count", Track, "5<Esc>j", ListDown, 0, false},
+		{"esc drops a sequence", Track, "g<Esc>j", ListDown, 0, false},
+		{"esc with nothing typed", Player, "<Esc>", AppQuit, 0, false},
+		{"no counts outside a list", Player, "5", PlayerNext, 0, false},
+		{"no list bindings outside a list", Player, "gg", "", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := ParseKeys(tt.typed)
+			if err != nil {
+				t.Fatalf("ParseKeys(%q) = %v", tt.typed, err)
+			}
+			var p Pending
+			var action Action
+			count := 0
+			for _, key := range keys {
+				action, count = k.Feed(&p, tt.mode, key)
+			}
+			if action != tt.want || count != tt.wantCount {
+				t.Errorf("Feed() after %s = %q %d, want %q %d", tt.typed, action, count, tt.want, tt.wantCount)
+			}
+			if p.Active() != tt.wantPending {
+				t.Errorf("Active() after %s = %v, want %v", tt.typed, p.Active(), tt.wantPending)
+			}
+			if (p.Timeout() != nil) != tt.wantPending {
+				t.Errorf("Timeout() after %s = %v, want a timeout %v", tt.typed, p.Timeout(), tt.wantPending)
+			}
+		})
+	}
+
+	// Keys typed in another mode are dropped
+	var p Pending
+	k.Feed(&p, Track, '5')
+	k.Feed(&p, Track, 'g')
+	if action, count := k.Feed(&p, Playlist, 'g'); action != "" || count != 0 {
+		t.Errorf("Feed() after 5g in track & g in playlist = %q %d, want nothing", action, count)
+	}
+	if action, count := k.Feed(&p, Playlist, 'g'); action != ListTop || count != 0 {
+		t.Errorf("Feed() after gg in playlist = %q %d, want %q 0", action, count, ListTop)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string // Part of the error, "" when the file loads
+	}{
+		{"bindings", "# Comment\n\nplayer x app.quit\n  track <C-x> track.remove  \nlist j none\n", ""},
+		{"missing action", "player x\n", "keymap:1: expected <mode> <keys> <action>"},
+		{"too many fields", "player x app.quit\nplayer y app.quit now\n", "keymap:2: expected <mode> <keys> <action>"},
+		{"bound twice", "player x app.quit\n\nplayer x app.help\n", "keymap:3: x is already bound on line 1"},
+		{"bound twice in another notation", "player <Space> app.quit\nplayer <space> app.help\n", "keymap:2: <space> is already bound on line 1"},
+		{"bad key", "player <Nope> app.quit\n", "keymap:1: ParseKeys: unknown key <Nope>"},
+		{"unknown mode", "nope x app.quit\n", "keymap:1: Bind: unknown mode: nope"},
+		{"unknown action", "player x app.nope\n", "keymap:1: Bind: unknown action: app.nope"},
+		{"action of another mode", "player x track.play\n", "keymap:1: Bind: track.play can't be used in player mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "keymap")
+			err := os.WriteFile(path, []byte(tt.file), 0o644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = Default().Load(path)
+			if tt.want == "" && err != nil {
+				t.Errorf("Load() = %v, want no error", err)
+			}
+			if tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("Load() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "keymap")
+	err := os.WriteFile(path, []byte("player x app.quit\nlist j none\ntrack <C-x> track.remove\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := Default()
+	err = k.Load(path)
+	if err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	if keys := k.Keys(Player, AppQuit); !slices.Contains(keys, "x") || !slices.Contains(keys, "<C-c>") {
+		t.Errorf("Keys(player, app.quit) = %v, want x on top of the default <C-c>", keys)
+	}
+	if keys := k.Keys(Track, ListDown); slices.Contains(keys, "j") {
+		t.Errorf("Keys(track, list.down) = %v, want j removed", keys)
+	}
+	if keys := k.Keys(Track, TrackRemove); !slices.Contains(keys, "<C-x>") {
+		t.Errorf("Keys(track, track.remove) = %v, want <C-x>", keys)
+	}
+
+	err = New().Load(filepath.Join(t.TempDir(), "missing"))
+	if err == nil {
+		t.Error("Load() of a missing file = nil, want an error")
+	}
+}
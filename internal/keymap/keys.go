@@ -0,0 +1,166 @@
+package keymap
+
+import (
+	"errors"
+	"neofy/internal/consts"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Names of keys that can't be typed as themselves, written as <Name> in a keymap
+var keyNames = map[string]rune{
+	"BS":       consts.BACKSPACE,
+	"C-c":      consts.CONTROLCASCII,
+	"C-d":      consts.CONTROL_D,
+	"C-f":      consts.CONTROL_F,
+	"C-s":      consts.CONTROL_S,
+	"C-u":      consts.CONTROL_U,
+	"Del":      consts.DEL_KEY,
+	"Down":     consts.DOWN_ARROW,
+	"End":      consts.END_KEY,
+	"Enter":    '\r',
+	"Esc":      consts.ESC,
+	"F1":       consts.F1,
+	"F2":       consts.F2,
+	"F3":       consts.F3,
+	"F4":       consts.F4,
+	"F5":       consts.F5,
+	"F6":       consts.F6,
+	"F7":       consts.F7,
+	"F8":       consts.F8,
+	"F9":       consts.F9,
+	"F10":      consts.F10,
+	"F11":      consts.F11,
+	"F12":      consts.F12,
+	"Home":     consts.HOME_KEY,
+	"Insert":   consts.INSERT_KEY,
+	"Left":     consts.LEFT_ARROW,
+	"PageDown": consts.PAGE_DOWN,
+	"PageUp":   consts.PAGE_UP,
+	"Right":    consts.RIGHT_ARROW,
+	"Space":    ' ',
+	"Tab":      consts.TAB,
+	"Up":       consts.UP_ARROW,
+	"lt":       '<',
+}
+
+// Control letters the terminal sends as the same byte as another key, <C-h> can only arrive as <BS>
+var controlAliases = map[string]rune{
+	"C-h": consts.BACKSPACE,
+	"C-i": consts.TAB,
+	"C-j": '\r',
+	"C-m": '\r',
+}
+
+// Parses vim style notation (gg, <C-d>, d<Del>) into the keys terminal.ReadInputKey returns
+func ParseKeys(str string) ([]rune, error) {
+	if str == "" {
+		return nil, errors.New("ParseKeys: no keys")
+	}
+	keys := []rune{}
+	runes := []rune(str)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '<' {
+			keys = append(keys, runes[i])
+			continue
+		}
+		end := slices.Index(runes[i:], '>')
+		if end < 0 {
+			return nil, errors.New("ParseKeys: missing > in " + str)
+		}
+		name := string(runes[i+1 : i+end])
+		key, ok := lookupKeyName(name)
+		if !ok {
+			return nil, errors.New("ParseKeys: unknown key <" + name + ">")
+		}
+		keys = append(keys, key)
+		i += end
+	}
+	return keys, nil
+}
+
+// Key names ignore case, except for the letter of a control key.
+// Modifiers go before the key: <C-a>, <A-x> or <M-x> for Alt, <S-Up>, <C-S-Left>
+func lookupKeyName(name string) (rune, bool) {
+	if key, ok := keyNames[name]; ok {
+		return key, true
+	}
+	for n, key := range keyNames {
+		if !strings.HasPrefix(n, "C-") && strings.EqualFold(n, name) {
+			return key, true
+		}
+	}
+	mods := consts.Modifier(0)
+	for len(name) > 2 && name[1] == '-' {
+		switch name[0] {
+		case 'S', 's':
+			mods |= consts.ModShift
+		case 'A', 'a', 'M', 'm':
+			mods |= consts.ModAlt
+		case 'C':
+			mods |= consts.ModCtrl
+		default:
+			return 0, false
+		}
+		name = name[2:]
+	}
+	if mods == 0 {
+		return 0, false
+	}
+	base, ok := lookupKeyName(name)
+	if !ok {
+		runes := []rune(name)
+		if len(runes) != 1 {
+			return 0, false
+		}
+		base = runes[0]
+	}
+	// Control letters with their own key code (<C-c>) are what the terminal sends
+	if mods&consts.ModCtrl != 0 && len(name) == 1 {
+		if key, ok := keyNames["C-"+strings.ToLower(name)]; ok {
+			return consts.WithMods(key, mods&^consts.ModCtrl), true
+		}
+		if key, ok := controlAliases["C-"+strings.ToLower(name)]; ok {
+			return consts.WithMods(key, mods&^consts.ModCtrl), true
+		}
+		base = unicode.ToLower(base)
+	}
+	return consts.WithMods(base, mods), true
+}
+
+// Writes keys back in the notation ParseKeys reads
+func FormatKeys(keys []rune) string {
+	var b strings.Builder
+	for _, key := range keys {
+		base, mods := consts.SplitMods(key)
+		name := ""
+		if base == ' ' || base == '<' || base == '\r' || consts.IsKeyCode(base) {
+			for n, r := range keyNames {
+				if r == base {
+					name = n
+					break
+				}
+			}
+		}
+		if mods == 0 && name == "" {
+			b.WriteRune(key)
+			continue
+		}
+		if name == "" {
+			name = string(base)
+		}
+		prefix := ""
+		if mods&consts.ModCtrl != 0 {
+			prefix += "C-"
+		}
+		if mods&consts.ModAlt != 0 {
+			prefix += "A-"
+		}
+		if mods&consts.ModShift != 0 {
+			prefix += "S-"
+		}
+		b.WriteString("<" + prefix + name + ">")
+	}
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package keymap
+
+import (
+	"neofy/internal/consts"
+	"neofy/internal/terminal"
+	"slices"
+	"testing"
+)
+
+// A bind only works when ParseKeys gives the key the decoder makes of what the terminal sends
+func TestParseKeysRoundTrip(t *testing.T) {
+	tests := []struct {
+		keys string
+		sent string
+	}{
+		{"a", "a"},
+		{"<Space>", " "},
+		{"<lt>", "<"},
+		{"<C-a>", "\x01"},
+		{"<C-c>", "\x03"},
+		{"<C-d>", "\x04"},
+		{"<C-z>", "\x1a"},
+		{"<C-h>", "\x08"},
+		{"<BS>", "\x7f"},
+		{"<BS>", "\x08"},
+		{"<C-i>", "\t"},
+		{"<Tab>", "\t"},
+		{"<C-j>", "\n"},
+		{"<C-m>", "\r"},
+		{"<Enter>", "\r"},
+		{"<Enter>", "\n"},
+		{"<Esc>", "\x1b"},
+		{"<A-x>", "\x1bx"},
+		{"<M-x>", "\x1bx"},
+		{"<C-A-a>", "\x1b\x01"},
+		{"<Up>", "\x1b[A"},
+		{"<Up>", "\x1bOA"},
+		{"<C-Up>", "\x1b[1;5A"},
+		{"<C-S-Left>", "\x1b[1;6D"},
+		{"<S-Tab>", "\x1b[Z"},
+		{"<Del>", "\x1b[3~"},
+		{"<PageDown>", "\x1b[6~"},
+		{"<F1>", "\x1bOP"},
+		{"<F5>", "\x1b[15~"},
+		{"<F12>", "\x1b[24~"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.keys, func(t *testing.T) {
+			keys, err := ParseKeys(tt.keys)
+			if err != nil {
+				t.Fatalf("ParseKeys(%q) = %v", tt.keys, err)
+			}
+			if len(keys) != 1 {
+				t.Fatalf("ParseKeys(%q) = %d keys, want 1", tt.keys, len(keys))
+			}
+			key, n := terminal.DecodeKey([]byte(tt.sent))
+			if n != len(tt.sent) {
+				t.Fatalf("DecodeKey(%q) used %d bytes, want %d", tt.sent, n, len(tt.sent))
+			}
+			if key.Rune() != keys[0] {
+				t.Errorf("DecodeKey(%q) = %s, ParseKeys(%q) = %s", tt.sent, FormatKeys([]rune{key.Rune()}), tt.keys, FormatKeys(keys))
+			}
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		keys    string
+		want    []rune
+		wantErr bool
+	}{
+		{"gg", []rune("gg"), false},
+		{"d<Del>", []rune{'d', consts.DEL_KEY}, false},
+		{"<space>", []rune{' '}, false},
+		{"<lt>x", []rune("<x"), false},
+		{"", nil, true},
+		{"<Up", nil, true},
+		{"<Nope>", nil, true},
+		{"<X-a>", nil, true},
+		{"<C-ab>", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.keys, func(t *testing.T) {
+			keys, err := ParseKeys(tt.keys)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKeys(%q) = %v, want an error %v", tt.keys, err, tt.wantErr)
+			}
+			if !slices.Equal(keys, tt.want) {
+				t.Errorf("ParseKeys(%q) = %q, want %q", tt.keys, keys, tt.want)
+			}
+		})
+	}
+}
+
+// FormatKeys writes what ParseKeys reads back as the same keys
+func TestFormatKeysRoundTrip(t *testing.T) {
+	tests := []string{"gg", "<Space>", "<lt>", "<C-d>", "<Del>", "<A-x>", "<C-S-Left>", "<S-Tab>", "<F12>", "d<Del>", "<C-A-a>"}
+	for _, keys := range tests {
+		t.Run(keys, func(t *testing.T) {
+			parsed, err := ParseKeys(keys)
+			if err != nil {
+				t.Fatalf("ParseKeys(%q) = %v", keys, err)
+			}
+			formatted := FormatKeys(parsed)
+			again, err := ParseKeys(formatted)
+			if err != nil {
+				t.Fatalf("ParseKeys(FormatKeys(%q)) = %v, FormatKeys wrote %q", keys, err, formatted)
+			}
+			if !slices.Equal(again, parsed) {
+				t.Errorf("ParseKeys(%q) = %q, want %q", formatted, again, parsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package layout
+
+// Area of the screen a pane is drawn in, X & Y start at 0
+type Rect struct {
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// Direction the children of a pane are placed in
+type Split int
+
+const (
+	Rows    Split = iota // Stacked top to bottom
+	Columns              // Side by side left to right
+)
+
+// Node of a layout tree, panes without children are drawn & looked up by Name
+type Pane struct {
+	Children []*Pane
+	Hidden   bool
+	Max      int // 0 when there is no max
+	Min      int
+	Name     string
+	Size     float64 // Share of the parent, 0 splits what's left with the other 0 panes
+	Split    Split
+}
+
+// Splits r between the panes of the tree, hidden panes & their children get no Rect
+func (p *Pane) Compute(r Rect) map[string]Rect {
+	rects := map[string]Rect{}
+	p.compute(r, rects)
+	return rects
+}
+
+func (p *Pane) compute(r Rect, rects map[string]Rect) {
+	if len(p.Children) == 0 {
+		rects[p.Name] = r
+		return
+	}
+	visible := []*Pane{}
+	for _, c := range p.Children {
+		if !c.Hidden {
+			visible = append(visible, c)
+		}
+	}
+	length := r.Height
+	if p.Split == Columns {
+		length = r.Width
+	}
+	pos := 0
+	for i, size := range split(length, visible) {
+		child := r
+		if p.Split == Columns {
+			child.X += pos
+			child.Width = size
+		} else {
+			child.Y += pos
+			child.Height = size
+		}
+		pos += size
+		visible[i].compute(child, rects)
+	}
+}
+
+// Sizes panes along a length, the sizes add up to length unless every pane is at it's max
+func split(length int, panes []*Pane) []int {
+	sizes := make([]int, len(panes))
+	flex := []int{}
+	used := 0
+	for i, p := range panes {
+		if p.Size == 0 {
+			flex = append(flex, i)
+			continue
+		}
+		sizes[i] = p.clamp(int(float64(length) * p.Size))
+		used += sizes[i]
+	}
+	for n, i := range flex {
+		sizes[i] = panes[i].clamp(max(length-used, 0) / (len(flex) - n))
+		used += sizes[i]
+	}
+
+	// Space that is left over goes to the last panes that can grow
+	for i := len(panes) - 1; i >= 0 && used < length; i-- {
+		grow := length - used
+		if panes[i].Max > 0 {
+			grow = min(grow, panes[i].Max-sizes[i])
+		}
+		sizes[i] += grow
+		used += grow
+	}
+	// NOTE: When the screen is too small the last panes shrink to their min first, then past it
+	for i := len(panes) - 1; i >= 0 && used > length; i-- {
+		shrink := min(used-length, max(sizes[i]-panes[i].Min, 0))
+		sizes[i] -= shrink
+		used -= shrink
+	}
+	for i := len(panes) - 1; i >= 0 && used > length; i-- {
+		shrink := min(used-length, sizes[i])
+		sizes[i] -= shrink
+		used -= shrink
+	}
+	// NOTE: Every pane has the max it asked for when used is still short, the rest is left blank
+	return sizes
+}
+
+func (p *Pane) clamp(size int) int {
+	if p.Max > 0 {
+		size = min(size, p.Max)
+	}
+	return max(size, p.Min)
+}
@@ -0,0 +1,94 @@
+package layout
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name   string
+		length int
+		panes  []*Pane
+		want   []int
+	}{
+		{"flex", 10, []*Pane{{}, {}}, []int{5, 5}},
+		{"flex rounding", 10, []*Pane{{}, {}, {}}, []int{3, 3, 4}},
+		{"size", 100, []*Pane{{Size: 0.25}, {}}, []int{25, 75}},
+		{"min", 20, []*Pane{{Size: 0.1, Min: 3}, {}}, []int{3, 17}},
+		{"max", 100, []*Pane{{Size: 0.5, Max: 10}, {}}, []int{10, 90}},
+		{"flex max", 20, []*Pane{{Max: 5}, {}}, []int{5, 15}},
+		{"last at max", 100, []*Pane{{Size: 0.1}, {Max: 10}}, []int{90, 10}},
+		{"every pane at max", 20, []*Pane{{Max: 5}, {Max: 5}}, []int{5, 5}},
+		{"sizes at max", 100, []*Pane{{Size: 0.5, Max: 20}, {Size: 0.5, Max: 30}}, []int{20, 30}},
+		{"too small", 10, []*Pane{{Size: 0.5, Min: 3}, {Min: 20}}, []int{3, 7}},
+		{"too small for the mins", 4, []*Pane{{Min: 3}, {Min: 3}}, []int{3, 1}},
+		{"nothing", 0, []*Pane{{Min: 3}, {}}, []int{0, 0}},
+		{"no panes", 10, []*Pane{}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := split(tt.length, tt.panes)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("split(%d) = %v, want %v", tt.length, got, tt.want)
+			}
+			for i, size := range got {
+				if p := tt.panes[i]; p.Max > 0 && size > p.Max {
+					t.Errorf("split(%d) gave pane %d %d, past it's max %d", tt.length, i, size, p.Max)
+				}
+			}
+		})
+	}
+}
+
+func TestCompute(t *testing.T) {
+	tree := func(hidePlaylists, hidePlayer bool) *Pane {
+		return &Pane{
+			Children: []*Pane{
+				{
+					Children: []*Pane{
+						{Name: "playlists", Size: 0.25, Hidden: hidePlaylists},
+						{Name: "tracks"},
+					},
+					Split: Columns,
+				},
+				{Name: "player", Size: 0.1, Min: 3, Hidden: hidePlayer},
+			},
+			Split: Rows,
+		}
+	}
+	screen := Rect{Y: 1, Width: 100, Height: 30}
+	tests := []struct {
+		name          string
+		hidePlaylists bool
+		hidePlayer    bool
+		want          map[string]Rect
+	}{
+		{"all", false, false, map[string]Rect{
+			"playlists": {X: 0, Y: 1, Width: 25, Height: 27},
+			"tracks":    {X: 25, Y: 1, Width: 75, Height: 27},
+			"player":    {X: 0, Y: 28, Width: 100, Height: 3},
+		}},
+		{"hidden playlists", true, false, map[string]Rect{
+			"tracks": {X: 0, Y: 1, Width: 100, Height: 27},
+			"player": {X: 0, Y: 28, Width: 100, Height: 3},
+		}},
+		{"hidden player", false, true, map[string]Rect{
+			"playlists": {X: 0, Y: 1, Width: 25, Height: 30},
+			"tracks":    {X: 25, Y: 1, Width: 75, Height: 30},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tree(tt.hidePlaylists, tt.hidePlayer).Compute(screen)
+			if len(got) != len(tt.want) {
+				t.Errorf("Compute() = %v, want %v", got, tt.want)
+			}
+			for name, want := range tt.want {
+				if got[name] != want {
+					t.Errorf("Compute()[%q] = %+v, want %+v", name, got[name], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package list
+
+import (
+	"unicode"
+)
+
+// Scrollable list shown in a pane. Modes move through it & output draws the
+// rows inside of it's viewport. Rows are counted in the filtered view while
+// CursorPosY & Selected are indexes into Items
+type List[T any] struct {
+	CursorPosY int // -1 when there is nothing to point at
+	Filter     Filter
+	Height     int // Rows that fit in the viewport
+	Items      []T
+	RowOffset  int // First row in the viewport
+	Selected   int // Item that is in use (playing, loaded), -1 when there is none
+}
+
+// Narrows a list down to the items that fuzzy match Query
+type Filter struct {
+	Indices   []int         // Items that match in list order
+	Positions map[int][]int // Rune positions that matched by item index
+	Query     string        // Empty when the list isn't filtered
+	Wide      bool          // Every item is shown & matches are only highlighted, see NextMatch
+}
+
+func New[T any](items []T, height int) List[T] {
+	l := List[T]{
+		CursorPosY: 0,
+		Height:     height,
+		Items:      items,
+		Selected:   -1,
+	}
+	l.Fix()
+	return l
+}
+
+// Replaces the items, the filter is ran again using label
+func (l *List[T]) SetItems(items []T, label func(T) string) {
+	l.Items = items
+	wide := l.Filter.Wide
+	l.ApplyFilter(l.Filter.Query, label)
+	l.Filter.Wide = wide
+	l.Fix()
+	if l.Selected >= len(items) {
+		l.Selected = -1
+	}
+}
+
+// Item under the cursor, nil when there is none
+func (l *List[T]) Current() *T {
+	if l.CursorPosY < 0 || l.CursorPosY >= len(l.Items) {
+		return nil
+	}
+	return &l.Items[l.CursorPosY]
+}
+
+// Item that is selected, nil when there is none
+func (l *List[T]) SelectedItem() *T {
+	if l.Selected < 0 || l.Selected >= len(l.Items) {
+		return nil
+	}
+	return &l.Items[l.Selected]
+}
+
+// Runs query against the label of every item, an empty query removes the filter
+func (l *List[T]) ApplyFilter(query string, label func(T) string) {
+	l.Filter = Filter{Query: query}
+	if query != "" {
+		l.Filter.Indices = []int{}
+		l.Filter.Positions = map[int][]int{}
+		for i, item := range l.Items {
+			positions, ok := FuzzyMatch(query, label(item))
+			if !ok {
+				continue
+			}
+			l.Filter.Indices = append(l.Filter.Indices, i)
+			l.Filter.Positions[i] = positions
+		}
+	}
+	l.Fix()
+}
+
+// Number of rows shown for the list
+func (l *List[T]) NumRows() int {
+	if !l.IsNarrowed() {
+		return len(l.Items)
+	}
+	return len(l.Filter.Indices)
+}
+
+// Converts a row to an item index, -1 when there is no item
+func (l *List[T]) Index(row int) int {
+	if row < 0 || row >= l.NumRows() {
+		return -1
+	}
+	if !l.IsNarrowed() {
+		return row
+	}
+	return l.Filter.Indices[row]
+}
+
+// Converts an item index to a row, -1 when the item is filtered out
+func (l *List[T]) Row(index int) int {
+	if index < 0 || index >= len(l.Items) {
+		return -1
+	}
+	if !l.IsNarrowed() {
+		return index
+	}
+	for row, i := range l.Filter.Indices {
+		if i == index {
+			return row
+		}
+	}
+	return -1
+}
+
+// Item indexes of the rows inside of the viewport
+func (l *List[T]) VisibleRows() []int {
+	indexes := []int{}
+	for row := l.RowOffset; row < l.RowOffset+l.Height && row < l.NumRows(); row++ {
+		indexes = append(indexes, l.Index(row))
+	}
+	return indexes
+}
+
+// There is a query, the list is narrowed down to it or it's matches are highlighted
+func (l *List[T]) IsFiltered() bool {
+	return l.Filter.Query != ""
+}
+
+// Only the items that match are shown
+func (l *List[T]) IsNarrowed() bool {
+	return l.Filter.Query != "" && !l.Filter.Wide
+}
+
+// First row of the viewport
+func (l *List[T]) TopRow() int {
+	return l.RowOffset
+}
+
+// Number of rows on the current page
+func (l *List[T]) PageRows() int {
+	return max(min(l.Height, l.NumRows()-l.RowOffset), 1)
+}
+
+// Row of the cursor, the first row when the cursor isn't on a shown item
+func (l *List[T]) CursorRow() int {
+	return max(l.Row(l.CursorPosY), 0)
+}
+
+// Moves the cursor to row, rows past the ends are clamped
+func (l *List[T]) MoveTo(row int) {
+	numRows := l.NumRows()
+	if numRows == 0 {
+		return
+	}
+	row = min(max(row, 0), numRows-1)
+	l.CursorPosY = l.Index(row)
+	l.ScrollTo(row)
+}
+
+func (l *List[T]) MoveBy(by int) {
+	l.MoveTo(l.CursorRow() + by)
+}
+
+// Moves the cursor by matches of the filter, going past an end wraps around.
+// The list is widened so the rows between the matches are shown too (like n & N in vim)
+func (l *List[T]) NextMatch(by int) {
+	matches := l.Filter.Indices
+	if l.Filter.Query == "" || len(matches) == 0 {
+		return
+	}
+	l.Filter.Wide = true
+	// Match the cursor is on or the one before it, -1 when the cursor is before the first match
+	at := -1
+	for i, index := range matches {
+		if index <= l.CursorPosY {
+			at = i
+		}
+	}
+	if by < 0 && (at < 0 || matches[at] != l.CursorPosY) {
+		// Between matches the one before the cursor is the first step back
+		at++
+	}
+	n := len(matches)
+	l.MoveToIndex(matches[((at+by)%n+n)%n])
+}
+
+// Moves the cursor to the item at index
+func (l *List[T]) MoveToIndex(index int) {
+	row := l.Row(index)
+	if row < 0 {
+		return
+	}
+	l.MoveTo(row)
+}
+
+// Scrolls the viewport & the cursor together
+func (l *List[T]) ScrollBy(by int) {
+	maxOffset := max(l.NumRows()-l.Height, 0)
+	l.RowOffset = min(max(l.RowOffset+by, 0), maxOffset)
+	l.MoveTo(l.CursorRow() + by)
+}
+
+// Puts the cursor's row in the middle of the viewport
+func (l *List[T]) Center() {
+	maxOffset := max(l.NumRows()-l.Height, 0)
+	l.RowOffset = min(max(l.CursorRow()-l.Height/2, 0), maxOffset)
+}
+
+// Keeps row inside of the viewport
+func (l *List[T]) ScrollTo(row int) {
+	if row < l.RowOffset {
+		l.RowOffset = row
+	} else if l.Height > 0 && row >= l.RowOffset+l.Height {
+		l.RowOffset = row - l.Height + 1
+	}
+}
+
+// Changes the viewport height, keeping the cursor inside of it
+func (l *List[T]) SetHeight(height int) {
+	l.Height = max(height, 0)
+	maxOffset := max(l.NumRows()-l.Height, 0)
+	l.RowOffset = min(max(l.RowOffset, 0), maxOffset)
+	if row := l.Row(l.CursorPosY); row >= 0 {
+		l.ScrollTo(row)
+	}
+}
+
+// Puts the cursor back on a shown item after the items or the filter changed
+func (l *List[T]) Fix() {
+	if l.NumRows() == 0 {
+		l.CursorPosY = -1
+		l.RowOffset = 0
+		return
+	}
+	if l.CursorPosY >= len(l.Items) {
+		l.CursorPosY = len(l.Items) - 1
+	}
+	row := l.Row(l.CursorPosY)
+	if row < 0 {
+		l.RowOffset = 0
+		l.MoveTo(0)
+		return
+	}
+	l.MoveTo(row)
+}
+
+// Returns the rune positions in str that match every rune of pattern in order (case insensitive)
+func FuzzyMatch(pattern, str string) ([]int, bool) {
+	patternRunes := []rune(pattern)
+	if len(patternRunes) == 0 {
+		return nil, true
+	}
+	positions := []int{}
+	p := 0
+	for i, r := range []rune(str) {
+		if unicode.ToLower(r) == unicode.ToLower(patternRunes[p]) {
+			positions = append(positions, i)
+			p++
+			if p == len(patternRunes) {
+				return positions, true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,274 @@
+package list
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func label(s string) string {
+	return s
+}
+
+func TestNextMatch(t *testing.T) {
+	items := []string{"abc", "x", "ab", "y", "z", "b", "abz"}
+	// "ab" matches 0, 2 & 6
+	tests := []struct {
+		name   string
+		cursor int
+		by     int
+		want   int
+	}{
+		{"from a match", 2, 1, 6},
+		{"back from a match", 2, -1, 0},
+		{"between matches", 3, 1, 6},
+		{"back between matches", 3, -1, 2},
+		{"wraps forward", 6, 1, 0},
+		{"wraps back", 0, -1, 6},
+		{"count", 0, 2, 6},
+		{"count wraps", 2, 4, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(items, 3)
+			l.ApplyFilter("ab", label)
+			l.Filter.Wide = true
+			l.MoveToIndex(tt.cursor)
+			l.NextMatch(tt.by)
+			if l.CursorPosY != tt.want {
+				t.Errorf("NextMatch(%d) from %d = %d, want %d", tt.by, tt.cursor, l.CursorPosY, tt.want)
+			}
+		})
+	}
+}
+
+// n on a narrowed list shows every row again, / narrows it back down
+func TestNextMatchWidens(t *testing.T) {
+	l := New([]string{"abc", "x", "ab", "y"}, 10)
+	l.ApplyFilter("ab", label)
+	if !l.IsNarrowed() || l.NumRows() != 2 {
+		t.Fatalf("ApplyFilter() shows %d rows, want 2 narrowed rows", l.NumRows())
+	}
+	l.NextMatch(1)
+	if l.IsNarrowed() || l.NumRows() != 4 {
+		t.Errorf("NextMatch() shows %d rows, want all 4", l.NumRows())
+	}
+	if !l.IsFiltered() || l.CursorPosY != 2 {
+		t.Errorf("NextMatch() = %d, filtered %v, want the cursor on 2 with the filter kept", l.CursorPosY, l.IsFiltered())
+	}
+	// Reloading the items keeps the list wide
+	l.SetItems([]string{"abc", "x", "ab", "y", "abd"}, label)
+	if l.IsNarrowed() || l.NumRows() != 5 || l.CursorPosY != 2 {
+		t.Errorf("SetItems() shows %d rows with the cursor on %d, want 5 wide rows & the cursor on 2", l.NumRows(), l.CursorPosY)
+	}
+	l.ApplyFilter(l.Filter.Query, label)
+	if !l.IsNarrowed() || l.NumRows() != 3 {
+		t.Errorf("ApplyFilter() shows %d rows, want 3 narrowed rows", l.NumRows())
+	}
+}
+
+func TestNextMatchWithoutFilter(t *testing.T) {
+	l := New([]string{"a", "b"}, 10)
+	l.NextMatch(1)
+	if l.CursorPosY != 0 || l.Filter.Wide {
+		t.Errorf("NextMatch() without a filter moved to %d", l.CursorPosY)
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		str       string
+		positions []int
+		ok        bool
+	}{
+		{"", "abc", nil, true},
+		{"abc", "abc", []int{0, 1, 2}, true},
+		{"ac", "abc", []int{0, 2}, true},
+		{"AC", "abc", []int{0, 2}, true},
+		{"ac", "ABC", []int{0, 2}, true},
+		{"ca", "abc", nil, false},
+		{"abcd", "abc", nil, false},
+		{"é", "café", []int{3}, true},
+		{"b", "", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"/"+tt.str, func(t *testing.T) {
+			positions, ok := FuzzyMatch(tt.pattern, tt.str)
+			if ok != tt.ok || !slices.Equal(positions, tt.positions) {
+				t.Errorf("FuzzyMatch(%q, %q) = %v, %v, want %v, %v", tt.pattern, tt.str, positions, ok, tt.positions, tt.ok)
+			}
+		})
+	}
+}
+
+// Rows count the shown items, indexes count every item
+func TestRowIndex(t *testing.T) {
+	l := New([]string{"ab", "x", "ab", "y", "ab"}, 10)
+	l.ApplyFilter("ab", label)
+	tests := []struct {
+		row   int
+		index int
+	}{
+		{0, 0},
+		{1, 2},
+		{2, 4},
+	}
+	for _, tt := range tests {
+		if got := l.Index(tt.row); got != tt.index {
+			t.Errorf("Index(%d) = %d, want %d", tt.row, got, tt.index)
+		}
+		if got := l.Row(tt.index); got != tt.row {
+			t.Errorf("Row(%d) = %d, want %d", tt.index, got, tt.row)
+		}
+	}
+	for _, row := range []int{-1, 3} {
+		if got := l.Index(row); got != -1 {
+			t.Errorf("Index(%d) = %d, want -1", row, got)
+		}
+	}
+	for _, index := range []int{-1, 1, 3, 5} {
+		if got := l.Row(index); got != -1 {
+			t.Errorf("Row(%d) = %d, want -1 for an item that isn't shown", index, got)
+		}
+	}
+	l.Filter.Wide = true
+	if l.Index(3) != 3 || l.Row(3) != 3 {
+		t.Errorf("Index(3), Row(3) = %d, %d on a wide list, want 3, 3", l.Index(3), l.Row(3))
+	}
+}
+
+func numbers(n int) []string {
+	items := []string{}
+	for i := range n {
+		items = append(items, strconv.Itoa(i))
+	}
+	return items
+}
+
+func TestMoveTo(t *testing.T) {
+	tests := []struct {
+		row    int
+		cursor int
+		offset int
+	}{
+		{0, 0, 0},
+		{2, 2, 0},
+		{3, 3, 1},
+		{5, 5, 3},
+		{9, 9, 7},
+		{99, 9, 7},
+		{-4, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.row), func(t *testing.T) {
+			l := New(numbers(10), 3)
+			l.MoveTo(tt.row)
+			if l.CursorPosY != tt.cursor || l.RowOffset != tt.offset {
+				t.Errorf("MoveTo(%d) = cursor %d offset %d, want %d, %d", tt.row, l.CursorPosY, l.RowOffset, tt.cursor, tt.offset)
+			}
+		})
+	}
+	// Scrolling back up only moves the viewport once the cursor leaves it
+	l := New(numbers(10), 3)
+	l.MoveTo(9)
+	l.MoveTo(8)
+	if l.RowOffset != 7 {
+		t.Errorf("MoveTo(8) from the end scrolled to %d, want 7", l.RowOffset)
+	}
+	l.MoveTo(4)
+	if l.RowOffset != 4 {
+		t.Errorf("MoveTo(4) from the end scrolled to %d, want 4", l.RowOffset)
+	}
+	// Rows are counted in the filtered view
+	l = New([]string{"a", "b", "a", "b", "a"}, 3)
+	l.ApplyFilter("a", label)
+	l.MoveTo(2)
+	if l.CursorPosY != 4 {
+		t.Errorf("MoveTo(2) on a filtered list = %d, want item 4", l.CursorPosY)
+	}
+}
+
+func TestScrollBy(t *testing.T) {
+	tests := []struct {
+		name   string
+		start  int
+		by     int
+		cursor int
+		offset int
+	}{
+		{"down", 0, 2, 2, 2},
+		{"up", 5, -2, 3, 1},
+		{"past the end", 0, 100, 9, 7},
+		{"past the start", 9, -100, 0, 0},
+		{"at the end", 9, 1, 9, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(numbers(10), 3)
+			l.MoveTo(tt.start)
+			l.ScrollBy(tt.by)
+			if l.CursorPosY != tt.cursor || l.RowOffset != tt.offset {
+				t.Errorf("ScrollBy(%d) from %d = cursor %d offset %d, want %d, %d", tt.by, tt.start, l.CursorPosY, l.RowOffset, tt.cursor, tt.offset)
+			}
+		})
+	}
+}
+
+func TestSetHeight(t *testing.T) {
+	tests := []struct {
+		name   string
+		height int
+		want   int // Height after it was set
+		offset int
+	}{
+		{"taller", 5, 5, 5},
+		{"taller than the list", 20, 20, 0},
+		{"shorter", 1, 1, 9},
+		{"negative", -2, 0, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(numbers(10), 3)
+			l.MoveTo(9)
+			l.SetHeight(tt.height)
+			if l.Height != tt.want || l.RowOffset != tt.offset {
+				t.Errorf("SetHeight(%d) = height %d offset %d, want %d, %d", tt.height, l.Height, l.RowOffset, tt.want, tt.offset)
+			}
+			if l.CursorPosY != 9 {
+				t.Errorf("SetHeight(%d) moved the cursor to %d", tt.height, l.CursorPosY)
+			}
+		})
+	}
+}
+
+func TestFix(t *testing.T) {
+	// The cursor's item is filtered out, it goes to the first shown row
+	l := New([]string{"ab", "x", "ab", "y"}, 10)
+	l.MoveTo(3)
+	l.ApplyFilter("ab", label)
+	if l.CursorPosY != 0 {
+		t.Errorf("ApplyFilter() left the cursor on %d, want 0", l.CursorPosY)
+	}
+	// The cursor's item is kept, it stays on it
+	l.MoveTo(1)
+	l.ApplyFilter("", label)
+	if l.CursorPosY != 2 {
+		t.Errorf("ApplyFilter(\"\") moved the cursor to %d, want 2", l.CursorPosY)
+	}
+	// The list got shorter than the cursor
+	l.MoveTo(3)
+	l.SetItems([]string{"a", "b"}, label)
+	if l.CursorPosY != 1 {
+		t.Errorf("SetItems() left the cursor on %d, want 1", l.CursorPosY)
+	}
+	// Nothing is shown
+	l.ApplyFilter("zzz", label)
+	if l.CursorPosY != -1 || l.RowOffset != 0 || l.Current() != nil {
+		t.Errorf("ApplyFilter() with no matches = cursor %d offset %d, want -1, 0", l.CursorPosY, l.RowOffset)
+	}
+	l.ApplyFilter("", label)
+	if l.CursorPosY != 0 {
+		t.Errorf("ApplyFilter(\"\") after no matches = cursor %d, want 0", l.CursorPosY)
+	}
+}
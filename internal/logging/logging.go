@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NOTE: The app owns the terminal so everything is logged to a file in the state dir
+const fileName = "neofy.log"
+
+// Level of the default logger, changed by the config while the app runs
+var level slog.LevelVar
+
+// Set by --debug, the config can't change the level then
+var forced bool
+
+// Logs to neofy.log in the state dir at the info level, or at the debug level when debug is set.
+// The returned closer closes the file
+func Setup(debug bool) (io.Closer, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return nil, fmt.Errorf("Setup: %w", err)
+	}
+	err = os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, fmt.Errorf("Setup: %w", err)
+	}
+	file, err := openRotating(filepath.Join(dir, fileName))
+	if err != nil {
+		return nil, fmt.Errorf("Setup: %w", err)
+	}
+	if debug {
+		level.Set(slog.LevelDebug)
+		forced = true
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(file, &slog.HandlerOptions{Level: &level})))
+	return file, nil
+}
+
+// Level set in the config, ignored when --debug was passed
+func SetLevel(l slog.Level) {
+	if forced {
+		return
+	}
+	level.Set(l)
+}
+
+// Parses debug, info, warn or error
+func ParseLevel(str string) (slog.Level, error) {
+	switch strings.ToLower(str) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, errors.New("ParseLevel: must be debug, info, warn or error")
+}
+
+// Where the log is written
+func Path() (string, error) {
+	dir, err := StateDir()
+	if err != nil {
+		return "", fmt.Errorf("Path: %w", err)
+	}
+	return filepath.Join(dir, fileName), nil
+}
+
+// $XDG_STATE_HOME/neofy, ~/.local/state/neofy when it isn't set. Other state that is kept between runs goes here too
+func StateDir() (string, error) {
+	dir := os.Getenv("XDG_STATE_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("StateDir: %w", err)
+		}
+		dir = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(dir, "neofy"), nil
+}
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Size a log can grow to before it's rotated
+const maxSize = 5 << 20
+
+// Rotated logs that are kept (neofy.log.1 to neofy.log.3), the oldest is removed
+const maxBackups = 3
+
+// Log file that is moved to path.1 once it's full, older logs move up a number
+type rotatingFile struct {
+	file *os.File
+	mu   sync.Mutex
+	path string
+	size int64
+}
+
+func openRotating(path string) (*rotatingFile, error) {
+	r := rotatingFile{path: path}
+	err := r.open()
+	if err != nil {
+		return nil, fmt.Errorf("openRotating: %w", err)
+	}
+	return &r, nil
+}
+
+// Appends to the log at path
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("open: %w", err)
+	}
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.size > 0 && r.size+int64(len(p)) > maxSize {
+		err := r.rotate()
+		if err != nil {
+			return 0, fmt.Errorf("Write: %w", err)
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) rotate() error {
+	err := r.file.Close()
+	if err != nil {
+		return fmt.Errorf("rotate: %w", err)
+	}
+	// NOTE: Renaming over the oldest backup removes it
+	for i := maxBackups - 1; i > 0; i-- {
+		os.Rename(r.path+"."+strconv.Itoa(i), r.path+"."+strconv.Itoa(i+1))
+	}
+	err = os.Rename(r.path, r.path+".1")
+	if err != nil {
+		return fmt.Errorf("rotate: %w", err)
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
@@ -0,0 +1,39 @@
+package match
+
+import (
+	"errors"
+	"neofy/internal/list"
+	"strings"
+)
+
+// Finds name in names ignoring case, falls back to a unique prefix & then a fuzzy match
+func Name(names []string, name string) (int, error) {
+	if name == "" {
+		return -1, errors.New("Name: name is empty")
+	}
+	for i, n := range names {
+		if strings.EqualFold(n, name) {
+			return i, nil
+		}
+	}
+	matches := []int{}
+	for i, n := range names {
+		if strings.HasPrefix(strings.ToLower(n), strings.ToLower(name)) {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 {
+		for i, n := range names {
+			if _, ok := list.FuzzyMatch(name, n); ok {
+				matches = append(matches, i)
+			}
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return -1, errors.New("Name: no match for " + name)
+	case 1:
+		return matches[0], nil
+	}
+	return -1, errors.New("Name: " + name + " matches more than one name")
+}
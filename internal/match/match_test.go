@@ -0,0 +1,36 @@
+package match
+
+import "testing"
+
+func TestName(t *testing.T) {
+	names := []string{"Living Room", "Kitchen", "Kitchen Speaker", "Laptop"}
+	tests := []struct {
+		name    string
+		want    int
+		wantErr bool
+	}{
+		{name: "kitchen", want: 1},     // Exact wins over the prefix of Kitchen Speaker
+		{name: "LIVING ROOM", want: 0}, // Case is ignored
+		{name: "liv", want: 0},         // Unique prefix
+		{name: "kitchen s", want: 2},   // Unique prefix
+		{name: "lvrm", want: 0},        // Fuzzy when nothing starts with it
+		{name: "l", wantErr: true},     // Living Room & Laptop
+		{name: "speaker", want: 2},     // Fuzzy
+		{name: "tv", wantErr: true},    // Nothing
+		{name: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Name(names, tt.name)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Name(%q) = %d, want an error", tt.name, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Name(%q) = %d, %v, want %d", tt.name, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,384 @@
+package mode
+
+import (
+	"errors"
+	"fmt"
+	"neofy/internal/consts"
+	"neofy/internal/data"
+	"neofy/internal/match"
+	"neofy/internal/terminal"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Max number of commands kept in d.Command.History
+const commandHistorySize = 100
+
+// Vim like command line (:vol 35), the input is kept in d.Prompt
+type Command struct {
+	completeAt    int      // Candidate the next Tab inserts
+	completeStart int      // Rune the completed word starts at
+	completions   []string // Nil when Tab wasn't the last key
+	draft         []rune   // Input typed before browsing the history
+	historyPos    int      // len(History) when not browsing
+	Previous      data.Mode
+}
+
+func (c *Command) ProcessInput(d *data.AppData) {
+	key := terminal.ReadKeyEvent()
+	if key.Rune() != consts.TAB {
+		c.completions = nil
+	}
+	switch key.Rune() {
+	case consts.CONTROLCASCII, consts.ESC:
+		c.close(d)
+	case consts.BACKSPACE:
+		// Deleting past the start closes the command line like vim
+		if len(d.Prompt.Input) == 0 {
+			c.close(d)
+			break
+		}
+		editPrompt(d, key)
+	case '\r':
+		line := strings.TrimSpace(string(d.Prompt.Input))
+		c.close(d)
+		if line == "" {
+			break
+		}
+		addCommandHistory(d, line)
+		err := request(d, ":"+line, func() error { return runCommand(d, line) })
+		if err != nil {
+			break
+		}
+	case consts.TAB:
+		c.complete(d)
+	case consts.UP_ARROW:
+		c.browseHistory(d, -1)
+	case consts.DOWN_ARROW:
+		c.browseHistory(d, 1)
+	default:
+		editPrompt(d, key)
+	}
+}
+
+func (*Command) ShortDisplay() rune {
+	return 'C'
+}
+
+// Opens the command line, the current mode is returned to once a command runs
+func startCommand(d *data.AppData) {
+	d.Prompt = data.Prompt{Label: ":"}
+	d.Mode = &Command{
+		historyPos: len(d.Command.History),
+		Previous:   d.Mode,
+	}
+}
+
+func (c *Command) close(d *data.AppData) {
+	d.Mode = c.Previous
+	d.Prompt = data.Prompt{}
+}
+
+// Moves through the history, moving past the newest command brings back what was typed
+func (c *Command) browseHistory(d *data.AppData, by int) {
+	pos := c.historyPos + by
+	if pos < 0 || pos > len(d.Command.History) {
+		return
+	}
+	if c.historyPos == len(d.Command.History) {
+		c.draft = slices.Clone(d.Prompt.Input)
+	}
+	c.historyPos = pos
+	if pos == len(d.Command.History) {
+		d.Prompt.Input = c.draft
+		return
+	}
+	d.Prompt.Input = []rune(d.Command.History[pos])
+}
+
+func addCommandHistory(d *data.AppData, line string) {
+	history := d.Command.History
+	if len(history) > 0 && history[len(history)-1] == line {
+		return
+	}
+	history = append(history, line)
+	if len(history) > commandHistorySize {
+		history = history[len(history)-commandHistorySize:]
+	}
+	d.Command.History = history
+}
+
+// Completes the command name or it's argument, pressing Tab again cycles through the matches
+func (c *Command) complete(d *data.AppData) {
+	if c.completions == nil {
+		input := string(d.Prompt.Input)
+		name, arg, hasArg := strings.Cut(input, " ")
+		candidates := []string{}
+		prefix := name
+		c.completeStart = 0
+		if !hasArg {
+			for _, cmd := range commands {
+				candidates = append(candidates, cmd.name)
+			}
+		} else {
+			cmd, err := findCommand(name)
+			if err != nil || cmd.complete == nil {
+				return
+			}
+			candidates = cmd.complete(d)
+			prefix = arg
+			c.completeStart = len([]rune(name)) + 1
+		}
+		c.completions = []string{}
+		for _, candidate := range candidates {
+			if strings.HasPrefix(strings.ToLower(candidate), strings.ToLower(prefix)) {
+				c.completions = append(c.completions, candidate)
+			}
+		}
+		c.completeAt = 0
+	}
+	if len(c.completions) == 0 {
+		return
+	}
+	input := d.Prompt.Input[:c.completeStart]
+	d.Prompt.Input = append(slices.Clone(input), []rune(c.completions[c.completeAt])...)
+	c.completeAt = (c.completeAt + 1) % len(c.completions)
+}
+
+type command struct {
+	aliases  []string
+	complete func(*data.AppData) []string // Optional, candidates for the argument
+	name     string
+	run      func(*data.AppData, string) error
+}
+
+var commands = []command{
+	{name: "device", run: deviceCommand, complete: deviceNames},
+	{name: "messages", run: func(d *data.AppData, _ string) error {
+		enterMessages(d)
+		return nil
+	}},
+	{name: "next", run: func(d *data.AppData, _ string) error { return skipTrack(d, true) }},
+	{name: "pause", run: func(d *data.AppData, _ string) error { return pausePlayback(d) }},
+	{name: "play", run: playCommand},
+	{name: "playlist", run: playlistCommand, complete: playlistNames},
+	{name: "prev", aliases: []string{"previous"}, run: func(d *data.AppData, _ string) error { return skipTrack(d, false) }},
+	{name: "quit", aliases: []string{"q"}, run: func(d *data.AppData, _ string) error {
+		d.Quitting = true
+		return nil
+	}},
+	{name: "repeat", run: repeatCommand, complete: func(*data.AppData) []string { return []string{"off", "context", "track"} }},
+	{name: "seek", run: seekCommand},
+	{name: "shuffle", run: shuffleCommand, complete: func(*data.AppData) []string { return []string{"on", "off"} }},
+	{name: "vol", aliases: []string{"volume"}, run: volumeCommand},
+}
+
+// Finds a command by it's name or alias, a unique prefix is enough (:dev, :sh)
+func findCommand(name string) (*command, error) {
+	if name == "" {
+		return nil, errors.New("findCommand: no command")
+	}
+	// NOTE: An exact name wins over prefixes even when it comes later
+	for i, cmd := range commands {
+		if cmd.name == name || slices.Contains(cmd.aliases, name) {
+			return &commands[i], nil
+		}
+	}
+	matches := []string{}
+	var match *command
+	for i, cmd := range commands {
+		if strings.HasPrefix(cmd.name, name) {
+			matches = append(matches, cmd.name)
+			match = &commands[i]
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, errors.New("findCommand: unknown command: " + name)
+	case 1:
+		return match, nil
+	}
+	return nil, errors.New("findCommand: ambiguous command: " + name + " (" + strings.Join(matches, ", ") + ")")
+}
+
+// Parses line into a command & it's argument, then runs it
+func runCommand(d *data.AppData, line string) error {
+	name, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+	cmd, err := findCommand(name)
+	if err != nil {
+		return fmt.Errorf("runCommand: %w", err)
+	}
+	err = cmd.run(d, strings.TrimSpace(arg))
+	if err != nil {
+		return fmt.Errorf("runCommand: %s: %w", cmd.name, err)
+	}
+	return nil
+}
+
+// :play resumes, :play <uri> plays a track, playlist, album or artist
+func playCommand(d *data.AppData, uri string) error {
+	if uri == "" {
+		return resumePlayback(d)
+	}
+	err := d.Player.Controller.PlayUri(uri, d.Spotify.UserTokens.AccessToken)
+	if err != nil {
+		return fmt.Errorf("playCommand: %w", err)
+	}
+	err = refreshPlayer(d.Spotify.UserTokens.AccessToken, &d.Player)
+	if err != nil {
+		return fmt.Errorf("playCommand: %w", err)
+	}
+	return nil
+}
+
+// :vol 35 sets the volume, :vol +5 & :vol -5 change it
+func volumeCommand(d *data.AppData, arg string) error {
+	volume, err := strconv.Atoi(arg)
+	if err != nil {
+		return fmt.Errorf("volumeCommand: %w", err)
+	}
+	if strings.HasPrefix(arg, "+") || strings.HasPrefix(arg, "-") {
+		volume += d.Player.Volume
+	}
+	return setVolume(d, volume)
+}
+
+// :seek 1:20 goes to that position, :seek +10 & :seek -10 move from the current position
+func seekCommand(d *data.AppData, arg string) error {
+	position, relative, err := parseSeek(arg)
+	if err != nil {
+		return fmt.Errorf("seekCommand: %w", err)
+	}
+	if relative {
+		progress := time.Duration(0)
+		if d.Player.PlayingSong.Progress != nil {
+			progress = *d.Player.PlayingSong.Progress
+		}
+		position += progress
+	}
+	return seekTo(d, position)
+}
+
+// 1:20 is a position, +10 & -10 move from the progress (the position is negative for -)
+func parseSeek(arg string) (time.Duration, bool, error) {
+	// Only one sign, +-10 isn't a position
+	unsigned := strings.TrimPrefix(strings.TrimPrefix(arg, "+"), "-")
+	if len(arg)-len(unsigned) > 1 {
+		return 0, false, errors.New("parseSeek: expected one sign: " + arg)
+	}
+	position, err := parsePosition(unsigned)
+	if err != nil {
+		return 0, false, fmt.Errorf("parseSeek: %w", err)
+	}
+	if strings.HasPrefix(arg, "-") {
+		position = -position
+	}
+	return position, unsigned != arg, nil
+}
+
+// Parses seconds (80), minutes & seconds (1:20) or hours, minutes & seconds (1:01:20)
+func parsePosition(str string) (time.Duration, error) {
+	parts := strings.Split(str, ":")
+	if len(parts) > 3 {
+		return 0, errors.New("parsePosition: expected [[h:]m:]s")
+	}
+	position := time.Duration(0)
+	for i, part := range parts {
+		// NOTE: Atoi takes a sign, only digits are a position
+		num, err := strconv.Atoi(part)
+		if err != nil || strings.ContainsAny(part, "+-") {
+			return 0, errors.New("parsePosition: not a number: " + part)
+		}
+		// Everything but the first number must fit in a clock
+		if i > 0 && num >= 60 {
+			return 0, errors.New("parsePosition: out of range: " + part)
+		}
+		position = position*60 + time.Duration(num)*time.Second
+	}
+	return position, nil
+}
+
+func repeatCommand(d *data.AppData, mode string) error {
+	switch mode {
+	case "off", "context", "track":
+		return setRepeat(d, mode)
+	}
+	return errors.New("repeatCommand: expected off, context or track")
+}
+
+// :shuffle toggles, :shuffle on & :shuffle off set it
+func shuffleCommand(d *data.AppData, arg string) error {
+	switch arg {
+	case "":
+		return setShuffle(d, !d.Player.IsShuffled)
+	case "on":
+		return setShuffle(d, true)
+	case "off":
+		return setShuffle(d, false)
+	}
+	return errors.New("shuffleCommand: expected on or off")
+}
+
+// Moves playback to the device with name, a unique prefix is enough
+func deviceCommand(d *data.AppData, name string) error {
+	devices, err := d.Player.Controller.AvailableDevices(d.Spotify.UserTokens.AccessToken)
+	if err != nil {
+		return fmt.Errorf("deviceCommand: %w", err)
+	}
+	names := []string{}
+	for _, device := range devices {
+		names = append(names, device.Name)
+	}
+	index, err := match.Name(names, name)
+	if err != nil {
+		return fmt.Errorf("deviceCommand: %w", err)
+	}
+	device := devices[index]
+	err = d.Player.Controller.TransferPlayback(d.Spotify.UserTokens.AccessToken, device.Id, d.Player.IsPlaying)
+	if err != nil {
+		return fmt.Errorf("deviceCommand: %w", err)
+	}
+	d.Player.SupportsVolume = device.SupportsVolume
+	d.Player.Volume = device.Volume
+	notify(d, data.Success, "Playing on "+device.Name)
+	return nil
+}
+
+func deviceNames(d *data.AppData) []string {
+	devices, err := d.Player.Controller.AvailableDevices(d.Spotify.UserTokens.AccessToken)
+	if err != nil {
+		return nil
+	}
+	names := []string{}
+	for _, device := range devices {
+		names = append(names, device.Name)
+	}
+	return names
+}
+
+// Loads the playlist with name & shows it's tracks, a unique prefix is enough
+func playlistCommand(d *data.AppData, name string) error {
+	index, err := match.Name(playlistNames(d), name)
+	if err != nil {
+		return fmt.Errorf("playlistCommand: %w", err)
+	}
+	err = selectPlaylist(d, index)
+	if err != nil {
+		return fmt.Errorf("playlistCommand: %w", err)
+	}
+	// The playlist could be hidden by the filter
+	d.Playlist.ApplyFilter("", data.PlaylistDetail.Label)
+	d.Playlist.MoveToIndex(index)
+	d.Mode = &Track{}
+	return nil
+}
+
+func playlistNames(d *data.AppData) []string {
+	names := []string{}
+	for _, p := range d.Playlist.Items {
+		names = append(names, p.Name)
+	}
+	return names
+}
@@ -0,0 +1,95 @@
+package mode
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSeek(t *testing.T) {
+	tests := []struct {
+		arg      string
+		want     time.Duration
+		relative bool
+		wantErr  bool
+	}{
+		{arg: "80", want: 80 * time.Second},
+		{arg: "1:20", want: 80 * time.Second},
+		{arg: "1:01:20", want: time.Hour + 80*time.Second},
+		{arg: "+10", want: 10 * time.Second, relative: true},
+		{arg: "-10", want: -10 * time.Second, relative: true},
+		{arg: "-1:00", want: -time.Minute, relative: true},
+		{arg: "+-10", wantErr: true},
+		{arg: "-+10", wantErr: true},
+		{arg: "--10", wantErr: true},
+		{arg: "++10", wantErr: true},
+		{arg: "1:+20", wantErr: true},
+		{arg: "1:60", wantErr: true},
+		{arg: "1:2:3:4", wantErr: true},
+		{arg: "", wantErr: true},
+		{arg: "+", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			got, relative, err := parseSeek(tt.arg)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseSeek(%q) = %v, want an error", tt.arg, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSeek(%q) = %v", tt.arg, err)
+			}
+			if got != tt.want || relative != tt.relative {
+				t.Errorf("parseSeek(%q) = %v, %v, want %v, %v", tt.arg, got, relative, tt.want, tt.relative)
+			}
+		})
+	}
+}
+
+func TestFindCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{name: "play", want: "play"},
+		{name: "playl", want: "playlist"},
+		{name: "dev", want: "device"},
+		{name: "q", want: "quit"},
+		{name: "previous", want: "prev"},
+		{name: "volume", want: "vol"},
+		{name: "p", wantErr: true},
+		{name: "pl", wantErr: true},
+		{name: "nope", wantErr: true},
+		{name: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := findCommand(tt.name)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("findCommand(%q) = %s, want an error", tt.name, cmd.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("findCommand(%q) = %v", tt.name, err)
+			}
+			if cmd.name != tt.want {
+				t.Errorf("findCommand(%q) = %s, want %s", tt.name, cmd.name, tt.want)
+			}
+		})
+	}
+}
+
+// An exact name later in the list wins over an earlier command it's a prefix of
+func TestFindCommandExactAfterPrefix(t *testing.T) {
+	saved := commands
+	defer func() { commands = saved }()
+	commands = []command{{name: "shuffle"}, {name: "shuf"}, {name: "shuffled"}}
+	cmd, err := findCommand("shuf")
+	if err != nil || cmd.name != "shuf" {
+		t.Errorf("findCommand(\"shuf\") = %v, %v, want shuf", cmd, err)
+	}
+}
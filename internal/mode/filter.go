@@ -0,0 +1,19 @@
+package mode
+
+import (
+	"neofy/internal/data"
+	"neofy/internal/list"
+)
+
+// Starts an incremental filter on l that is updated as the user types, ESC removes it
+func startFilter[T any](d *data.AppData, l *list.List[T], label func(T) string) {
+	// NOTE: A list widened by n & N is narrowed down to the matches again
+	l.ApplyFilter(l.Filter.Query, label)
+	prompt := startPrompt(d, "/", l.Filter.Query, func(*data.AppData, string) {})
+	prompt.OnChange = func(_ *data.AppData, query string) {
+		l.ApplyFilter(query, label)
+	}
+	prompt.OnCancel = func(*data.AppData) {
+		l.ApplyFilter("", label)
+	}
+}
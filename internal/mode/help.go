@@ -0,0 +1,118 @@
+package mode
+
+import (
+	"fmt"
+	"neofy/internal/consts"
+	"neofy/internal/data"
+	"neofy/internal/keymap"
+	"neofy/internal/terminal"
+	"strings"
+	"unicode/utf8"
+)
+
+// Shows the key binds of the mode it was opened from in a box over the panes, the binds
+// are read from the keymap so rebinds show up
+type Help struct {
+	Previous data.Mode
+}
+
+// Keymap modes by ShortDisplay
+var keymapModes = map[rune]string{
+	'A': keymap.Picker,
+	'H': keymap.History,
+	'M': keymap.Messages,
+	'P': keymap.Player,
+	'T': keymap.Track,
+	'U': keymap.Playlist,
+}
+
+// Titles of the sections of the help
+var keymapTitles = map[string]string{
+	keymap.History:  "History",
+	keymap.List:     "Lists",
+	keymap.Messages: "Messages",
+	keymap.Picker:   "Add To Playlist",
+	keymap.Player:   "Player",
+	keymap.Playlist: "Playlists",
+	keymap.Track:    "Tracks",
+}
+
+func (h *Help) ProcessInput(d *data.AppData) {
+	switch terminal.ReadInputKey() {
+	case consts.CONTROLCASCII, consts.ESC, '?', 'q':
+		d.Mode = h.Previous
+		d.Help = data.Help{}
+	case 'j', consts.DOWN_ARROW:
+		scrollHelp(&d.Help, 1)
+	case 'k', consts.UP_ARROW:
+		scrollHelp(&d.Help, -1)
+	case consts.CONTROL_D, consts.PAGE_DOWN, ' ':
+		scrollHelp(&d.Help, max(d.Help.Height, 1))
+	case consts.CONTROL_U, consts.PAGE_UP:
+		scrollHelp(&d.Help, -max(d.Help.Height, 1))
+	case 'g', consts.HOME_KEY:
+		scrollHelp(&d.Help, -len(d.Help.Lines))
+	case 'G', consts.END_KEY:
+		scrollHelp(&d.Help, len(d.Help.Lines))
+	}
+}
+
+func (*Help) ShortDisplay() rune {
+	return '?'
+}
+
+// Opens the help for the current mode
+func startHelp(d *data.AppData) {
+	name, ok := keymapModes[d.Mode.ShortDisplay()]
+	if !ok {
+		return
+	}
+	lines := helpSection(d.Keymap, name)
+	if keymap.UsesList(name) {
+		lines = append(lines, "")
+		lines = append(lines, helpSection(d.Keymap, keymap.List)...)
+	}
+	d.Help = data.Help{
+		Lines: lines,
+		Title: "Help: " + keymapTitles[name],
+	}
+	d.Mode = &Help{Previous: d.Mode}
+}
+
+// Title of the section & a line for every action with the keys bound to it
+func helpSection(k *keymap.Keymap, mode string) []string {
+	bindings := k.Bindings(mode)
+	keys := make([]string, len(bindings))
+	keysWidth := 0
+	for i, b := range bindings {
+		keys[i] = strings.Join(b.Keys, " ")
+		keysWidth = max(keysWidth, utf8.RuneCountInString(keys[i]))
+	}
+	lines := []string{keymapTitles[mode] + ":"}
+	for i, b := range bindings {
+		pad := strings.Repeat(" ", keysWidth-utf8.RuneCountInString(keys[i]))
+		lines = append(lines, fmt.Sprintf("  %s%s  %s", keys[i], pad, b.Action))
+	}
+	if len(bindings) == 0 {
+		lines = append(lines, "  Nothing is bound")
+	}
+	return lines
+}
+
+// Moves the lines by, the last line stops at the bottom of the box
+func scrollHelp(h *data.Help, by int) {
+	h.Offset = min(max(h.Offset+by, 0), max(len(h.Lines)-h.Height, 0))
+}
+
+// Mode the prompt, command line or help was opened from, otherwise m
+func Underlying(m data.Mode) data.Mode {
+	switch m := m.(type) {
+	case *Prompt:
+		return Underlying(m.Previous)
+	case *Command:
+		return Underlying(m.Previous)
+	case *Help:
+		return Underlying(m.Previous)
+	}
+	return m
+}
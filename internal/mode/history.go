@@ -0,0 +1,181 @@
+package mode
+
+import (
+	"errors"
+	"fmt"
+	"neofy/internal/data"
+	"neofy/internal/keymap"
+	"neofy/internal/list"
+	"neofy/internal/spotify"
+	"time"
+)
+
+// Max number of items spotify returns per request
+const historyPageSize = 50
+
+type History struct{}
+
+func (h *History) ProcessInput(d *data.AppData) {
+	action, count := readCountedAction(d, keymap.History)
+	h.runAction(d, action, count)
+}
+
+// Runs the action of a key bind, clicks run their actions through here too
+func (*History) runAction(d *data.AppData, action keymap.Action, count int) {
+	if navigate(&d.History.List, action, count, d.History.Height) {
+		// Page in older items once the last one is reached
+		if d.History.CursorPosY == len(d.History.Items)-1 && d.History.Before != "" {
+			request(d, "Fetch history", func() error { return loadOlderHistory(d) })
+		}
+		return
+	}
+	switch action {
+	case keymap.ListBack:
+		d.Mode = &Player{}
+	case keymap.HistoryPlay:
+		// Replay the track under the cursor
+		item := d.History.Current()
+		if item == nil {
+			break
+		}
+		err := request(d, "Play track", func() error {
+			return d.Player.Controller.PlayUri(item.Track.ContextUri, d.Spotify.UserTokens.AccessToken)
+		})
+		if err != nil {
+			break
+		}
+		artist := "???"
+		if len(item.Track.Artists) > 0 {
+			artist = item.Track.Artists[0].Name
+		}
+		zero := time.Duration(0)
+		d.Player.PlayingSong.Name = item.Track.Name
+		d.Player.PlayingSong.Artist = artist
+		d.Player.PlayingSong.Uri = item.Track.ContextUri
+		d.Player.PlayingSong.Duration = time.Duration(item.Track.DurationMs * 1000000)
+		d.Player.PlayingSong.Progress = &zero
+	case keymap.HistoryOpenPlaylist:
+		// Jump to the playlist the track was played from
+		item := d.History.Current()
+		if item == nil {
+			break
+		}
+		playlistIndex := findPlaylistByUri(d.Playlist.Items, item.ContextUri)
+		if playlistIndex < 0 {
+			break
+		}
+		err := request(d, "Load playlist", func() error { return selectPlaylist(d, playlistIndex) })
+		if err != nil {
+			break
+		}
+		d.Playlist.MoveToIndex(playlistIndex)
+		for i, t := range d.Songs.Items {
+			if t.ContextUri == item.Track.ContextUri {
+				d.Songs.MoveToIndex(i)
+				d.Songs.Center()
+				break
+			}
+		}
+		d.Mode = &Track{}
+	case keymap.HistoryFetch:
+		// Fetch anything played since the newest item
+		err := request(d, "Fetch history", func() error { return loadNewerHistory(d) })
+		if err != nil {
+			break
+		}
+	default:
+		runGlobalAction(d, action)
+	}
+}
+
+func (*History) ShortDisplay() rune {
+	return 'H'
+}
+
+// Switches to history mode, the first page is fetched when nothing is loaded
+func enterHistory(d *data.AppData) {
+	if len(d.History.Items) == 0 {
+		var resp *spotify.SlimRecentlyPlayed
+		err := request(d, "Fetch history", func() error {
+			var err error
+			resp, err = d.Player.Controller.RecentlyPlayed(d.Spotify.UserTokens.AccessToken, "", "", historyPageSize)
+			return err
+		})
+		if err != nil {
+			return
+		}
+		d.History.List = list.New(toHistoryDetails(resp.Items), d.History.Height)
+		d.History.Before = resp.Before
+		d.History.After = resp.After
+	}
+	d.Mode = &History{}
+}
+
+func loadOlderHistory(d *data.AppData) error {
+	if d.History.Before == "" {
+		return errors.New("loadOlderHistory: no older items")
+	}
+	resp, err := d.Player.Controller.RecentlyPlayed(d.Spotify.UserTokens.AccessToken, d.History.Before, "", historyPageSize)
+	if err != nil {
+		return fmt.Errorf("loadOlderHistory: %w", err)
+	}
+	d.History.Items = append(d.History.Items, toHistoryDetails(resp.Items)...)
+	d.History.Fix()
+	// NOTE: Spotify doesn't return a cursor once the end is reached
+	d.History.Before = resp.Before
+	return nil
+}
+
+func loadNewerHistory(d *data.AppData) error {
+	resp, err := d.Player.Controller.RecentlyPlayed(d.Spotify.UserTokens.AccessToken, "", d.History.After, historyPageSize)
+	if err != nil {
+		return fmt.Errorf("loadNewerHistory: %w", err)
+	}
+	if len(resp.Items) == 0 {
+		return nil
+	}
+	newItems := toHistoryDetails(resp.Items)
+	d.History.Items = append(newItems, d.History.Items...)
+	d.History.After = resp.After
+	// Keep the cursor on the same item
+	if d.History.CursorPosY >= 0 {
+		d.History.CursorPosY += len(newItems)
+		d.History.RowOffset += len(newItems)
+	}
+	d.History.Fix()
+	return nil
+}
+
+func toHistoryDetails(items []spotify.SlimPlayHistory) []data.HistoryDetail {
+	details := []data.HistoryDetail{}
+	for _, item := range items {
+		artists := []data.ArtistDetail{}
+		for _, a := range item.Track.Artist {
+			artists = append(artists, data.ArtistDetail{Name: a.Name})
+		}
+		details = append(details, data.HistoryDetail{
+			ContextType: item.ContextType,
+			ContextUri:  item.ContextUri,
+			PlayedAt:    item.PlayedAt,
+			Track: data.TrackDetail{
+				Name:       item.Track.Name,
+				ContextUri: item.Track.ContextUri,
+				DurationMs: item.Track.DurationMs,
+				Artists:    artists,
+			},
+		})
+	}
+	return details
+}
+
+func findPlaylistByUri(playlists []data.PlaylistDetail, uri string) int {
+	if uri == "" {
+		return -1
+	}
+	for i, p := range playlists {
+		if p.ContextUri == uri {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,77 @@
+package mode
+
+import (
+	"neofy/internal/data"
+	"neofy/internal/keymap"
+	"neofy/internal/terminal"
+)
+
+// Any list.List, motions are the same for every type of item
+type scroller interface {
+	Center()
+	MoveBy(int)
+	MoveTo(int)
+	NextMatch(int)
+	NumRows() int
+	PageRows() int
+	ScrollBy(int)
+	TopRow() int
+}
+
+// Action of the key the app read in mode, "" while it waits for the rest of a binding (gg)
+func readAction(d *data.AppData, mode string) keymap.Action {
+	action, _ := d.Keymap.Feed(&d.Pending, mode, terminal.ReadInputKey())
+	return action
+}
+
+// Action of the key the app read in a list mode, a count (5j, 20G) can be typed before it.
+// Count is 0 when there is none
+func readCountedAction(d *data.AppData, mode string) (keymap.Action, int) {
+	return d.Keymap.Feed(&d.Pending, mode, terminal.ReadInputKey())
+}
+
+// Vim style motions shared by every list mode, returns false when action isn't a motion
+func navigate(l scroller, action keymap.Action, count int, pageHeight int) bool {
+	times := max(count, 1)
+	halfPage := max(pageHeight/2, 1)
+	switch action {
+	case keymap.ListDown:
+		l.MoveBy(times)
+	case keymap.ListUp:
+		l.MoveBy(-times)
+	case keymap.ListHalfPageDown:
+		l.ScrollBy(halfPage * times)
+	case keymap.ListHalfPageUp:
+		l.ScrollBy(-halfPage * times)
+	case keymap.ListPageDown:
+		l.ScrollBy(max(pageHeight, 1) * times)
+	case keymap.ListPageUp:
+		l.ScrollBy(-max(pageHeight, 1) * times)
+	case keymap.ListBottom:
+		if count > 0 {
+			l.MoveTo(count - 1)
+		} else {
+			l.MoveTo(l.NumRows() - 1)
+		}
+	case keymap.ListTop:
+		l.MoveTo(times - 1)
+	case keymap.ListPageTop:
+		// Top of the page
+		l.MoveTo(l.TopRow() + min(times, l.PageRows()) - 1)
+	case keymap.ListPageMiddle:
+		// Middle of the page
+		l.MoveTo(l.TopRow() + (l.PageRows()-1)/2)
+	case keymap.ListPageBottom:
+		// Bottom of the page
+		l.MoveTo(l.TopRow() + l.PageRows() - min(times, l.PageRows()))
+	case keymap.ListCenter:
+		l.Center()
+	case keymap.ListNextMatch:
+		l.NextMatch(times)
+	case keymap.ListPrevMatch:
+		l.NextMatch(-times)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package mode
+
+import (
+	"neofy/internal/data"
+	"neofy/internal/mpris"
+	"neofy/internal/spotify"
+	"time"
+)
+
+// Runs what media keys & desktop widgets asked for over MPRIS, failures are shown like they are for key binds
+func RunMediaCommand(d *data.AppData, c mpris.Command) {
+	switch c.Action {
+	case mpris.Play:
+		err := request(d, "Play", func() error { return resumePlayback(d) })
+		if err != nil {
+			break
+		}
+	case mpris.Pause, mpris.Stop:
+		err := request(d, "Pause", func() error { return pausePlayback(d) })
+		if err != nil {
+			break
+		}
+	case mpris.PlayPause:
+		if d.Player.IsPlaying {
+			RunMediaCommand(d, mpris.Command{Action: mpris.Pause})
+		} else {
+			RunMediaCommand(d, mpris.Command{Action: mpris.Play})
+		}
+	case mpris.Next:
+		err := request(d, "Next track", func() error { return skipTrack(d, true) })
+		if err != nil {
+			break
+		}
+	case mpris.Previous:
+		err := request(d, "Previous track", func() error { return skipTrack(d, false) })
+		if err != nil {
+			break
+		}
+	case mpris.Seek:
+		var progress time.Duration
+		if d.Player.PlayingSong.Progress != nil {
+			progress = *d.Player.PlayingSong.Progress
+		}
+		err := request(d, "Seek", func() error { return seekTo(d, progress+c.Position) })
+		if err != nil {
+			break
+		}
+	case mpris.SetPosition:
+		err := request(d, "Seek", func() error { return seekTo(d, c.Position) })
+		if err != nil {
+			break
+		}
+	case mpris.SetVolume:
+		err := request(d, "Volume", func() error { return setVolume(d, c.Volume) })
+		if err != nil {
+			break
+		}
+	case mpris.SetShuffle:
+		err := request(d, "Shuffle", func() error { return setShuffle(d, c.Shuffle) })
+		if err != nil {
+			break
+		}
+	case mpris.SetRepeat:
+		err := request(d, "Repeat", func() error { return setRepeat(d, c.Repeat) })
+		if err != nil {
+			break
+		}
+	case mpris.OpenUri:
+		err := request(d, "Play "+c.Uri, func() error { return playCommand(d, c.Uri) })
+		if err != nil {
+			break
+		}
+	}
+}
+
+// What the player shows, published over MPRIS
+func MediaStatus(d *data.AppData) spotify.Status {
+	p := d.Player
+	var progress *int
+	if p.PlayingSong.Progress != nil {
+		ms := int(p.PlayingSong.Progress.Milliseconds())
+		progress = &ms
+	}
+	return spotify.NewStatus(&spotify.SlimPlayerData{
+		Artist:         p.PlayingSong.Artist,
+		IsPlaying:      p.IsPlaying,
+		IsShuffled:     p.IsShuffled,
+		Repeat:         p.Repeat,
+		SongDuration:   int(p.PlayingSong.Duration.Milliseconds()),
+		SongName:       p.PlayingSong.Name,
+		SongProgress:   progress,
+		SongUri:        p.PlayingSong.Uri,
+		SupportsVolume: p.SupportsVolume,
+		Volume:         p.Volume,
+	})
+}
@@ -0,0 +1,47 @@
+package mode
+
+import (
+	"neofy/internal/data"
+	"neofy/internal/keymap"
+	"neofy/internal/list"
+)
+
+// Messages that were shown in the status line, oldest first
+type Messages struct{}
+
+func (m *Messages) ProcessInput(d *data.AppData) {
+	action, count := readCountedAction(d, keymap.Messages)
+	m.runAction(d, action, count)
+}
+
+// Runs the action of a key bind, clicks run their actions through here too
+func (*Messages) runAction(d *data.AppData, action keymap.Action, count int) {
+	if navigate(&d.Messages.List, action, count, d.Messages.Height) {
+		return
+	}
+	switch action {
+	case keymap.ListBack:
+		// First back (ESC) removes the filter
+		if d.Messages.IsFiltered() {
+			d.Messages.ApplyFilter("", data.Message.Label)
+			break
+		}
+		d.Mode = &Player{}
+	case keymap.ListFilter:
+		startFilter(d, &d.Messages.List, data.Message.Label)
+	case keymap.MessagesClear:
+		d.Messages.List = list.New([]data.Message{}, d.Messages.Height)
+	default:
+		runGlobalAction(d, action)
+	}
+}
+
+func (*Messages) ShortDisplay() rune {
+	return 'M'
+}
+
+// Switches to the messages with the newest one under the cursor
+func enterMessages(d *data.AppData) {
+	d.Messages.MoveToIndex(len(d.Messages.Items) - 1)
+	d.Mode = &Messages{}
+}
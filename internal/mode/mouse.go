@@ -0,0 +1,137 @@
+package mode
+
+import (
+	"neofy/internal/data"
+	"neofy/internal/keymap"
+	"neofy/internal/list"
+	"neofy/internal/terminal"
+	"time"
+)
+
+// NOTE: Two clicks on the same cell this close together are a double click
+const doubleClickTime = 400 * time.Millisecond
+
+// Rows a turn of the wheel scrolls a list
+const wheelRows = 3
+
+// Modes a click can run actions in
+type actionRunner interface {
+	data.Mode
+	runAction(d *data.AppData, action keymap.Action, count int)
+}
+
+// Runs what a click or a turn of the wheel does where it happened, clicks run the same actions as key binds.
+// A click picks a row & a double click plays or opens it
+func HandleMouse(d *data.AppData, m terminal.MouseEvent) {
+	switch d.Mode.(type) {
+	case *Prompt, *Command:
+		// Clicking away would lose what was typed
+		return
+	case *Help:
+		// The wheel scrolls the help, it's closed with the keyboard
+		switch m.Button {
+		case terminal.MouseWheelUp:
+			scrollHelp(&d.Help, -wheelRows)
+		case terminal.MouseWheelDown:
+			scrollHelp(&d.Help, wheelRows)
+		}
+		return
+	}
+	double := false
+	if m.Button == terminal.MouseLeft {
+		last := d.LastClick
+		double = time.Since(last.At) < doubleClickTime && last.X == m.X && last.Y == m.Y
+		d.LastClick = data.Click{At: time.Now(), X: m.X, Y: m.Y}
+		if double {
+			// A third click starts over
+			d.LastClick = data.Click{}
+		}
+	}
+
+	switch {
+	case inPane(d.Player.Display, m):
+		clickPlayer(d, m)
+	case inPane(d.Playlist.Display, m):
+		// The picker takes the place of playlists while it's the active mode
+		if picker, ok := d.Mode.(*AddToPlaylist); ok {
+			clickList(d, &d.Picker.List, d.Picker.Display, m, double, picker, keymap.PickerAdd)
+			break
+		}
+		clickList(d, &d.Playlist.List, d.Playlist.Display, m, double, &Playlist{}, keymap.PlaylistSelect)
+	case inPane(d.Songs.Display, m):
+		// History & the messages take the place of tracks while they're the active mode
+		switch mode := d.Mode.(type) {
+		case *History:
+			clickList(d, &d.History.List, d.History.Display, m, double, mode, keymap.HistoryPlay)
+			return
+		case *Messages:
+			// NOTE: Messages can't be opened
+			clickList(d, &d.Messages.List, d.Messages.Display, m, double, mode, "")
+			return
+		}
+		clickList(d, &d.Songs.List, d.Songs.Display, m, double, &Track{}, keymap.TrackPlay)
+	}
+}
+
+// The | after a pane counts as part of it
+func inPane(pane data.Display, m terminal.MouseEvent) bool {
+	return m.X >= pane.X && m.X <= pane.X+pane.Width && m.Y >= pane.Y && m.Y < pane.Y+pane.Height
+}
+
+// The wheel scrolls the list, a click switches to the list's mode & moves the cursor to the row
+func clickList[T any](d *data.AppData, l *list.List[T], pane data.Display, m terminal.MouseEvent, double bool, mode actionRunner, open keymap.Action) {
+	switch m.Button {
+	case terminal.MouseWheelUp:
+		l.ScrollBy(-wheelRows)
+		return
+	case terminal.MouseWheelDown:
+		l.ScrollBy(wheelRows)
+		return
+	case terminal.MouseLeft:
+	default:
+		return
+	}
+	d.Mode = mode
+	// NOTE: The header is the first row of the pane
+	row := m.Y - pane.Y - 1
+	rows := l.VisibleRows()
+	if row < 0 || row >= len(rows) {
+		return
+	}
+	l.MoveToIndex(rows[row])
+	if double {
+		mode.runAction(d, open, 0)
+	}
+}
+
+// The wheel changes the volume, clicks press the player's buttons
+func clickPlayer(d *data.AppData, m terminal.MouseEvent) {
+	switch m.Button {
+	case terminal.MouseWheelUp:
+		runGlobalAction(d, keymap.PlayerVolumeUp)
+		return
+	case terminal.MouseWheelDown:
+		runGlobalAction(d, keymap.PlayerVolumeDown)
+		return
+	case terminal.MouseLeft:
+	default:
+		return
+	}
+	x := m.X - d.Player.Display.X
+	y := m.Y - d.Player.Display.Y
+	for _, b := range d.Player.Buttons {
+		if y != b.Y || x < b.X || x >= b.X+b.Width {
+			continue
+		}
+		if b.Action == "" {
+			err := request(d, "Volume", func() error { return setVolume(d, b.Volume) })
+			if err != nil {
+				return
+			}
+			return
+		}
+		runGlobalAction(d, b.Action)
+		return
+	}
+	d.Mode = &Player{}
+}
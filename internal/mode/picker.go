@@ -0,0 +1,60 @@
+package mode
+
+import (
+	"errors"
+	"fmt"
+	"neofy/internal/data"
+	"neofy/internal/keymap"
+)
+
+// Picks a playlist to add d.Picker.Track to, then returns to track mode
+type AddToPlaylist struct{}
+
+func (a *AddToPlaylist) ProcessInput(d *data.AppData) {
+	action, count := readCountedAction(d, keymap.Picker)
+	a.runAction(d, action, count)
+}
+
+// Runs the action of a key bind, clicks run their actions through here too
+func (*AddToPlaylist) runAction(d *data.AppData, action keymap.Action, count int) {
+	if navigate(&d.Picker.List, action, count, d.Picker.Height) {
+		return
+	}
+	switch action {
+	case keymap.PickerCancel:
+		d.Mode = &Track{}
+	case keymap.PickerAdd:
+		playlist := d.Picker.Current()
+		if playlist == nil {
+			break
+		}
+		err := request(d, "Add track", func() error { return addTrackToPlaylist(d, playlist, d.Picker.Track) })
+		if err != nil {
+			break
+		}
+		notify(d, data.Success, "Added "+d.Picker.Track.Name+" to "+playlist.Name)
+		d.Mode = &Track{}
+	default:
+		runGlobalAction(d, action)
+	}
+}
+
+func (*AddToPlaylist) ShortDisplay() rune {
+	return 'A'
+}
+
+// Appends track to playlist
+func addTrackToPlaylist(d *data.AppData, playlist *data.PlaylistDetail, track data.TrackDetail) error {
+	if playlist == nil {
+		return errors.New("addTrackToPlaylist: no playlist picked")
+	}
+	snapshotId, err := d.Player.Controller.AddTracksToPlaylist(playlist.Href, d.Spotify.UserTokens.AccessToken, []string{track.ContextUri}, -1)
+	if err != nil {
+		return fmt.Errorf("addTrackToPlaylist: %w", err)
+	}
+	setPlaylistSnapshot(d, playlist.Href, snapshotId, 1)
+	if selected := d.Playlist.SelectedItem(); selected != nil && selected.Href == playlist.Href {
+		d.Songs.SetItems(append(d.Songs.Items, track), data.TrackDetail.Label)
+	}
+	return nil
+}
@@ -3,72 +3,64 @@ package mode
 import (
 	"errors"
 	"fmt"
-	"neofy/internal/consts"
 	"neofy/internal/data"
-	"neofy/internal/terminal"
+	"neofy/internal/keymap"
 	"time"
 )
 
 type Player struct{}
 
 func (*Player) ProcessInput(d *data.AppData) {
-	keyReadRune := terminal.ReadInputKey()
-	switch keyReadRune {
-	case consts.CONTROLCASCII:
-		terminal.Quit(d.Term)
-		break
-	case 'u', 'U':
+	runGlobalAction(d, readAction(d, keymap.Player))
+}
+
+// Runs the app, mode & player actions that can be bound in any mode, returns false for other actions
+func runGlobalAction(d *data.AppData, action keymap.Action) bool {
+	switch action {
+	case keymap.AppQuit:
+		d.Quitting = true
+	case keymap.AppCommand:
+		startCommand(d)
+	case keymap.AppHelp:
+		startHelp(d)
+	case keymap.AppPanic:
+		panic("Wicho: Panic")
+	case keymap.ModePlayer:
+		d.Mode = &Player{}
+	case keymap.ModePlaylists:
 		d.Mode = &Playlist{}
-	case 't', 'T':
+	case keymap.ModeTracks:
 		d.Mode = &Track{}
-	case 's', 'S':
-		// Shuffle:
-		err := d.Player.Controller.ShuffleMode(d.Spotify.UserTokens.AccessToken, !d.Player.IsShuffled)
-		if err != nil {
-			break
-		}
-		d.Player.IsShuffled = !d.Player.IsShuffled
-	case 'b', 'B':
-		// Previous Song
-		err := d.Player.Controller.SkipToPrevious(d.Spotify.UserTokens.AccessToken)
-		if err != nil {
-			break
-		}
-		err = refreshPlayer(d.Spotify.UserTokens.AccessToken, &d.Player)
+	case keymap.ModeHistory:
+		enterHistory(d)
+	case keymap.ModeMessages:
+		enterMessages(d)
+	case keymap.PlayerShuffle:
+		err := request(d, "Shuffle", func() error { return setShuffle(d, !d.Player.IsShuffled) })
 		if err != nil {
 			break
 		}
-	case 'p', 'P':
-		// Play song
-		if d.Player.IsPlaying {
-			break
-		}
-		err := d.Player.Controller.StartResumePlayback(d.Spotify.UserTokens.AccessToken)
+	case keymap.PlayerPrevious:
+		err := request(d, "Previous track", func() error { return skipTrack(d, false) })
 		if err != nil {
 			break
 		}
-		d.Player.IsPlaying = true
-	case 'x', 'X':
-		// Pause Song
-		if !d.Player.IsPlaying {
-			break
-		}
-		err := d.Player.Controller.PausePlayback(d.Spotify.UserTokens.AccessToken)
+	case keymap.PlayerPlay:
+		err := request(d, "Play", func() error { return resumePlayback(d) })
 		if err != nil {
 			break
 		}
-		d.Player.IsPlaying = false
-	case 'n', 'N':
-		// Skip Song
-		err := d.Player.Controller.SkipToNext(d.Spotify.UserTokens.AccessToken)
+	case keymap.PlayerPause:
+		err := request(d, "Pause", func() error { return pausePlayback(d) })
 		if err != nil {
 			break
 		}
-		err = refreshPlayer(d.Spotify.UserTokens.AccessToken, &d.Player)
+	case keymap.PlayerNext:
+		err := request(d, "Next track", func() error { return skipTrack(d, true) })
 		if err != nil {
 			break
 		}
-	case 'r', 'R':
+	case keymap.PlayerRepeat:
 		// Start Loop:
 		nextLoop := "off"
 		switch d.Player.Repeat {
@@ -81,58 +73,57 @@ func (*Player) ProcessInput(d *data.AppData) {
 		default:
 			break
 		}
-		err := d.Player.Controller.RepeatMode(d.Spotify.UserTokens.AccessToken, nextLoop)
+		err := request(d, "Repeat", func() error { return setRepeat(d, nextLoop) })
 		if err != nil {
 			break
 		}
-		d.Player.Repeat = nextLoop
-	case '-':
+	case keymap.PlayerVolumeDown:
 		// Decrease Volume if enabled
-		if !d.Player.SupportsVolume {
-			break
-		}
-		newVol := d.Player.Volume - 10
-		if newVol > 100 {
-			newVol = 100
-		} else if newVol < 0 {
-			newVol = 0
-		}
-		err := d.Player.Controller.SetPlaybackVolume(d.Spotify.UserTokens.AccessToken, newVol)
+		err := request(d, "Volume", func() error { return setVolume(d, d.Player.Volume-d.Settings.VolumeStep) })
 		if err != nil {
 			break
 		}
-		d.Player.Volume = newVol
-	case '+', '=':
+	case keymap.PlayerVolumeUp:
 		// Increase Volume if enabled
-		if !d.Player.SupportsVolume {
-			break
-		}
-		newVol := d.Player.Volume + 10
-		if newVol > 100 {
-			newVol = 100
-		} else if newVol < 0 {
-			newVol = 0
-		}
-		err := d.Player.Controller.SetPlaybackVolume(d.Spotify.UserTokens.AccessToken, newVol)
+		err := request(d, "Volume", func() error { return setVolume(d, d.Player.Volume+d.Settings.VolumeStep) })
 		if err != nil {
 			break
 		}
-		d.Player.Volume = newVol
-	case 'f', 'F':
+	case keymap.PlayerRefresh:
 		// Refresh the current song
-		err := refreshPlayer(d.Spotify.UserTokens.AccessToken, &d.Player)
+		err := request(d, "Refresh", func() error { return refreshPlayer(d.Spotify.UserTokens.AccessToken, &d.Player) })
 		if err != nil {
 			break
 		}
-	case 'w', 'W':
-		panic("Wicho: Panic")
+	default:
+		return false
 	}
+	return true
 }
 
 func (*Player) ShortDisplay() rune {
 	return 'P'
 }
 
+// Mode the app starts in by it's name in the config: player, playlists, tracks or history
+func Startup(d *data.AppData, name string) {
+	switch name {
+	case "playlists":
+		d.Mode = &Playlist{}
+	case "tracks":
+		d.Mode = &Track{}
+	case "history":
+		enterHistory(d)
+	default:
+		d.Mode = &Player{}
+	}
+}
+
+// Syncs the player with what spotify is playing, used when polling
+func RefreshPlayer(d *data.AppData) error {
+	return refreshPlayer(d.Spotify.UserTokens.AccessToken, &d.Player)
+}
+
 func refreshPlayer(accessToken string, mp *data.MusicPlayer) error {
 	if mp == nil {
 		return errors.New("refreshPlayer: mp is nil")
@@ -145,6 +136,7 @@ func refreshPlayer(accessToken string, mp *data.MusicPlayer) error {
 	mp.IsShuffled = player.IsShuffled
 	mp.PlayingSong.Name = player.SongName
 	mp.PlayingSong.Artist = player.Artist
+	mp.PlayingSong.Uri = player.SongUri
 	mp.Repeat = player.Repeat
 	if player.SongProgress != nil {
 		p := time.Duration(*player.SongProgress * 1000000)
@@ -155,3 +147,91 @@ func refreshPlayer(accessToken string, mp *data.MusicPlayer) error {
 	mp.PlayingSong.Duration = time.Duration(player.SongDuration * 1000000)
 	return nil
 }
+
+// Player actions, shared by the key binds & the command line
+
+func resumePlayback(d *data.AppData) error {
+	if d.Player.IsPlaying {
+		return nil
+	}
+	err := d.Player.Controller.StartResumePlayback(d.Spotify.UserTokens.AccessToken)
+	if err != nil {
+		return fmt.Errorf("resumePlayback: %w", err)
+	}
+	d.Player.IsPlaying = true
+	return nil
+}
+
+func pausePlayback(d *data.AppData) error {
+	if !d.Player.IsPlaying {
+		return nil
+	}
+	err := d.Player.Controller.PausePlayback(d.Spotify.UserTokens.AccessToken)
+	if err != nil {
+		return fmt.Errorf("pausePlayback: %w", err)
+	}
+	d.Player.IsPlaying = false
+	return nil
+}
+
+// Skips to the next track, or the previous one when next is false
+func skipTrack(d *data.AppData, next bool) error {
+	var err error
+	if next {
+		err = d.Player.Controller.SkipToNext(d.Spotify.UserTokens.AccessToken)
+	} else {
+		err = d.Player.Controller.SkipToPrevious(d.Spotify.UserTokens.AccessToken)
+	}
+	if err != nil {
+		return fmt.Errorf("skipTrack: %w", err)
+	}
+	err = refreshPlayer(d.Spotify.UserTokens.AccessToken, &d.Player)
+	if err != nil {
+		return fmt.Errorf("skipTrack: %w", err)
+	}
+	return nil
+}
+
+func setShuffle(d *data.AppData, shuffle bool) error {
+	err := d.Player.Controller.ShuffleMode(d.Spotify.UserTokens.AccessToken, shuffle)
+	if err != nil {
+		return fmt.Errorf("setShuffle: %w", err)
+	}
+	d.Player.IsShuffled = shuffle
+	return nil
+}
+
+// mode is one of off, context or track
+func setRepeat(d *data.AppData, mode string) error {
+	err := d.Player.Controller.RepeatMode(d.Spotify.UserTokens.AccessToken, mode)
+	if err != nil {
+		return fmt.Errorf("setRepeat: %w", err)
+	}
+	d.Player.Repeat = mode
+	return nil
+}
+
+// Volume is clamped to 0-100
+func setVolume(d *data.AppData, volume int) error {
+	if !d.Player.SupportsVolume {
+		return errors.New("setVolume: the device doesn't support volume")
+	}
+	volume = min(max(volume, 0), 100)
+	err := d.Player.Controller.SetPlaybackVolume(d.Spotify.UserTokens.AccessToken, volume)
+	if err != nil {
+		return fmt.Errorf("setVolume: %w", err)
+	}
+	d.Player.Volume = volume
+	return nil
+}
+
+// Seeks to position in the playing track
+func seekTo(d *data.AppData, position time.Duration) error {
+	position = min(max(position, 0), d.Player.PlayingSong.Duration)
+	err := d.Player.Controller.SeekToPosition(d.Spotify.UserTokens.AccessToken, int(position.Milliseconds()))
+	if err != nil {
+		return fmt.Errorf("seekTo: %w", err)
+	}
+	d.Player.PlayingSong.Progress = &position
+	return nil
+}
@@ -1,90 +1,239 @@
 package mode
 
 import (
-	"neofy/internal/consts"
+	"errors"
+	"fmt"
 	"neofy/internal/data"
-	"neofy/internal/terminal"
+	"neofy/internal/keymap"
+	"neofy/internal/list"
+	"neofy/internal/spotify"
 )
 
 type Playlist struct{}
 
-func (*Playlist) ProcessInput(d *data.AppData) {
-	keyReadRune := terminal.ReadInputKey()
-	switch keyReadRune {
-	case consts.CONTROLCASCII, consts.ESC:
-		d.Mode = &Player{}
-		break
-	case consts.CONTROL_U:
-		skipBy := 10
-		if d.Playlist.CursorPosY < 0 {
-			break
-		} else if d.Playlist.CursorPosY-skipBy < 0 {
+func (p *Playlist) ProcessInput(d *data.AppData) {
+	action, count := readCountedAction(d, keymap.Playlist)
+	p.runAction(d, action, count)
+}
+
+// Runs the action of a key bind, clicks run their actions through here too
+func (*Playlist) runAction(d *data.AppData, action keymap.Action, count int) {
+	if navigate(&d.Playlist.List, action, count, d.Playlist.Height) {
+		return
+	}
+	switch action {
+	case keymap.ListBack:
+		// First back (ESC) removes the filter
+		if d.Playlist.IsFiltered() {
+			d.Playlist.ApplyFilter("", data.PlaylistDetail.Label)
 			break
 		}
-		if d.Playlist.CursorPosY-skipBy <= d.Playlist.RowOffset {
-			d.Playlist.RowOffset -= skipBy
-		}
-		d.Playlist.CursorPosY -= skipBy
-	case consts.CONTROL_D:
-		skipBy := 10
+		d.Mode = &Player{}
+	case keymap.ListFilter:
+		startFilter(d, &d.Playlist.List, data.PlaylistDetail.Label)
+	case keymap.PlaylistSelect:
 		if d.Playlist.CursorPosY < 0 {
 			break
-		} else if d.Playlist.CursorPosY+skipBy >= len(d.Playlist.Playlists) {
-			break
 		}
-		if d.Playlist.CursorPosY+skipBy >= len(d.Playlist.Display.Screen)-3+d.Playlist.RowOffset {
-			d.Playlist.RowOffset += skipBy
-		}
-		d.Playlist.CursorPosY += skipBy
-	case 't', 'T':
-		d.Mode = &Track{}
-	case 'j', 'J':
-		if d.Playlist.CursorPosY < 0 {
-			break
-		} else if d.Playlist.CursorPosY+1 >= len(d.Playlist.Playlists) {
+		err := request(d, "Load playlist", func() error { return selectPlaylist(d, d.Playlist.CursorPosY) })
+		if err != nil {
 			break
 		}
-		if d.Playlist.CursorPosY >= len(d.Playlist.Display.Screen)-3+d.Playlist.RowOffset {
-			d.Playlist.RowOffset++
+	case keymap.PlaylistCreate:
+		startPrompt(d, "New playlist name: ", "", createPlaylist)
+	case keymap.PlaylistRename:
+		p := d.Playlist.Current()
+		if p == nil {
+			break
 		}
-		d.Playlist.CursorPosY++
-	case 'k', 'K':
-		if d.Playlist.CursorPosY < 0 {
+		startPrompt(d, "Rename playlist: ", p.Name, func(d *data.AppData, name string) {
+			if name == "" {
+				return
+			}
+			changePlaylist(d, p.Id, spotify.PlaylistChanges{Name: &name}, "Renamed "+p.Name+" to "+name)
+		})
+	case keymap.PlaylistDescribe:
+		p := d.Playlist.Current()
+		if p == nil {
 			break
-		} else if d.Playlist.CursorPosY-1 < 0 {
+		}
+		startPrompt(d, "Playlist description: ", p.Description, func(d *data.AppData, description string) {
+			changePlaylist(d, p.Id, spotify.PlaylistChanges{Description: &description}, "Changed the description of "+p.Name)
+		})
+	case keymap.PlaylistTogglePublic:
+		p := d.Playlist.Current()
+		if p == nil {
 			break
 		}
-		if d.Playlist.CursorPosY <= d.Playlist.RowOffset {
-			d.Playlist.RowOffset--
+		public := !p.Public
+		changes := spotify.PlaylistChanges{Public: &public}
+		if public && p.Collaborative {
+			// NOTE: Spotify only allows collaborative playlists to be private
+			collaborative := false
+			changes.Collaborative = &collaborative
 		}
-		d.Playlist.CursorPosY--
-	case 's', 'S':
-		if d.Playlist.CursorPosY < 0 {
-			break
+		visibility := "private"
+		if public {
+			visibility = "public"
 		}
-		if d.Playlist.SelectedPlaylist == nil {
+		changePlaylist(d, p.Id, changes, p.Name+" is now "+visibility)
+	case keymap.PlaylistToggleCollaborative:
+		p := d.Playlist.Current()
+		if p == nil {
 			break
 		}
-		curPlaylist := d.Playlist.Playlists[d.Playlist.CursorPosY]
-		tracksResp, err := d.Player.Controller.GetTracksFromPlaylist(curPlaylist.Href, d.Spotify.UserTokens.AccessToken, curPlaylist.NumSongs)
-		if err != nil {
+		collaborative := !p.Collaborative
+		changes := spotify.PlaylistChanges{Collaborative: &collaborative}
+		if collaborative && p.Public {
+			public := false
+			changes.Public = &public
+		}
+		done := p.Name + " is no longer collaborative"
+		if collaborative {
+			done = p.Name + " is now collaborative"
+		}
+		changePlaylist(d, p.Id, changes, done)
+	case keymap.PlaylistDelete:
+		// Needs to be confirmed
+		p := d.Playlist.Current()
+		if p == nil {
 			break
 		}
-		newTracks := []data.TrackDetail{}
-		for _, track := range tracksResp {
-			artists := []data.ArtistDetail{}
-			for _, a := range track.Artist {
-				artists = append(artists, data.ArtistDetail{Name: a.Name})
+		startPrompt(d, "Delete playlist "+p.Name+"? (y/n): ", "", func(d *data.AppData, answer string) {
+			if answer != "y" && answer != "Y" {
+				return
 			}
-			newTracks = append(newTracks, data.TrackDetail{Name: track.Name, ContextUri: track.ContextUri, DurationMs: track.DurationMs, Artists: artists})
-		}
-		d.Playlist.SelectedPlaylist = &curPlaylist
-		d.Songs.Tracks = newTracks
-		d.Songs.CursorPosY = 0
-		d.Songs.SelectedTrack = nil
+			err := request(d, "Delete playlist", func() error {
+				err := d.Player.Controller.UnfollowPlaylist(p.Id, d.Spotify.UserTokens.AccessToken)
+				if err != nil {
+					return err
+				}
+				return refreshPlaylists(d)
+			})
+			if err != nil {
+				return
+			}
+			notify(d, data.Success, "Deleted "+p.Name)
+		})
+	default:
+		runGlobalAction(d, action)
 	}
 }
 
 func (*Playlist) ShortDisplay() rune {
 	return 'U'
 }
+
+// Loads the tracks of the playlist at index & marks it as selected
+func selectPlaylist(d *data.AppData, index int) error {
+	if index < 0 || index >= len(d.Playlist.Items) {
+		return errors.New("selectPlaylist: index out of range")
+	}
+	curPlaylist := d.Playlist.Items[index]
+	tracksResp, err := d.Player.Controller.GetTracksFromPlaylist(curPlaylist.Href, d.Spotify.UserTokens.AccessToken, curPlaylist.NumSongs)
+	if err != nil {
+		return fmt.Errorf("selectPlaylist: %w", err)
+	}
+	newTracks := []data.TrackDetail{}
+	for _, track := range tracksResp {
+		artists := []data.ArtistDetail{}
+		for _, a := range track.Artist {
+			artists = append(artists, data.ArtistDetail{Name: a.Name})
+		}
+		newTracks = append(newTracks, data.TrackDetail{Name: track.Name, ContextUri: track.ContextUri, DurationMs: track.DurationMs, Artists: artists})
+	}
+	d.Playlist.Selected = index
+	clearTracks(d, newTracks)
+	return nil
+}
+
+// Replaces the loaded tracks, the cursor goes back to the top
+func clearTracks(d *data.AppData, tracks []data.TrackDetail) {
+	d.Songs.List = list.New(tracks, d.Songs.Height)
+}
+
+func createPlaylist(d *data.AppData, name string) {
+	if name == "" {
+		return
+	}
+	var newPlaylist *spotify.SlimPlaylistData
+	err := request(d, "Create playlist", func() error {
+		userId, err := d.Player.Controller.CurrentUserId(d.Spotify.UserTokens.AccessToken)
+		if err != nil {
+			return err
+		}
+		public := false
+		newPlaylist, err = d.Player.Controller.CreatePlaylist(userId, d.Spotify.UserTokens.AccessToken, spotify.PlaylistChanges{Name: &name, Public: &public})
+		if err != nil {
+			return err
+		}
+		return refreshPlaylists(d)
+	})
+	if err != nil {
+		return
+	}
+	notify(d, data.Success, "Created "+name)
+	// The new playlist could be hidden by the filter
+	d.Playlist.ApplyFilter("", data.PlaylistDetail.Label)
+	for i, p := range d.Playlist.Items {
+		if p.Id == newPlaylist.Id {
+			d.Playlist.MoveToIndex(i)
+		}
+	}
+}
+
+// done is shown once the playlist is changed
+func changePlaylist(d *data.AppData, playlistId string, changes spotify.PlaylistChanges, done string) {
+	err := request(d, "Change playlist", func() error {
+		err := d.Player.Controller.ChangePlaylistDetails(playlistId, d.Spotify.UserTokens.AccessToken, changes)
+		if err != nil {
+			return err
+		}
+		return refreshPlaylists(d)
+	})
+	if err != nil {
+		return
+	}
+	notify(d, data.Success, done)
+}
+
+// Fetches the user's playlists again, keeping the selected playlist & cursor when possible
+func refreshPlaylists(d *data.AppData) error {
+	userPlaylists, err := d.Player.Controller.GetUserPlaylists(d.Spotify.UserTokens.AccessToken)
+	if err != nil {
+		return fmt.Errorf("refreshPlaylists: %w", err)
+	}
+	playlists := []data.PlaylistDetail{}
+	for _, p := range userPlaylists {
+		newP := data.PlaylistDetail{
+			Collaborative: p.Collaborative,
+			ContextUri:    p.ContextUri,
+			Description:   p.Description,
+			Href:          p.TracksHref,
+			Id:            p.Id,
+			Name:          p.Name,
+			NumSongs:      p.TotalTracks,
+			Public:        p.Public,
+			SnapshotId:    p.SnapshotId,
+		}
+		playlists = append(playlists, newP)
+	}
+	selectedId := ""
+	if p := d.Playlist.SelectedItem(); p != nil {
+		selectedId = p.Id
+	}
+	d.Playlist.Selected = -1
+	d.Playlist.SetItems(playlists, data.PlaylistDetail.Label)
+	if selectedId == "" {
+		return nil
+	}
+	for i, p := range playlists {
+		if p.Id == selectedId {
+			d.Playlist.Selected = i
+			return nil
+		}
+	}
+	// The selected playlist was removed so it's tracks can't be played
+	clearTracks(d, []data.TrackDetail{})
+	return nil
+}
@@ -0,0 +1,91 @@
+package mode
+
+import (
+	"neofy/internal/consts"
+	"neofy/internal/data"
+	"neofy/internal/terminal"
+	"unicode"
+)
+
+// Reads a line of text into d.Prompt, once submitted it's handed to OnSubmit
+type Prompt struct {
+	OnCancel func(*data.AppData)         // Optional
+	OnChange func(*data.AppData, string) // Optional, called after every edit
+	OnSubmit func(*data.AppData, string)
+	Previous data.Mode // Mode to return to once done
+}
+
+func (p *Prompt) ProcessInput(d *data.AppData) {
+	key := terminal.ReadKeyEvent()
+	switch key.Rune() {
+	case consts.CONTROLCASCII, consts.ESC:
+		d.Mode = p.Previous
+		d.Prompt = data.Prompt{}
+		if p.OnCancel != nil {
+			p.OnCancel(d)
+		}
+	case '\r':
+		input := string(d.Prompt.Input)
+		d.Mode = p.Previous
+		d.Prompt = data.Prompt{}
+		p.OnSubmit(d, input)
+	default:
+		if editPrompt(d, key) {
+			p.changed(d)
+		}
+	}
+}
+
+// Types, pastes or deletes a rune of d.Prompt, returns false when the key isn't an edit
+func editPrompt(d *data.AppData, key terminal.KeyEvent) bool {
+	r := key.Rune()
+	switch {
+	case r == consts.BACKSPACE:
+		if len(d.Prompt.Input) == 0 {
+			return false
+		}
+		d.Prompt.Input = d.Prompt.Input[:len(d.Prompt.Input)-1]
+		return true
+	case r == consts.PASTE:
+		// NOTE: The prompt is a single line, line breaks & tabs become spaces
+		pasted := false
+		for _, p := range key.Paste {
+			if unicode.IsSpace(p) {
+				p = ' '
+			}
+			if unicode.IsPrint(p) {
+				d.Prompt.Input = append(d.Prompt.Input, p)
+				pasted = true
+			}
+		}
+		return pasted
+	case consts.IsKeyCode(r) || !unicode.IsPrint(r):
+		return false
+	}
+	d.Prompt.Input = append(d.Prompt.Input, r)
+	return true
+}
+
+func (p *Prompt) changed(d *data.AppData) {
+	if p.OnChange != nil {
+		p.OnChange(d, string(d.Prompt.Input))
+	}
+}
+
+func (*Prompt) ShortDisplay() rune {
+	return 'I'
+}
+
+// Switches to a prompt that starts with initial as it's input
+func startPrompt(d *data.AppData, label, initial string, onSubmit func(*data.AppData, string)) *Prompt {
+	d.Prompt = data.Prompt{
+		Input: []rune(initial),
+		Label: label,
+	}
+	p := &Prompt{
+		OnSubmit: onSubmit,
+		Previous: d.Mode,
+	}
+	d.Mode = p
+	return p
+}
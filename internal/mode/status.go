@@ -0,0 +1,76 @@
+package mode
+
+import (
+	"log/slog"
+	"neofy/internal/data"
+	"neofy/internal/spotify"
+	"time"
+)
+
+// How long a message stays in the status line
+const messageTimeout = 5 * time.Second
+
+// Max number of messages kept, the oldest are dropped
+const messageLimit = 200
+
+// Runs a request to spotify, the status line shows a spinner with label until it's done.
+// A failed request is shown as an error with the reason it failed
+func request(d *data.AppData, label string, run func() error) error {
+	if d.Spinner != nil {
+		d.Spinner.Start(label)
+	}
+	err := run()
+	if d.Spinner != nil {
+		d.Spinner.Stop()
+	}
+	if err != nil {
+		slog.Error("request failed", "request", label, "err", err)
+		notify(d, data.Error, label+" failed: "+spotify.ErrorReason(err))
+	}
+	return err
+}
+
+// Shows text in the status line & keeps it in the messages, a repeat of the newest message is counted instead
+func notify(d *data.AppData, level data.Level, text string) {
+	slog.Debug("message", "level", level, "text", text)
+	m := &d.Messages
+	if n := len(m.Items); n > 0 && m.Items[n-1].Text == text && m.Items[n-1].Level == level {
+		m.Items[n-1].At = time.Now()
+		m.Items[n-1].Count++
+		return
+	}
+	// NOTE: The cursor follows new messages unless it was moved up
+	follow := m.CursorPosY < 0 || m.CursorPosY == len(m.Items)-1
+	items := append(m.Items, data.Message{At: time.Now(), Count: 1, Level: level, Text: text})
+	if dropped := len(items) - messageLimit; dropped > 0 {
+		items = items[dropped:]
+		m.CursorPosY = max(m.CursorPosY-dropped, 0)
+	}
+	m.SetItems(items, data.Message.Label)
+	if follow {
+		m.MoveToIndex(len(items) - 1)
+	}
+}
+
+// Shows text like the messages of key binds, for what happens outside of a mode (config reloads)
+func Notify(d *data.AppData, level data.Level, text string) {
+	notify(d, level, text)
+}
+
+// Newest message while it's shown in the status line, nil once it timed out
+func StatusMessage(d *data.AppData) *data.Message {
+	n := len(d.Messages.Items)
+	if n == 0 || time.Since(d.Messages.Items[n-1].At) >= messageTimeout {
+		return nil
+	}
+	return &d.Messages.Items[n-1]
+}
+
+// Fires once the message in the status line times out so it can be cleared, nil when there is none
+func StatusTimeout(d *data.AppData) <-chan time.Time {
+	m := StatusMessage(d)
+	if m == nil {
+		return nil
+	}
+	return time.After(time.Until(m.At.Add(messageTimeout)))
+}
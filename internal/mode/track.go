@@ -1,70 +1,47 @@
 package mode
 
 import (
-	"neofy/internal/consts"
+	"errors"
+	"fmt"
 	"neofy/internal/data"
-	"neofy/internal/terminal"
+	"neofy/internal/keymap"
+	"neofy/internal/list"
+	"slices"
 	"time"
 )
 
 type Track struct{}
 
-func (*Track) ProcessInput(d *data.AppData) {
-	keyReadRune := terminal.ReadInputKey()
-	switch keyReadRune {
-	case consts.CONTROLCASCII, consts.ESC:
-		d.Mode = &Player{}
-		break
-	case consts.CONTROL_U:
-		skipBy := 10
-		if d.Songs.CursorPosY < 0 {
-			break
-		} else if d.Songs.CursorPosY-skipBy < 0 {
-			break
-		}
-		if d.Songs.CursorPosY-skipBy <= d.Songs.RowOffset {
-			d.Songs.RowOffset -= skipBy
-		}
-		d.Songs.CursorPosY -= skipBy
-	case consts.CONTROL_D:
-		skipBy := 10
-		if d.Songs.CursorPosY < 0 {
-			break
-		} else if d.Songs.CursorPosY+skipBy >= len(d.Songs.Tracks) {
-			break
-		}
-		if d.Songs.CursorPosY+skipBy >= len(d.Songs.Display.Screen)-3+d.Songs.RowOffset {
-			d.Songs.RowOffset += skipBy
-		}
-		d.Songs.CursorPosY += skipBy
-	case 'u', 'U':
-		d.Mode = &Playlist{}
-	case 'j', 'J':
-		if d.Songs.CursorPosY < 0 {
-			break
-		} else if d.Songs.CursorPosY+1 >= len(d.Songs.Tracks) {
-			break
-		}
-		if d.Songs.CursorPosY >= len(d.Songs.Display.Screen)-3+d.Songs.RowOffset {
-			d.Songs.RowOffset++
-		}
-		d.Songs.CursorPosY++
-	case 'k', 'K':
-		if d.Songs.CursorPosY < 0 {
-			break
-		} else if d.Songs.CursorPosY-1 < 0 {
+func (t *Track) ProcessInput(d *data.AppData) {
+	action, count := readCountedAction(d, keymap.Track)
+	t.runAction(d, action, count)
+}
+
+// Runs the action of a key bind, clicks run their actions through here too
+func (*Track) runAction(d *data.AppData, action keymap.Action, count int) {
+	if navigate(&d.Songs.List, action, count, d.Songs.Height) {
+		return
+	}
+	switch action {
+	case keymap.ListBack:
+		// First back (ESC) removes the filter
+		if d.Songs.IsFiltered() {
+			d.Songs.ApplyFilter("", data.TrackDetail.Label)
 			break
 		}
-		if d.Songs.CursorPosY <= d.Songs.RowOffset {
-			d.Songs.RowOffset--
-		}
-		d.Songs.CursorPosY--
-	case 's', 'S':
-		if d.Songs.CursorPosY < 0 {
+		d.Mode = &Player{}
+	case keymap.ListFilter:
+		startFilter(d, &d.Songs.List, data.TrackDetail.Label)
+	case keymap.TrackPlay:
+		track := d.Songs.Current()
+		playlist := d.Playlist.SelectedItem()
+		if track == nil || playlist == nil {
 			break
 		}
-		newTrack := d.Songs.Tracks[d.Songs.CursorPosY]
-		err := d.Player.Controller.StartTrack(d.Playlist.SelectedPlaylist.ContextUri, d.Spotify.UserTokens.AccessToken, d.Songs.CursorPosY)
+		newTrack := *track
+		err := request(d, "Play track", func() error {
+			return d.Player.Controller.StartTrack(playlist.ContextUri, d.Spotify.UserTokens.AccessToken, d.Songs.CursorPosY)
+		})
 		if err != nil {
 			break
 		}
@@ -73,14 +50,128 @@ func (*Track) ProcessInput(d *data.AppData) {
 			artist = newTrack.Artists[0].Name
 		}
 		zero := time.Duration(0)
-		d.Songs.SelectedTrack = &newTrack
+		d.Songs.Selected = d.Songs.CursorPosY
 		d.Player.PlayingSong.Name = newTrack.Name
 		d.Player.PlayingSong.Artist = artist
+		d.Player.PlayingSong.Uri = newTrack.ContextUri
 		d.Player.PlayingSong.Duration = time.Duration(newTrack.DurationMs * 1000000)
 		d.Player.PlayingSong.Progress = &zero
+	case keymap.TrackMoveDown:
+		err := request(d, "Move track", func() error { return moveTrack(d, d.Songs.CursorPosY, d.Songs.CursorPosY+1) })
+		if err != nil {
+			break
+		}
+		d.Songs.MoveBy(1)
+	case keymap.TrackMoveUp:
+		err := request(d, "Move track", func() error { return moveTrack(d, d.Songs.CursorPosY, d.Songs.CursorPosY-1) })
+		if err != nil {
+			break
+		}
+		d.Songs.MoveBy(-1)
+	case keymap.TrackRemove:
+		track := d.Songs.Current()
+		playlist := d.Playlist.SelectedItem()
+		if track == nil || playlist == nil {
+			break
+		}
+		done := "Removed " + track.Name + " from " + playlist.Name
+		err := request(d, "Remove track", func() error { return removeTrack(d, d.Songs.CursorPosY) })
+		if err != nil {
+			break
+		}
+		notify(d, data.Success, done)
+	case keymap.TrackAddToPlaylist:
+		// Add the playing song to a playlist
+		if d.Player.PlayingSong.Uri == "" {
+			break
+		}
+		d.Picker.Track = data.TrackDetail{
+			Name:       d.Player.PlayingSong.Name,
+			ContextUri: d.Player.PlayingSong.Uri,
+			DurationMs: int(d.Player.PlayingSong.Duration.Milliseconds()),
+			Artists:    []data.ArtistDetail{{Name: d.Player.PlayingSong.Artist}},
+		}
+		d.Picker.List = list.New(slices.Clone(d.Playlist.Items), d.Picker.Height)
+		d.Mode = &AddToPlaylist{}
+	default:
+		runGlobalAction(d, action)
 	}
 }
 
 func (*Track) ShortDisplay() rune {
 	return 'T'
 }
+
+// Moves the track at index from to index to in the selected playlist
+func moveTrack(d *data.AppData, from, to int) error {
+	playlist := d.Playlist.SelectedItem()
+	if playlist == nil {
+		return errors.New("moveTrack: no playlist selected")
+	}
+	if from < 0 || from >= len(d.Songs.Items) || to < 0 || to >= len(d.Songs.Items) {
+		return errors.New("moveTrack: index out of range")
+	}
+	// NOTE: Rows that are filtered out would make the move look like a jump
+	if d.Songs.IsNarrowed() {
+		return errors.New("moveTrack: can't move tracks while filtering")
+	}
+	// Spotify inserts the track before insertBefore, which is after the track when moving down
+	insertBefore := to
+	if to > from {
+		insertBefore = to + 1
+	}
+	snapshotId, err := d.Player.Controller.ReorderPlaylistTracks(playlist.Href, d.Spotify.UserTokens.AccessToken, playlist.SnapshotId, from, insertBefore, 1)
+	if err != nil {
+		return fmt.Errorf("moveTrack: %w", err)
+	}
+	setPlaylistSnapshot(d, playlist.Href, snapshotId, 0)
+	track := d.Songs.Items[from]
+	d.Songs.Items = slices.Delete(d.Songs.Items, from, from+1)
+	d.Songs.Items = slices.Insert(d.Songs.Items, to, track)
+	// The playing track moves with it
+	switch d.Songs.Selected {
+	case from:
+		d.Songs.Selected = to
+	case to:
+		d.Songs.Selected = from
+	}
+	return nil
+}
+
+// Removes the track at index from the selected playlist
+func removeTrack(d *data.AppData, index int) error {
+	playlist := d.Playlist.SelectedItem()
+	if playlist == nil {
+		return errors.New("removeTrack: no playlist selected")
+	}
+	if index < 0 || index >= len(d.Songs.Items) {
+		return errors.New("removeTrack: index out of range")
+	}
+	uri := d.Songs.Items[index].ContextUri
+	snapshotId, err := d.Player.Controller.RemoveTracksFromPlaylist(playlist.Href, d.Spotify.UserTokens.AccessToken, playlist.SnapshotId, []string{uri})
+	if err != nil {
+		return fmt.Errorf("removeTrack: %w", err)
+	}
+	// NOTE: Spotify removes every occurrence of the uri
+	tracks := []data.TrackDetail{}
+	for _, t := range d.Songs.Items {
+		if t.ContextUri != uri {
+			tracks = append(tracks, t)
+		}
+	}
+	setPlaylistSnapshot(d, playlist.Href, snapshotId, len(tracks)-len(d.Songs.Items))
+	// NOTE: Indexes shift once tracks are removed
+	d.Songs.Selected = -1
+	d.Songs.SetItems(tracks, data.TrackDetail.Label)
+	return nil
+}
+
+// Keeps the playlist list in sync after an edit
+func setPlaylistSnapshot(d *data.AppData, href, snapshotId string, songDiff int) {
+	for i := range d.Playlist.Items {
+		if d.Playlist.Items[i].Href == href {
+			d.Playlist.Items[i].SnapshotId = snapshotId
+			d.Playlist.Items[i].NumSongs += songDiff
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package mpris
+
+import "time"
+
+// What media keys & desktop widgets asked for over MPRIS (D-Bus), the app or daemon runs it against the controller
+type Action int
+
+const (
+	Play Action = iota
+	Pause
+	PlayPause
+	Stop // Spotify can't stop, it pauses
+	Next
+	Previous
+	Seek        // Position is from the current position
+	SetPosition // Position is from the start of the track
+	SetVolume
+	SetShuffle
+	SetRepeat
+	OpenUri
+)
+
+// Names of the actions for the log, in the order of the actions
+var actionNames = []string{"play", "pause", "play-pause", "stop", "next", "previous", "seek", "set-position", "set-volume", "set-shuffle", "set-repeat", "open-uri"}
+
+func (a Action) String() string {
+	if int(a) < len(actionNames) {
+		return actionNames[a]
+	}
+	return "unknown"
+}
+
+type Command struct {
+	Action   Action
+	Position time.Duration
+	Repeat   string // off, context or track
+	Shuffle  bool
+	Uri      string
+	Volume   int // 0-100
+}
+
+// Commands that haven't been ran yet, more are dropped (a held down media key)
+const commandBuffer = 16
+
+// MPRIS loop status by spotify repeat mode
+var loopStatuses = map[string]string{
+	"off":     "None",
+	"context": "Playlist",
+	"track":   "Track",
+}
+
+func repeatMode(loopStatus string) (string, bool) {
+	for repeat, loop := range loopStatuses {
+		if loop == loopStatus {
+			return repeat, true
+		}
+	}
+	return "", false
+}
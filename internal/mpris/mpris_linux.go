@@ -0,0 +1,353 @@
+package mpris
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"neofy/internal/spotify"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+)
+
+const (
+	busName     = "org.mpris.MediaPlayer2.neofy"
+	objectPath  = "/org/mpris/MediaPlayer2"
+	rootIface   = "org.mpris.MediaPlayer2"
+	playerIface = "org.mpris.MediaPlayer2.Player"
+	// Track id when nothing is playing, from the MPRIS spec
+	noTrack = "/org/mpris/MediaPlayer2/TrackList/NoTrack"
+)
+
+// Publishes the player on the session bus, see Update & Commands
+type Server struct {
+	commands chan Command
+	conn     *dbus.Conn
+	props    *prop.Properties
+
+	mu     sync.Mutex
+	closed bool // The bus went away, nothing is published after it
+	last   spotify.Status
+	lastAt time.Time
+}
+
+// Connects to the session bus & takes the neofy name, a second app takes neofy.instance<pid> like the spec says
+func Start() (*Server, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("Start: %w", err)
+	}
+	s := &Server{commands: make(chan Command, commandBuffer), conn: conn}
+	err = s.export()
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("Start: %w", err)
+	}
+	name := busName
+	reply, err := conn.RequestName(name, dbus.NameFlagDoNotQueue)
+	if err == nil && reply != dbus.RequestNameReplyPrimaryOwner {
+		name = busName + ".instance" + strconv.Itoa(os.Getpid())
+		reply, err = conn.RequestName(name, dbus.NameFlagDoNotQueue)
+	}
+	if err == nil && reply != dbus.RequestNameReplyPrimaryOwner {
+		err = errors.New(name + " is taken")
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("Start: %w", err)
+	}
+	slog.Info("mpris started", "name", name)
+	return s, nil
+}
+
+// Sends what was asked for over D-Bus, nil when the server isn't running
+func (s *Server) Commands() <-chan Command {
+	if s == nil {
+		return nil
+	}
+	return s.commands
+}
+
+func (s *Server) Close() {
+	if s == nil {
+		return
+	}
+	s.conn.Close()
+}
+
+// Publishes the player's state, only what changed is sent to the bus
+func (s *Server) Update(status spotify.Status) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	// NOTE: The daemon restarting or the user logging out closes the connection
+	if !s.conn.Connected() {
+		s.closed = true
+		s.mu.Unlock()
+		slog.Warn("mpris connection closed, the player isn't published anymore")
+		return
+	}
+	// Players are told about seeks with the Seeked signal
+	seeked := !s.lastAt.IsZero() && s.last.Uri == status.Uri && status.Seeked(s.last, time.Since(s.lastAt))
+	s.last, s.lastAt = status, time.Now()
+	s.mu.Unlock()
+
+	values := map[string]any{
+		"CanGoNext":      status.State != "stopped",
+		"CanGoPrevious":  status.State != "stopped",
+		"CanPause":       status.State != "stopped",
+		"CanSeek":        status.State != "stopped",
+		"LoopStatus":     loopStatus(status.Repeat),
+		"Metadata":       metadata(status),
+		"PlaybackStatus": playbackStatus(status.State),
+		"Shuffle":        status.Shuffle,
+		"Volume":         float64(status.Volume) / 100,
+	}
+	for name, v := range values {
+		if !reflect.DeepEqual(s.props.GetMust(playerIface, name), v) {
+			s.set(name, v)
+		}
+	}
+	// NOTE: Position is never sent in PropertiesChanged, players work it out from the rate & Seeked
+	s.set("Position", microseconds(status.ProgressMs))
+	if seeked {
+		err := s.conn.Emit(objectPath, playerIface+".Seeked", microseconds(status.ProgressMs))
+		if err != nil {
+			slog.Warn("mpris seeked signal failed", "err", err)
+		}
+	}
+}
+
+// Changes a property of the player. props.Set is what D-Bus clients call, it refuses read only
+// properties & runs the callbacks, so SetMust is used & it's panic when the change can't be sent is logged
+func (s *Server) set(name string, v any) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Warn("mpris property not sent", "property", name, "err", r)
+		}
+	}()
+	s.props.SetMust(playerIface, name, v)
+}
+
+// Sends c to the owner of the player, dropped when it's behind
+func (s *Server) send(c Command) *dbus.Error {
+	select {
+	case s.commands <- c:
+	default:
+		slog.Warn("mpris command dropped", "action", c.Action)
+	}
+	return nil
+}
+
+func (s *Server) export() error {
+	err := s.conn.Export(root{}, objectPath, rootIface)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	p := player{s}
+	err = s.conn.ExportWithMap(p, playerMethods, objectPath, playerIface)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	stopped := spotify.StoppedStatus()
+	s.props, err = prop.Export(s.conn, objectPath, prop.Map{
+		rootIface: {
+			"CanQuit":             {Value: false, Emit: prop.EmitConst},
+			"CanRaise":            {Value: false, Emit: prop.EmitConst},
+			"HasTrackList":        {Value: false, Emit: prop.EmitConst},
+			"Identity":            {Value: "Neofy", Emit: prop.EmitConst},
+			"SupportedMimeTypes":  {Value: []string{}, Emit: prop.EmitConst},
+			"SupportedUriSchemes": {Value: []string{"spotify"}, Emit: prop.EmitConst},
+		},
+		playerIface: {
+			"CanControl":     {Value: true, Emit: prop.EmitConst},
+			"CanGoNext":      {Value: false, Emit: prop.EmitTrue},
+			"CanGoPrevious":  {Value: false, Emit: prop.EmitTrue},
+			"CanPause":       {Value: false, Emit: prop.EmitTrue},
+			"CanPlay":        {Value: true, Emit: prop.EmitConst},
+			"CanSeek":        {Value: false, Emit: prop.EmitTrue},
+			"LoopStatus":     {Value: "None", Emit: prop.EmitTrue, Writable: true, Callback: p.setLoopStatus},
+			"MaximumRate":    {Value: 1.0, Emit: prop.EmitConst},
+			"Metadata":       {Value: metadata(stopped), Emit: prop.EmitTrue},
+			"MinimumRate":    {Value: 1.0, Emit: prop.EmitConst},
+			"PlaybackStatus": {Value: "Stopped", Emit: prop.EmitTrue},
+			"Position":       {Value: int64(0), Emit: prop.EmitFalse},
+			"Rate":           {Value: 1.0, Emit: prop.EmitConst, Writable: true},
+			"Shuffle":        {Value: false, Emit: prop.EmitTrue, Writable: true, Callback: p.setShuffle},
+			"Volume":         {Value: 0.0, Emit: prop.EmitTrue, Writable: true, Callback: p.setVolume},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	node := &introspect.Node{
+		Name: objectPath,
+		Interfaces: []introspect.Interface{
+			introspect.IntrospectData,
+			prop.IntrospectData,
+			{Name: rootIface, Methods: introspect.Methods(root{}), Properties: s.props.Introspection(rootIface)},
+			{
+				Name:       playerIface,
+				Methods:    playerIntrospection(p),
+				Properties: s.props.Introspection(playerIface),
+				Signals:    []introspect.Signal{{Name: "Seeked", Args: []introspect.Arg{{Name: "Position", Type: "x"}}}},
+			},
+		},
+	}
+	err = s.conn.Export(introspect.NewIntrospectable(node), objectPath, "org.freedesktop.DBus.Introspectable")
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	return nil
+}
+
+// org.mpris.MediaPlayer2, neofy can't be raised or quit from outside
+type root struct{}
+
+func (root) Raise() *dbus.Error {
+	return nil
+}
+
+func (root) Quit() *dbus.Error {
+	return nil
+}
+
+// org.mpris.MediaPlayer2.Player, positions are in microseconds
+type player struct {
+	s *Server
+}
+
+// D-Bus names of the methods that can't have them in go.
+// NOTE: A Seek method that isn't io.Seeker's fails go vet
+var playerMethods = map[string]string{"SeekBy": "Seek"}
+
+func playerIntrospection(p player) []introspect.Method {
+	methods := introspect.Methods(p)
+	for i, m := range methods {
+		if name, ok := playerMethods[m.Name]; ok {
+			methods[i].Name = name
+		}
+	}
+	return methods
+}
+
+func (p player) Next() *dbus.Error {
+	return p.s.send(Command{Action: Next})
+}
+
+func (p player) Previous() *dbus.Error {
+	return p.s.send(Command{Action: Previous})
+}
+
+func (p player) Pause() *dbus.Error {
+	return p.s.send(Command{Action: Pause})
+}
+
+func (p player) PlayPause() *dbus.Error {
+	return p.s.send(Command{Action: PlayPause})
+}
+
+func (p player) Stop() *dbus.Error {
+	return p.s.send(Command{Action: Stop})
+}
+
+func (p player) Play() *dbus.Error {
+	return p.s.send(Command{Action: Play})
+}
+
+func (p player) SeekBy(offset int64) *dbus.Error {
+	return p.s.send(Command{Action: Seek, Position: time.Duration(offset) * time.Microsecond})
+}
+
+// NOTE: Ignored when the track changed since the player asked, like the spec says
+func (p player) SetPosition(trackId dbus.ObjectPath, position int64) *dbus.Error {
+	p.s.mu.Lock()
+	current := trackPath(p.s.last.Uri)
+	p.s.mu.Unlock()
+	if trackId != current {
+		return nil
+	}
+	return p.s.send(Command{Action: SetPosition, Position: time.Duration(position) * time.Microsecond})
+}
+
+func (p player) OpenUri(uri string) *dbus.Error {
+	return p.s.send(Command{Action: OpenUri, Uri: uri})
+}
+
+func (p player) setLoopStatus(c *prop.Change) *dbus.Error {
+	repeat, ok := repeatMode(c.Value.(string))
+	if !ok {
+		return prop.ErrInvalidArg
+	}
+	return p.s.send(Command{Action: SetRepeat, Repeat: repeat})
+}
+
+func (p player) setShuffle(c *prop.Change) *dbus.Error {
+	return p.s.send(Command{Action: SetShuffle, Shuffle: c.Value.(bool)})
+}
+
+func (p player) setVolume(c *prop.Change) *dbus.Error {
+	volume := int(c.Value.(float64)*100 + 0.5)
+	return p.s.send(Command{Action: SetVolume, Volume: min(max(volume, 0), 100)})
+}
+
+func metadata(status spotify.Status) map[string]dbus.Variant {
+	m := map[string]dbus.Variant{
+		"mpris:trackid": dbus.MakeVariant(trackPath(status.Uri)),
+	}
+	if status.State == "stopped" {
+		return m
+	}
+	m["mpris:length"] = dbus.MakeVariant(microseconds(status.DurationMs))
+	m["xesam:title"] = dbus.MakeVariant(status.Name)
+	m["xesam:artist"] = dbus.MakeVariant([]string{status.Artist})
+	if status.Uri != "" {
+		m["xesam:url"] = dbus.MakeVariant(status.Uri)
+	}
+	return m
+}
+
+var notPathChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// Object path of a track, spotify:track:<id> is /org/neofy/track/<id>. Other uris (episodes) keep their type
+func trackPath(uri string) dbus.ObjectPath {
+	id := notPathChars.ReplaceAllString(strings.TrimPrefix(uri, "spotify:track:"), "_")
+	if id == "" {
+		return noTrack
+	}
+	return dbus.ObjectPath("/org/neofy/track/" + id)
+}
+
+func loopStatus(repeat string) string {
+	if loop, ok := loopStatuses[repeat]; ok {
+		return loop
+	}
+	return "None"
+}
+
+func playbackStatus(state string) string {
+	switch state {
+	case "playing":
+		return "Playing"
+	case "paused":
+		return "Paused"
+	}
+	return "Stopped"
+}
+
+func microseconds(ms int) int64 {
+	return int64(ms) * 1000
+}
@@ -0,0 +1,195 @@
+package mpris
+
+import (
+	"bufio"
+	"neofy/internal/spotify"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// Starts a session bus of it's own for the test & connects a client to it, the server is started on it with Start.
+// stop ends the bus early, like the user logging out
+func startBus(t *testing.T) (s *Server, client *dbus.Conn, stop func()) {
+	t.Helper()
+	_, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon isn't installed")
+	}
+	cmd := exec.Command("dbus-daemon", "--session", "--print-address", "--nofork")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmd.Start()
+	if err != nil {
+		t.Fatalf("dbus-daemon: %v", err)
+	}
+	stop = func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}
+	t.Cleanup(stop)
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("dbus-daemon address: %v", err)
+	}
+	address = strings.TrimSpace(address)
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", address)
+
+	s, err = Start()
+	if err != nil {
+		t.Fatalf("Start() = %v", err)
+	}
+	t.Cleanup(s.Close)
+	client, err = dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("Connect() = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return s, client, stop
+}
+
+func playing(uri string, progressMs int) spotify.Status {
+	return spotify.Status{
+		Artist:     "Artist",
+		DurationMs: 200_000,
+		Name:       "Song",
+		Playing:    true,
+		ProgressMs: progressMs,
+		Repeat:     "off",
+		State:      "playing",
+		Uri:        uri,
+		Volume:     50,
+	}
+}
+
+func TestCommands(t *testing.T) {
+	s, client, _ := startBus(t)
+	s.Update(playing("spotify:track:abc", 10_000))
+	player := client.Object(busName, objectPath)
+	setProp := func(name string, v any) *dbus.Call {
+		return player.Call("org.freedesktop.DBus.Properties.Set", 0, playerIface, name, dbus.MakeVariant(v))
+	}
+	tests := []struct {
+		name    string
+		call    func() *dbus.Call
+		want    *Command // nil when nothing should be sent
+		wantErr bool
+	}{
+		{"play-pause", func() *dbus.Call { return player.Call(playerIface+".PlayPause", 0) }, &Command{Action: PlayPause}, false},
+		{"next", func() *dbus.Call { return player.Call(playerIface+".Next", 0) }, &Command{Action: Next}, false},
+		{"seek", func() *dbus.Call { return player.Call(playerIface+".Seek", 0, int64(-5_000_000)) }, &Command{Action: Seek, Position: -5 * time.Second}, false},
+		{"set-position", func() *dbus.Call {
+			return player.Call(playerIface+".SetPosition", 0, dbus.ObjectPath("/org/neofy/track/abc"), int64(30_000_000))
+		}, &Command{Action: SetPosition, Position: 30 * time.Second}, false},
+		{"set-position of another track", func() *dbus.Call {
+			return player.Call(playerIface+".SetPosition", 0, dbus.ObjectPath("/org/neofy/track/old"), int64(30_000_000))
+		}, nil, false},
+		{"loop", func() *dbus.Call { return setProp("LoopStatus", "Track") }, &Command{Action: SetRepeat, Repeat: "track"}, false},
+		{"bad loop", func() *dbus.Call { return setProp("LoopStatus", "Bogus") }, nil, true},
+		{"shuffle", func() *dbus.Call { return setProp("Shuffle", true) }, &Command{Action: SetShuffle, Shuffle: true}, false},
+		{"volume", func() *dbus.Call { return setProp("Volume", 0.42) }, &Command{Action: SetVolume, Volume: 42}, false},
+		{"volume past the max", func() *dbus.Call { return setProp("Volume", 1.5) }, &Command{Action: SetVolume, Volume: 100}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call().Err
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("call error = %v, want an error %v", err, tt.wantErr)
+			}
+			// NOTE: The command is sent before the reply, so it's there once the call returns
+			select {
+			case c := <-s.Commands():
+				if tt.want == nil {
+					t.Errorf("Commands() = %+v, want nothing", c)
+				} else if c != *tt.want {
+					t.Errorf("Commands() = %+v, want %+v", c, *tt.want)
+				}
+			default:
+				if tt.want != nil {
+					t.Errorf("Commands() is empty, want %+v", *tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateSignals(t *testing.T) {
+	s, client, _ := startBus(t)
+	err := client.AddMatchSignal(dbus.WithMatchObjectPath(objectPath))
+	if err != nil {
+		t.Fatalf("AddMatchSignal() = %v", err)
+	}
+	signals := make(chan *dbus.Signal, 16)
+	client.Signal(signals)
+	// Waits for the next signal called name, skipping the others
+	next := func(name string) *dbus.Signal {
+		t.Helper()
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case sig := <-signals:
+				if sig.Name == name {
+					return sig
+				}
+			case <-timeout:
+				t.Fatalf("no %s signal", name)
+				return nil
+			}
+		}
+	}
+
+	s.Update(playing("spotify:track:abc", 10_000))
+	// NOTE: Each property is sent in a PropertiesChanged of it's own
+	changed := map[string]dbus.Variant{}
+	for changed["PlaybackStatus"].Value() == nil {
+		sig := next("org.freedesktop.DBus.Properties.PropertiesChanged")
+		if len(sig.Body) < 2 || sig.Body[0] != playerIface {
+			t.Fatalf("PropertiesChanged = %v, want the player's properties", sig.Body)
+		}
+		props, _ := sig.Body[1].(map[string]dbus.Variant)
+		for name, v := range props {
+			changed[name] = v
+		}
+	}
+	if v := changed["PlaybackStatus"]; v.Value() != "Playing" {
+		t.Errorf("PropertiesChanged = %v, want PlaybackStatus Playing", changed)
+	}
+
+	// Playing on as expected isn't a seek, a jump is. The first Seeked has to be the jump
+	s.Update(playing("spotify:track:abc", 10_100))
+	s.Update(playing("spotify:track:abc", 100_000))
+	sig := next(playerIface + ".Seeked")
+	if len(sig.Body) != 1 || sig.Body[0] != int64(100_000_000) {
+		t.Errorf("Seeked = %v, want 100000000", sig.Body)
+	}
+
+	// A new track starting somewhere else isn't a seek
+	s.Update(playing("spotify:track:def", 50_000))
+	s.Update(playing("spotify:track:def", 150_000))
+	sig = next(playerIface + ".Seeked")
+	if len(sig.Body) != 1 || sig.Body[0] != int64(150_000_000) {
+		t.Errorf("Seeked = %v, want 150000000", sig.Body)
+	}
+}
+
+// Losing the bus stops the publishing, the app keeps running
+func TestUpdateAfterBusCloses(t *testing.T) {
+	s, _, stop := startBus(t)
+	s.Update(playing("spotify:track:abc", 10_000))
+	stop()
+	for i := range 5 {
+		s.Update(playing("spotify:track:def", i*50_000))
+		time.Sleep(10 * time.Millisecond)
+	}
+	s.mu.Lock()
+	closed := s.closed
+	s.mu.Unlock()
+	if !closed {
+		t.Error("Update() is still publishing after the bus closed")
+	}
+}
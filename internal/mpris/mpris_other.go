@@ -0,0 +1,23 @@
+//go:build !linux
+
+package mpris
+
+import (
+	"errors"
+	"neofy/internal/spotify"
+)
+
+// TODO: MPRIS is linux only, macOS & windows have their own media controls
+type Server struct{}
+
+func Start() (*Server, error) {
+	return nil, errors.New("Start: mpris is only on linux")
+}
+
+func (s *Server) Commands() <-chan Command {
+	return nil
+}
+
+func (s *Server) Close() {}
+
+func (s *Server) Update(spotify.Status) {}
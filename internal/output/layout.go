@@ -0,0 +1,82 @@
+package output
+
+import (
+	"neofy/internal/data"
+	"neofy/internal/layout"
+	"neofy/internal/mode"
+)
+
+// Names of the panes in the layout tree
+const (
+	playerPane    = "player"
+	playlistsPane = "playlists"
+	tracksPane    = "tracks"
+)
+
+// Sizes every pane from the layout settings & the size of the screen.
+// NOTE: Ran before every draw so mode changes & resizes are picked up
+func applyLayout(d *data.AppData) {
+	rects := layoutTree(d.Settings.Layout, activePane(d.Mode)).Compute(layout.Rect{
+		// The header takes up the first row & the status line the last
+		Y:      1,
+		Width:  d.Display.Width,
+		Height: d.Display.Height - 2,
+	})
+	d.Playlist.Display = paneDisplay(d.Playlist.Display, rects, playlistsPane)
+	d.Picker.Display = paneDisplay(d.Picker.Display, rects, playlistsPane)
+	d.Songs.Display = paneDisplay(d.Songs.Display, rects, tracksPane)
+	d.History.Display = paneDisplay(d.History.Display, rects, tracksPane)
+	d.Messages.Display = paneDisplay(d.Messages.Display, rects, tracksPane)
+	d.Player.Display = paneDisplay(d.Player.Display, rects, playerPane)
+}
+
+// Playlists & tracks side by side, with the player above or below them
+func layoutTree(l data.Layout, active string) *layout.Pane {
+	playlists := layoutPane(playlistsPane, l.Playlists, active)
+	tracks := layoutPane(tracksPane, l.Tracks, active)
+	lists := &layout.Pane{
+		Children: []*layout.Pane{playlists, tracks},
+		Hidden:   playlists.Hidden && tracks.Hidden,
+		Split:    layout.Columns,
+	}
+	player := layoutPane(playerPane, l.Player, active)
+	root := &layout.Pane{
+		Children: []*layout.Pane{lists, player},
+		Split:    layout.Rows,
+	}
+	if l.PlayerOnTop {
+		root.Children = []*layout.Pane{player, lists}
+	}
+	return root
+}
+
+func layoutPane(name string, p data.PaneLayout, active string) *layout.Pane {
+	return &layout.Pane{
+		Hidden: p.Hidden && name != active,
+		Max:    p.Max,
+		Min:    p.Min,
+		Name:   name,
+		Size:   p.Size,
+	}
+}
+
+// Pane the mode works in, the prompt, command line & help use the pane of the mode they were opened from
+func activePane(m data.Mode) string {
+	switch mode.Underlying(m).ShortDisplay() {
+	case 'U', 'A':
+		return playlistsPane
+	case 'T', 'H', 'M':
+		return tracksPane
+	}
+	return playerPane
+}
+
+// Hidden panes get a size of 0. Every pane ends with a | so it isn't part of the width
+func paneDisplay(display data.Display, rects map[string]layout.Rect, name string) data.Display {
+	r := rects[name]
+	display.X = r.X
+	display.Y = r.Y
+	display.Width = max(r.Width-1, 0)
+	display.Height = r.Height
+	return display
+}
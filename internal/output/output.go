@@ -3,59 +3,151 @@ package output
 import (
 	"fmt"
 	"neofy/internal/data"
+	"neofy/internal/display"
+	"neofy/internal/keymap"
+	"neofy/internal/list"
+	"neofy/internal/mode"
 	"strings"
-	"unicode/utf8"
 )
 
+// Picks up the new size of the terminal. Panes are sized again & the lists keep their
+// cursor in view on the next draw
+func Resize(d *data.AppData) error {
+	w, h, err := d.Term.GetTerminalSize()
+	if err != nil {
+		return fmt.Errorf("Resize: %w", err)
+	}
+	d.Display.Resize(w, h)
+	return nil
+}
+
 // This is the main draw func
 func UpdateApp(d *data.AppData) {
-	// Clears Screen
-	d.Display.Buffer.WriteString("\033[?25l") // Moves Cursor
-	d.Display.Buffer.WriteString("\033[H")    // Move Cursor to upper right
+	d.Display.Buffer.WriteString("\033[?25l") // Hides Cursor
 
 	// Update App Components
-	updatePlaylistDisplay(&d.Playlist)
-	updateTracksDisplay(&d.Songs)
-	updatePlayerDisplay(&d.Player)
+	applyLayout(d)
+	theme := d.Settings.Theme
+	updateListDisplay(&d.Playlist.List, &d.Playlist.Display, theme, theme.Selected, "Playlists", playlistRow)
+	// NOTE: The selected track is the one that is playing
+	updateListDisplay(&d.Songs.List, &d.Songs.Display, theme, theme.Playing, "Tracks", trackRow)
+	updateListDisplay(&d.History.List, &d.History.Display, theme, theme.Selected, "Recently Played", historyRow(d.Playlist.Items))
+	updateListDisplay(&d.Picker.List, &d.Picker.Display, theme, theme.Selected, "Add To Playlist", playlistRow)
+	updateListDisplay(&d.Messages.List, &d.Messages.Display, theme, theme.Selected, "Messages", messageRow)
+	updatePlayerDisplay(&d.Player, theme)
 
-	drawAppScreen(d)
-
-	d.Display.Buffer.WriteString("\033[?25l") // Moves Cursor
+	drawAppScreen(d, d.Display.NewFrame())
+	// NOTE: Only the cells that changed since the last frame are written, in a single write
+	d.Display.Render()
 
 	fmt.Print(d.Display.Buffer.String())
 	d.Display.Buffer.Reset()
 }
 
-func drawAppScreen(d *data.AppData) {
-	d.Display.Buffer.WriteString("Neofy v0.0.0: " + drawMode(d.Mode.ShortDisplay()) + "\r\n")
-	d.Display.Buffer.WriteString("\033[K")                     // Clears entire line
-	drawMusicOptions(&d.Playlist, &d.Songs, &d.Display.Buffer) // Playlist & tracks
-	drawPlayer(&d.Player, &d.Display.Buffer)
-
+func drawAppScreen(d *data.AppData, frame *display.Frame) {
+	header := styled(d.Settings.Theme.Header, "Neofy v0.0.0:") + " " + drawMode(d.Mode.ShortDisplay(), d.Settings.Theme)
+	if d.Mode.ShortDisplay() == 'I' || d.Mode.ShortDisplay() == 'C' {
+		header += " " + d.Prompt.Label + string(d.Prompt.Input) + "_"
+	}
+	frame.Put(0, 0, header)
+	active := mode.Underlying(d.Mode).ShortDisplay()
+	// History & the messages take the place of tracks while they're the active mode
+	rightPane := &d.Songs.Display
+	switch active {
+	case 'H':
+		rightPane = &d.History.Display
+	case 'M':
+		rightPane = &d.Messages.Display
+	}
+	// The picker takes the place of playlists while it's the active mode
+	leftPane := &d.Playlist.Display
+	if active == 'A' {
+		leftPane = &d.Picker.Display
+	}
+	border := styled(d.Settings.Theme.Border, "|")
+	drawPane(leftPane, border, frame)
+	drawPane(rightPane, border, frame)
+	drawPane(&d.Player.Display, border, frame)
+	drawStatus(d, frame)
+	if d.Mode.ShortDisplay() == '?' {
+		drawHelp(&d.Help, d.Settings.Theme, frame)
+	}
 }
 
-func drawMusicOptions(playlist *data.Playlist, tracks *data.Tracks, buf *strings.Builder) {
-	if playlist.Display.Height != tracks.Display.Height {
+// Draws the newest message in the last row until it times out
+func drawStatus(d *data.AppData, frame *display.Frame) {
+	m := mode.StatusMessage(d)
+	if m == nil {
 		return
 	}
-	numRows := playlist.Display.Height
-	for i := 0; i < numRows; i++ {
-		rowString := playlist.Display.Screen[i] + "|" + tracks.Display.Screen[i] + "|" + "\r\n"
-		buf.WriteString(rowString)
-		buf.WriteString("\033[K") // Clears entire line
+	text := m.Text
+	if m.Count > 1 {
+		text += fmt.Sprintf(" (x%d)", m.Count)
 	}
+	frame.Put(0, frame.Height-1, styled(levelStyle(m.Level, d.Settings.Theme), fitStringToWidth(text, frame.Width)))
 }
 
-func drawPlayer(p *data.MusicPlayer, buf *strings.Builder) {
-	if p.Display.Height < 3 {
+func levelStyle(l data.Level, theme data.Theme) string {
+	switch l {
+	case data.Success:
+		return theme.Success
+	case data.Warning:
+		return theme.Warning
+	case data.Error:
+		return theme.Error
+	}
+	return theme.Info
+}
+
+// Draws the help in a box in the middle of the screen, the box fits the lines when there's room
+func drawHelp(h *data.Help, theme data.Theme, frame *display.Frame) {
+	// NOTE: The top & bottom borders take up 2 rows, the border & a space on each side 4 columns
+	height := min(len(h.Lines)+2, frame.Height-2)
+	if height < 3 {
 		return
 	}
-	for i := range p.Display.Screen {
-		rowString := p.Display.Screen[i] + "|\r\n"
-		//rowString := p.Display.Screen[i] + "\r\n"
-		buf.WriteString(rowString)
-		buf.WriteString("\033[K") // Clears entire line
+	h.Height = height - 2
+	h.Offset = min(h.Offset, max(len(h.Lines)-h.Height, 0))
+	footer := " Esc to close "
+	if len(h.Lines) > h.Height {
+		footer = fmt.Sprintf(" %d-%d/%d, j/k to scroll, Esc to close ", h.Offset+1, h.Offset+h.Height, len(h.Lines))
+	}
+	linesWidth := max(stringWidth(h.Title), stringWidth(footer))
+	for _, line := range h.Lines {
+		linesWidth = max(linesWidth, stringWidth(line))
+	}
+	width := min(linesWidth+4, frame.Width-2)
+	if width < 8 {
+		return
+	}
+	x := (frame.Width - width) / 2
+	y := (frame.Height - height + 1) / 2
+
+	frame.Put(x, y, paneHeader(" "+h.Title+" ", width, theme))
+	side := styled(theme.Border, "|")
+	for i := 0; i < h.Height; i++ {
+		line := ""
+		if h.Offset+i < len(h.Lines) {
+			line = h.Lines[h.Offset+i]
+		}
+		frame.Put(x, y+1+i, side+" "+fitStringToWidth(line, width-4)+" "+side)
 	}
+	frame.Put(x, y+height-1, paneHeader(footer, width, theme))
+}
+
+// Draws the rows of a pane where the layout put it, each row ends with border
+func drawPane(pane *data.Display, border string, frame *display.Frame) {
+	for i, row := range pane.Screen {
+		frame.Put(pane.X, pane.Y+i, row+border)
+	}
+}
+
+// Wraps str in the SGR escapes of style, the style is reset after it
+func styled(style, str string) string {
+	if style == "" {
+		return str
+	}
+	return "\033[" + style + "m" + str + "\033[0m"
 }
 
 func printPlayerView(s []string, buf *strings.Builder) {
@@ -66,8 +158,12 @@ func printPlayerView(s []string, buf *strings.Builder) {
 	}
 }
 
-func updatePlayerDisplay(mp *data.MusicPlayer) {
-	// If there is no screen then we do nothing
+func updatePlayerDisplay(mp *data.MusicPlayer, theme data.Theme) {
+	// NOTE: The player needs 3 rows to be drawn, smaller players are left blank
+	if mp.Display.Height < 3 {
+		mp.Display.Screen = blankRows(mp.Display.Width, mp.Display.Height)
+		return
+	}
 	s := []string{}
 
 	// Prepare visual for player
@@ -75,21 +171,24 @@ func updatePlayerDisplay(mp *data.MusicPlayer) {
 	if mp.IsPlaying {
 		playPause = "||"
 	}
-	volume := "<|)"
+	// Each cell of the volume bar sets the volume it stands for when clicked
+	volume := []controlPart{{text: "<|) "}}
 	if mp.SupportsVolume {
-		volume += " "
 		numBold := mp.Volume / 10
 		if numBold > 10 {
 			numBold = 10
 		} else if numBold < 0 {
 			numBold = 0
 		}
-		numSlim := 10 - numBold
-		bold := strings.Repeat("=", numBold)
-		slim := strings.Repeat("-", numSlim)
-		volume += bold + slim
+		for i := 0; i < 10; i++ {
+			bar := "-"
+			if i < numBold {
+				bar = "="
+			}
+			volume = append(volume, controlPart{text: bar, volume: (i + 1) * 10})
+		}
 	} else {
-		volume += " xxxxx"
+		volume = append(volume, controlPart{text: "xxxxx"})
 	}
 	shuffled := "->"
 	if mp.IsShuffled {
@@ -105,15 +204,37 @@ func updatePlayerDisplay(mp *data.MusicPlayer) {
 		loop = "[!]"
 	}
 
+	playAction := keymap.PlayerPlay
+	if mp.IsPlaying {
+		playAction = keymap.PlayerPause
+	}
+	controls := append([]controlPart{
+		{text: shuffled, action: keymap.PlayerShuffle},
+		{text: "    "},
+		{text: "|<", action: keymap.PlayerPrevious},
+		{text: "    "},
+		{text: playPause, action: playAction},
+		{text: "    "},
+		{text: ">|", action: keymap.PlayerNext},
+		{text: "    "},
+		{text: loop, action: keymap.PlayerRepeat},
+		{text: "    "},
+	}, volume...)
+
 	// Write Visual to display
-	header := fitStringToWidthAndFillRune("--Song", '-', mp.Display.Width)
+	mp.Buttons = nil
+	header := styled(theme.Border, "--") + styled(theme.Header, "Song") + styled(theme.Border, fillWidthWithRune('-', mp.Display.Width-6))
+	if mp.Display.Width < 6 {
+		header = styled(theme.Border, fitStringToWidthAndFillRune("--Song", '-', mp.Display.Width))
+	}
 	s = append(s, header)
 	for i := 0; i < mp.Display.Height-2; i++ {
 		str := fitStringToWidth("&", mp.Display.Width)
 		switch i {
 		case (mp.Display.Height - 2) / 2:
 			//"x>    |<    ||    >|    [≥]    <|) =====-----"
-			str = fitStringInMiddle(shuffled+"    |<    "+playPause+"    >|    "+loop+"    "+volume, ' ', mp.Display.Width)
+			// NOTE: The header is the first row of the player
+			str, mp.Buttons = centerControls(controls, mp.Display.Width, i+1)
 		case 0:
 			if mp.PlayingSong.Name == "" {
 				str = fitStringToWidth("", mp.Display.Width)
@@ -129,88 +250,171 @@ func updatePlayerDisplay(mp *data.MusicPlayer) {
 		}
 		s = append(s, str)
 	}
-	bottom := fillWidthWithRune('-', mp.Display.Width)
+	bottom := styled(theme.Border, fillWidthWithRune('-', mp.Display.Width))
 	s = append(s, bottom)
 	mp.Display.Screen = s
 }
 
-func updatePlaylistDisplay(playlist *data.Playlist) {
-	if playlist.RowOffset < 0 {
-		playlist.RowOffset = 0
-	}
-	playlist.Display.Screen = []string{}
-	header := fitStringInMiddle("Playlists", '-', playlist.Display.Width)
-	playlist.Display.Screen = append(playlist.Display.Screen, header)
-	for i := 0; i < playlist.Display.Height-2; i++ {
-		playlistIndex := i + playlist.RowOffset
-		rowString := fitStringToWidth("", playlist.Display.Width)
-		if playlistIndex < len(playlist.Playlists) {
-			rowString = fitStringToWidth(playlist.Playlists[playlistIndex].Name, playlist.Display.Width)
-			if playlist.SelectedPlaylist != nil && (playlist.Playlists[playlistIndex].Name == playlist.SelectedPlaylist.Name) {
-				rowString = "\033[44m" + rowString + "\033[49m"
-			} else if playlistIndex == playlist.CursorPosY {
-				rowString = "\033[100m" + rowString + "\033[49m"
-			}
+// Text of the player controls, parts with an action or volume can be clicked
+type controlPart struct {
+	action keymap.Action
+	text   string
+	volume int
+}
+
+// Centers the controls in width like fitStringInMiddle, with where each button ends up on row y
+func centerControls(parts []controlPart, width, y int) (string, []data.PlayerButton) {
+	line := ""
+	for _, p := range parts {
+		line += p.text
+	}
+	padSpace := max(width-stringWidth(line), 0)
+	x := padSpace - padSpace/2
+	buttons := []data.PlayerButton{}
+	for _, p := range parts {
+		w := stringWidth(p.text)
+		if p.action != "" || p.volume > 0 {
+			buttons = append(buttons, data.PlayerButton{Action: p.action, Volume: p.volume, Width: w, X: x, Y: y})
 		}
-		playlist.Display.Screen = append(playlist.Display.Screen, rowString)
-	}
-	bottom := fillWidthWithRune('-', playlist.Display.Width)
-	playlist.Display.Screen = append(playlist.Display.Screen, bottom)
-}
-
-func updateTracksDisplay(tracks *data.Tracks) {
-	if tracks.RowOffset < 0 {
-		tracks.RowOffset = 0
-	}
-	tracks.Display.Screen = []string{}
-	header := fitStringInMiddle("Tracks", '-', tracks.Display.Width)
-	tracks.Display.Screen = append(tracks.Display.Screen, header)
-	for i := 0; i < tracks.Display.Height-2; i++ {
-		trackIndex := i + tracks.RowOffset
-		rowString := fitStringToWidth("", tracks.Display.Width)
-		if trackIndex < len(tracks.Tracks) {
-			rowString = fitStringToWidth(tracks.Tracks[trackIndex].Name, tracks.Display.Width)
-			if tracks.SelectedTrack != nil && (tracks.SelectedTrack.Name == tracks.Tracks[trackIndex].Name) {
-				rowString = "\033[44m" + rowString + "\033[49m"
-			} else if trackIndex == tracks.CursorPosY {
-				rowString = "\033[100m" + rowString + "\033[49m"
+		x += w
+	}
+	return fitStringInMiddle(line, ' ', width), buttons
+}
+
+// Draws the rows of l that are inside of it's viewport, label fits an item to the width of the pane.
+// The selected row is drawn in the selected style
+func updateListDisplay[T any](l *list.List[T], display *data.Display, theme data.Theme, selected string, title string, label func(T, int) string) {
+	// NOTE: The header & the bottom border take up 2 rows
+	l.SetHeight(display.Height - 2)
+	if display.Height < 2 {
+		display.Screen = blankRows(display.Width, display.Height)
+		return
+	}
+	display.Screen = []string{}
+	display.Screen = append(display.Screen, paneHeader(filterTitle(title, l.Filter), display.Width, theme))
+	rows := l.VisibleRows()
+	for i := 0; i < l.Height; i++ {
+		rowString := fitStringToWidth("", display.Width)
+		if i < len(rows) {
+			index := rows[i]
+			rowString = highlightRunes(label(l.Items[index], display.Width), l.Filter.Positions[index])
+			if index == l.Selected {
+				rowString = styled(selected, rowString)
+			} else if index == l.CursorPosY {
+				rowString = styled(theme.Cursor, rowString)
 			}
 		}
-		tracks.Display.Screen = append(tracks.Display.Screen, rowString)
+		display.Screen = append(display.Screen, rowString)
 	}
-	bottom := fillWidthWithRune('-', tracks.Display.Width)
-	tracks.Display.Screen = append(tracks.Display.Screen, bottom)
+	bottom := styled(theme.Border, fillWidthWithRune('-', display.Width))
+	display.Screen = append(display.Screen, bottom)
 }
 
-// Helper Func to pad or trim string
-func fitStringToWidth(str string, width int) string {
-	lenStr := utf8.RuneCountInString(str)
-	if lenStr <= width {
-		numWhiteSpaces := width - lenStr
-		return str + strings.Repeat(" ", numWhiteSpaces)
+// Title of a pane in the middle of the border like fitStringInMiddle
+func paneHeader(title string, width int, theme data.Theme) string {
+	title = truncateString(title, width, ellipsis)
+	padSpace := max(width-stringWidth(title), 0)
+	leftPad := fillWidthWithRune('-', padSpace-padSpace/2)
+	rightPad := fillWidthWithRune('-', padSpace/2)
+	return styled(theme.Border, leftPad) + styled(theme.Header, title) + styled(theme.Border, rightPad)
+}
+
+func blankRows(width, height int) []string {
+	rows := make([]string, max(height, 0))
+	for i := range rows {
+		rows[i] = fitStringToWidth("", width)
 	}
-	return str[:width]
+	return rows
 }
 
-func fitStringToWidthAndFillRune(str string, r rune, width int) string {
-	lenStr := utf8.RuneCountInString(str)
-	if lenStr <= width {
-		numWhiteSpaces := width - lenStr
-		return str + strings.Repeat(string(r), numWhiteSpaces)
+func playlistRow(p data.PlaylistDetail, width int) string {
+	return fitStringToWidth(p.Label(), width)
+}
+
+func trackRow(t data.TrackDetail, width int) string {
+	return fitStringToWidth(t.Label(), width)
+}
+
+// Shows when & where the track was played from
+func historyRow(playlists []data.PlaylistDetail) func(data.HistoryDetail, int) string {
+	return func(item data.HistoryDetail, width int) string {
+		song := item.Track.Name
+		if len(item.Track.Artists) > 0 {
+			song += " - " + item.Track.Artists[0].Name
+		}
+		context := historyContextName(item, playlists)
+		playedAt := item.PlayedAt.Local().Format("Jan 02 15:04")
+		songWidth := width - stringWidth(playedAt) - stringWidth(context) - 4
+		if songWidth > 0 {
+			return fitStringToWidth(playedAt+"  "+fitStringToWidth(song, songWidth)+"  "+context, width)
+		}
+		return fitStringToWidth(playedAt+"  "+song, width)
+	}
+}
+
+// Messages sent more than once in a row end with how many times
+func messageRow(m data.Message, width int) string {
+	if m.Count > 1 {
+		return fitStringToWidth(m.Label()+fmt.Sprintf(" (x%d)", m.Count), width)
+	}
+	return fitStringToWidth(m.Label(), width)
+}
+
+// Uses the playlist name when we know it, otherwise the context type
+func historyContextName(item data.HistoryDetail, playlists []data.PlaylistDetail) string {
+	if item.ContextUri == "" {
+		return ""
+	}
+	for _, p := range playlists {
+		if p.ContextUri == item.ContextUri {
+			return p.Name
+		}
+	}
+	return item.ContextType
+}
+
+func filterTitle(title string, f list.Filter) string {
+	if f.Query == "" {
+		return title
 	}
-	return str[:width]
+	return title + " /" + f.Query
+}
+
+// Underlines the runes at positions, str must not have escape codes
+func highlightRunes(str string, positions []int) string {
+	if len(positions) == 0 {
+		return str
+	}
+	var b strings.Builder
+	p := 0
+	for i, r := range []rune(str) {
+		if p < len(positions) && positions[p] == i {
+			b.WriteString("\033[4m" + string(r) + "\033[24m")
+			p++
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Helper Func to pad or trim string, strings that are cut short end with an ellipsis
+func fitStringToWidth(str string, width int) string {
+	return fitStringToWidthAndFillRune(truncateString(str, width, ellipsis), ' ', width)
+}
+
+func fitStringToWidthAndFillRune(str string, r rune, width int) string {
+	str = truncateString(str, width, "")
+	return str + strings.Repeat(string(r), max(width-stringWidth(str), 0))
 }
 
 func fillWidthWithRune(r rune, width int) string {
-	return strings.Repeat(string(r), width)
+	return strings.Repeat(string(r), max(width, 0))
 }
 
 func fitStringInMiddle(str string, pad rune, width int) string {
-	lenStr := utf8.RuneCountInString(str)
-	if lenStr > width {
-		return str[:width]
-	}
-	padSpace := width - lenStr
+	str = truncateString(str, width, ellipsis)
+	padSpace := max(width-stringWidth(str), 0)
 	leftPadLen := padSpace - padSpace/2
 	rightPadLen := padSpace - leftPadLen
 	leftPad := strings.Repeat(string(pad), leftPadLen)
@@ -218,14 +422,6 @@ func fitStringInMiddle(str string, pad rune, width int) string {
 	return leftPad + str + rightPad
 }
 
-func drawMode(r rune) string {
-	switch r {
-	case 'P':
-		return "\033[42m\033[30m " + string(r) + " \033[39m\033[40m"
-	case 'T':
-		return "\033[43m\033[30m " + string(r) + " \033[39m\033[40m"
-	case 'U':
-		return "\033[44m\033[30m " + string(r) + " \033[39m\033[40m"
-	}
-	return "\033[45m\033[30m " + string(r) + " \033[39m\033[40m"
+func drawMode(r rune, theme data.Theme) string {
+	return styled(theme.Modes[r], " "+string(r)+" ")
 }
@@ -0,0 +1,77 @@
+package output
+
+import (
+	"fmt"
+	"neofy/internal/data"
+	"neofy/internal/terminal"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// Requests that are done before this don't show the spinner so it doesn't flash
+const spinnerDelay = 150 * time.Millisecond
+
+const spinnerInterval = 100 * time.Millisecond
+
+// Draws a spinner in the status line while the app waits on a request. The main loop is blocked
+// so it's drawn straight to the terminal, the row is drawn again on the next draw
+type spinner struct {
+	app   *data.AppData
+	depth int // Requests made while one is running share the spinner
+	done  chan struct{}
+	wg    sync.WaitGroup
+	y     int
+}
+
+func NewSpinner(d *data.AppData) data.Spinner {
+	return &spinner{app: d}
+}
+
+func (s *spinner) Start(label string) {
+	s.depth++
+	if s.depth > 1 {
+		return
+	}
+	s.done = make(chan struct{})
+	s.y = s.app.Display.Height - 1
+	width := s.app.Display.Width
+	style := s.app.Settings.Theme.Info
+	s.wg.Add(1)
+	go func() {
+		defer terminal.RestoreOnPanic()
+		defer s.wg.Done()
+		select {
+		case <-s.done:
+			return
+		case <-time.After(spinnerDelay):
+		}
+		ticker := time.NewTicker(spinnerInterval)
+		defer ticker.Stop()
+		for i := 0; ; i++ {
+			text := string(spinnerFrames[i%len(spinnerFrames)]) + " " + label + "..."
+			// NOTE: Moves to the status line & hides the cursor that printing shows
+			fmt.Print("\033[?25l\033[" + strconv.Itoa(s.y+1) + ";1H" + styled(style, fitStringToWidth(text, width)))
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (s *spinner) Stop() {
+	if s.depth == 0 {
+		return
+	}
+	s.depth--
+	if s.depth > 0 {
+		return
+	}
+	close(s.done)
+	s.wg.Wait()
+	s.app.Display.ForgetRow(s.y)
+}
@@ -0,0 +1,45 @@
+package output
+
+import (
+	"strings"
+
+	"github.com/rivo/uniseg"
+)
+
+// Put at the end of strings that are cut short
+const ellipsis = "…"
+
+// Number of cells str takes up in the terminal. Wide runes (CJK, emoji) take up 2,
+// combining marks & joined emoji (👩‍💻) count as part of the character before them
+func stringWidth(str string) int {
+	return uniseg.StringWidth(str)
+}
+
+// Cuts str down to at most width cells without splitting a character, tail takes the
+// place of the end when str doesn't fit. The result can be a cell short when a wide
+// character doesn't fit
+func truncateString(str string, width int, tail string) string {
+	width = max(width, 0)
+	if stringWidth(str) <= width {
+		return str
+	}
+	tailWidth := stringWidth(tail)
+	if tailWidth > width {
+		tail = ""
+		tailWidth = 0
+	}
+	var b strings.Builder
+	used := 0
+	state := -1
+	for len(str) > 0 {
+		var cluster string
+		var w int
+		cluster, str, w, state = uniseg.FirstGraphemeClusterInString(str, state)
+		if used+w > width-tailWidth {
+			break
+		}
+		b.WriteString(cluster)
+		used += w
+	}
+	return b.String() + tail
+}
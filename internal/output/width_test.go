@@ -0,0 +1,44 @@
+package output
+
+import "testing"
+
+func TestTruncateString(t *testing.T) {
+	tests := []struct {
+		name  string
+		str   string
+		width int
+		tail  string
+		want  string
+	}{
+		{"fits", "abc", 5, ellipsis, "abc"},
+		{"exact", "abcde", 5, ellipsis, "abcde"},
+		{"cut", "abcdef", 5, ellipsis, "abcd…"},
+		{"no tail", "abcdef", 3, "", "abc"},
+		{"no width", "abc", 0, ellipsis, ""},
+		{"negative width", "abc", -2, ellipsis, ""},
+		{"wide", "日本語", 5, ellipsis, "日本…"},
+		{"wide a cell short", "日本語", 4, ellipsis, "日…"},
+		{"wide without a tail", "日本語", 3, "", "日"},
+		{"wide that doesn't fit at all", "日本", 1, "", ""},
+		{"combining", "e\u0301e\u0301e\u0301e\u0301", 3, ellipsis, "e\u0301e\u0301…"},
+		{"combining fits", "e\u0301x", 2, ellipsis, "e\u0301x"},
+		{"zwj emoji", "👩‍💻👩‍💻", 3, ellipsis, "👩‍💻…"},
+		{"zwj emoji isn't split", "a👩‍💻", 2, "", "a"},
+		{"flags", "🇯🇵🇯🇵", 3, ellipsis, "🇯🇵…"},
+		{"tail wider than width", "abcdef", 2, "[more]", "ab"},
+		{"wide tail wider than width", "abcdef", 1, "日", "a"},
+		{"tail as wide as width", "abcdef", 3, "...", "..."},
+		{"wide tail", "abcdef", 4, "日", "ab日"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateString(tt.str, tt.width, tt.tail)
+			if got != tt.want {
+				t.Errorf("truncateString(%q, %d, %q) = %q, want %q", tt.str, tt.width, tt.tail, got, tt.want)
+			}
+			if w := stringWidth(got); w > max(tt.width, 0) {
+				t.Errorf("truncateString(%q, %d, %q) is %d cells wide", tt.str, tt.width, tt.tail, w)
+			}
+		})
+	}
+}
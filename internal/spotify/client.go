@@ -0,0 +1,88 @@
+package spotify
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Most of a failed response's body that is logged
+const maxLoggedBody = 1024
+
+// Query params that hold secrets, they are never logged
+var secretParams = []string{"access_token", "client_secret", "code", "refresh_token"}
+
+// Tokens in json (access_token & refresh_token) & form bodies
+var secretValues = regexp.MustCompile(`("(?:access|refresh)_token"\s*:\s*")[^"]*(")|((?:access_token|refresh_token|client_secret|code)=)[^&\s]*`)
+
+// Client every request to spotify is made with
+func newClient() http.Client {
+	return http.Client{Transport: tracingTransport{}}
+}
+
+// Logs every request at the debug level with how long it took, failed requests are logged as warnings.
+// Tokens are redacted
+type tracingTransport struct{}
+
+func (tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	attrs := []any{
+		"method", req.Method,
+		"url", redactUrl(req.URL),
+		"latency", time.Since(start).Round(time.Millisecond),
+	}
+	if auth := req.Header.Get("Authorization"); auth != "" {
+		scheme, _, _ := strings.Cut(auth, " ")
+		attrs = append(attrs, "auth", scheme+" [REDACTED]")
+	}
+	if err != nil {
+		slog.Warn("http request failed", append(attrs, "err", err)...)
+		return nil, err
+	}
+	attrs = append(attrs, "status", resp.StatusCode)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// NOTE: The body is read to be logged, the caller gets a copy of it
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if readErr == nil {
+			attrs = append(attrs, "body", redactBody(body))
+		}
+		slog.Warn("http request", attrs...)
+		return resp, nil
+	}
+	slog.Debug("http request", attrs...)
+	return resp, nil
+}
+
+func redactUrl(u *url.URL) string {
+	query := u.Query()
+	redacted := false
+	for _, param := range secretParams {
+		if query.Has(param) {
+			query.Set(param, "REDACTED")
+			redacted = true
+		}
+	}
+	if !redacted {
+		return u.String()
+	}
+	c := *u
+	c.RawQuery = query.Encode()
+	return c.String()
+}
+
+// NOTE: Redacted before it's cut, a token cut in half wouldn't match
+func redactBody(body []byte) string {
+	redacted := secretValues.ReplaceAllString(string(body), "${1}${3}REDACTED${2}")
+	if len(redacted) > maxLoggedBody {
+		redacted = redacted[:maxLoggedBody]
+	}
+	return redacted
+}
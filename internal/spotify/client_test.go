@@ -0,0 +1,31 @@
+package spotify
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	secret := strings.Repeat("s", 64)
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"json", `{"access_token":"` + secret + `","expires_in":3600}`},
+		{"form", "grant_type=refresh_token&refresh_token=" + secret + "&client_id=id"},
+		// The token starts before the cut & ends after it
+		{"json past the cut", strings.Repeat(" ", maxLoggedBody-30) + `{"refresh_token":"` + secret + `"}`},
+		{"form past the cut", strings.Repeat(" ", maxLoggedBody-30) + "code=" + secret},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactBody([]byte(tt.body))
+			if strings.Contains(got, "ssss") {
+				t.Errorf("redactBody(%q) = %q, the token is logged", tt.body, got)
+			}
+			if len(got) > maxLoggedBody {
+				t.Errorf("redactBody(%q) is %d bytes, want at most %d", tt.body, len(got), maxLoggedBody)
+			}
+		})
+	}
+}
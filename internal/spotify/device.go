@@ -0,0 +1,129 @@
+package spotify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type SlimDevice struct {
+	Id             string
+	IsActive       bool
+	Name           string
+	SupportsVolume bool
+	Type           string // computer, smartphone, speaker, etc.
+	Volume         int
+}
+
+// Returns the devices spotify connect can play on
+func (SpotifyPlayer) AvailableDevices(accessToken string) ([]SlimDevice, error) {
+	err := validTokenFormat(accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("AvailableDevices: %w", err)
+	}
+	apiUrl := "https://api.spotify.com/v1/me/player/devices"
+	headerStr := "Bearer " + accessToken
+
+	req, err := http.NewRequest("GET", apiUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("AvailableDevices: req: %w", err)
+	}
+	req.Header.Add("Authorization", headerStr)
+	c := newClient()
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("AvailableDevices: client: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, errors.New("AvailableDevices: Http status not successful: " + resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("AvailableDevices: read body: %w", err)
+	}
+
+	var respStruct DevicesResponse
+	err = json.Unmarshal(body, &respStruct)
+	if err != nil {
+		return nil, fmt.Errorf("AvailableDevices: json: unmarshal: %w", err)
+	}
+	devices := []SlimDevice{}
+	for _, d := range respStruct.Devices {
+		devices = append(devices, SlimDevice{
+			Id:             d.ID,
+			IsActive:       d.IsActive,
+			Name:           d.Name,
+			SupportsVolume: d.SupportsVolume,
+			Type:           d.Type,
+			Volume:         d.VolumePercent,
+		})
+	}
+	return devices, nil
+}
+
+// Moves playback to the device, play starts it even when it was paused
+func (SpotifyPlayer) TransferPlayback(accessToken, deviceId string, play bool) error {
+	err := validTokenFormat(accessToken)
+	if err != nil {
+		return fmt.Errorf("TransferPlayback: %w", err)
+	}
+	if deviceId == "" {
+		return errors.New("TransferPlayback: deviceId is empty")
+	}
+	apiUrl := "https://api.spotify.com/v1/me/player"
+	headerStr := "Bearer " + accessToken
+	reqStruct := struct {
+		DeviceIds []string `json:"device_ids"`
+		Play      bool     `json:"play"`
+	}{
+		DeviceIds: []string{deviceId},
+		Play:      play,
+	}
+	reqBody, err := json.Marshal(reqStruct)
+	if err != nil {
+		return fmt.Errorf("TransferPlayback: json: marshal: %w", err)
+	}
+
+	req, err := http.NewRequest("PUT", apiUrl, bytes.NewReader(reqBody))
+	if err != nil {
+		return fmt.Errorf("TransferPlayback: req: %w", err)
+	}
+	req.Header.Add("Authorization", headerStr)
+	c := newClient()
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("TransferPlayback: client: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("TransferPlayback: read body: %w", err)
+	}
+
+	var respStruct PlayerErrorResponse
+	err = json.Unmarshal(body, &respStruct)
+	if err != nil {
+		return fmt.Errorf("TransferPlayback: json: unmarshal: %w", err)
+	}
+	return fmt.Errorf("TransferPlayback: %w", &respStruct.Error)
+}
+
+type DevicesResponse struct {
+	Devices []struct {
+		ID               string `json:"id"`
+		IsActive         bool   `json:"is_active"`
+		IsPrivateSession bool   `json:"is_private_session"`
+		IsRestricted     bool   `json:"is_restricted"`
+		Name             string `json:"name"`
+		Type             string `json:"type"`
+		VolumePercent    int    `json:"volume_percent"`
+		SupportsVolume   bool   `json:"supports_volume"`
+	} `json:"devices"`
+}
@@ -0,0 +1,135 @@
+package spotify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// NOTE: Only one of before & after can be set, they are unix timestamps in ms
+func (SpotifyPlayer) RecentlyPlayed(accessToken, before, after string, limit int) (*SlimRecentlyPlayed, error) {
+	err := validTokenFormat(accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("RecentlyPlayed: %w", err)
+	}
+	if before != "" && after != "" {
+		return nil, errors.New("RecentlyPlayed: before & after can't both be set")
+	}
+	if limit < 1 || limit > 50 {
+		return nil, errors.New("RecentlyPlayed: limit must be between 1-50")
+	}
+	params := url.Values{}
+	params.Add("limit", strconv.Itoa(limit))
+	if before != "" {
+		params.Add("before", before)
+	}
+	if after != "" {
+		params.Add("after", after)
+	}
+	apiUrl := "https://api.spotify.com/v1/me/player/recently-played?" + params.Encode()
+	headerStr := "Bearer " + accessToken
+
+	req, err := http.NewRequest("GET", apiUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("RecentlyPlayed: req: %w", err)
+	}
+	req.Header.Add("Authorization", headerStr)
+	c := newClient()
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("RecentlyPlayed: client: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, errors.New("RecentlyPlayed: Http status not successful: " + resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("RecentlyPlayed: read body: %w", err)
+	}
+
+	var respStruct recentlyPlayedResp
+
+	err = json.Unmarshal(body, &respStruct)
+	if err != nil {
+		return nil, fmt.Errorf("RecentlyPlayed: json: unmarshal: %w", err)
+	}
+
+	items := []SlimPlayHistory{}
+	for _, item := range respStruct.Items {
+		artists := []SlimArtistInfo{}
+		for _, a := range item.Track.Artists {
+			artists = append(artists, SlimArtistInfo{Name: a.Name})
+		}
+		playedAt, err := time.Parse(time.RFC3339, item.PlayedAt)
+		if err != nil {
+			return nil, fmt.Errorf("RecentlyPlayed: played at: %w", err)
+		}
+		newItem := SlimPlayHistory{
+			Track: SlimTrackInfo{
+				Name:       item.Track.Name,
+				ContextUri: item.Track.Uri,
+				DurationMs: item.Track.DurationMs,
+				Artist:     artists,
+			},
+			PlayedAt: playedAt,
+		}
+		if item.Context != nil {
+			newItem.ContextType = item.Context.Type
+			newItem.ContextUri = item.Context.URI
+			newItem.ContextHref = item.Context.Href
+		}
+		items = append(items, newItem)
+	}
+
+	slimResp := SlimRecentlyPlayed{
+		Items: items,
+	}
+	if respStruct.Cursors != nil {
+		slimResp.Before = respStruct.Cursors.Before
+		slimResp.After = respStruct.Cursors.After
+	}
+	return &slimResp, nil
+}
+
+type SlimRecentlyPlayed struct {
+	Items  []SlimPlayHistory
+	Before string // Cursor for older items, empty when there are none
+	After  string // Cursor for newer items
+}
+
+type SlimPlayHistory struct {
+	Track       SlimTrackInfo
+	PlayedAt    time.Time
+	ContextType string // playlist, album, artist or empty
+	ContextUri  string
+	ContextHref string
+}
+
+type recentlyPlayedResp struct {
+	Href    string  `json:"href"`
+	Limit   int     `json:"limit"`
+	Next    *string `json:"next"`
+	Cursors *struct {
+		After  string `json:"after"`
+		Before string `json:"before"`
+	} `json:"cursors"`
+	Total int `json:"total"`
+	Items []struct {
+		Track struct {
+			Name       string `json:"name"`
+			Uri        string `json:"uri"`
+			DurationMs int    `json:"duration_ms"`
+			Artists    []struct {
+				Name string `json:"name"`
+			} `json:"artists"`
+		} `json:"track"`
+		PlayedAt string   `json:"played_at"`
+		Context  *Context `json:"context"`
+	} `json:"items"`
+}
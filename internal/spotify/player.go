@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"slices"
 	"strconv"
+	"strings"
 )
 
 type Controller interface {
@@ -23,7 +24,20 @@ type Controller interface {
 	ShuffleMode(string, bool) error
 	GetUserPlaylists(string) ([]SlimPlaylistData, error)
 	GetTracksFromPlaylist(string, string, int) ([]SlimTrackInfo, error)
+	GetPlaylist(string, string) (*SlimPlaylistWithTracks, error)
 	StartTrack(string, string, int) error
+	PlayUri(string, string) error
+	AddTracksToPlaylist(string, string, []string, int) (string, error)
+	RemoveTracksFromPlaylist(string, string, string, []string) (string, error)
+	ReorderPlaylistTracks(string, string, string, int, int, int) (string, error)
+	CurrentUserId(string) (string, error)
+	CreatePlaylist(string, string, PlaylistChanges) (*SlimPlaylistData, error)
+	ChangePlaylistDetails(string, string, PlaylistChanges) error
+	UnfollowPlaylist(string, string) error
+	RecentlyPlayed(string, string, string, int) (*SlimRecentlyPlayed, error)
+	SeekToPosition(string, int) error
+	AvailableDevices(string) ([]SlimDevice, error)
+	TransferPlayback(string, string, bool) error
 }
 
 type SpotifyPlayer struct{}
@@ -46,11 +60,15 @@ func (SpotifyPlayer) PlaybackState(accessToken string) (*SlimPlayerData, error)
 		return nil, fmt.Errorf("PlaybackState: req: %w", err)
 	}
 	req.Header.Add("Authorization", headerStr)
-	c := http.Client{}
+	c := newClient()
 	resp, err := c.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("PlaybackState: client: %w", err)
 	}
+	// NOTE: Spotify sends 204 when there is no active device
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, fmt.Errorf("PlaybackState: %w", ErrNoPlayback)
+	}
 	if resp.StatusCode != 200 {
 		return nil, errors.New("PlaybackState: resp code isn't 200 got: " + resp.Status)
 	}
@@ -70,9 +88,10 @@ func (SpotifyPlayer) PlaybackState(accessToken string) (*SlimPlayerData, error)
 		IsPlaying:      respStruct.IsPlaying,
 		IsShuffled:     respStruct.ShuffleState,
 		SupportsVolume: respStruct.Device.SupportsVolume,
-		Volume:         *respStruct.Device.VolumePercent,
+		Volume:         respStruct.Device.volume(),
 		SongName:       respStruct.Item.Name,
-		Artist:         respStruct.Item.Artists[0].Name,
+		SongUri:        respStruct.Item.URI,
+		Artist:         respStruct.Item.artist(),
 		Repeat:         respStruct.RepeatState,
 		SongProgress:   respStruct.ProgressMs,
 		SongDuration:   respStruct.Item.DurationMs,
@@ -99,7 +118,7 @@ func (SpotifyPlayer) StartResumePlayback(accessToken string) error {
 		return fmt.Errorf("StartResumePlayback: req: %w", err)
 	}
 	req.Header.Add("Authorization", headerStr)
-	c := http.Client{}
+	c := newClient()
 	resp, err := c.Do(req)
 	if err != nil {
 		return fmt.Errorf("StartResumePlayback: client: %w", err)
@@ -119,7 +138,7 @@ func (SpotifyPlayer) StartResumePlayback(accessToken string) error {
 	if err != nil {
 		return fmt.Errorf("StartResumePlayback: json: unmarshal: %w", err)
 	}
-	return errors.New("StartResumePlayback: Status: " + respStruct.Error.Status + " message: " + respStruct.Error.Message)
+	return fmt.Errorf("StartResumePlayback: %w", &respStruct.Error)
 }
 
 func (SpotifyPlayer) PausePlayback(accessToken string) error {
@@ -135,7 +154,7 @@ func (SpotifyPlayer) PausePlayback(accessToken string) error {
 		return fmt.Errorf("PausePlayback: req: %w", err)
 	}
 	req.Header.Add("Authorization", headerStr)
-	c := http.Client{}
+	c := newClient()
 	resp, err := c.Do(req)
 	if err != nil {
 		return fmt.Errorf("PausePlayback: client: %w", err)
@@ -155,7 +174,7 @@ func (SpotifyPlayer) PausePlayback(accessToken string) error {
 	if err != nil {
 		return fmt.Errorf("PausePlayback: json: unmarshal: %w", err)
 	}
-	return errors.New("PausePlayback: Status: " + respStruct.Error.Status + "message: " + respStruct.Error.Message)
+	return fmt.Errorf("PausePlayback: %w", &respStruct.Error)
 }
 
 func (SpotifyPlayer) SkipToNext(accessToken string) error {
@@ -171,7 +190,7 @@ func (SpotifyPlayer) SkipToNext(accessToken string) error {
 		return fmt.Errorf("SkipToNext: req: %w", err)
 	}
 	req.Header.Add("Authorization", headerStr)
-	c := http.Client{}
+	c := newClient()
 	resp, err := c.Do(req)
 	if err != nil {
 		return fmt.Errorf("SkipToNext: client: %w", err)
@@ -191,7 +210,7 @@ func (SpotifyPlayer) SkipToNext(accessToken string) error {
 	if err != nil {
 		return fmt.Errorf("SkipToNext: json: unmarshal: %w", err)
 	}
-	return errors.New("SkipToNext: Status: " + respStruct.Error.Status + "message: " + respStruct.Error.Message)
+	return fmt.Errorf("SkipToNext: %w", &respStruct.Error)
 }
 
 func (SpotifyPlayer) SkipToPrevious(accessToken string) error {
@@ -207,7 +226,7 @@ func (SpotifyPlayer) SkipToPrevious(accessToken string) error {
 		return fmt.Errorf("SkipToPrevious: req: %w", err)
 	}
 	req.Header.Add("Authorization", headerStr)
-	c := http.Client{}
+	c := newClient()
 	resp, err := c.Do(req)
 	if err != nil {
 		return fmt.Errorf("SkipToPrevious: client: %w", err)
@@ -227,7 +246,7 @@ func (SpotifyPlayer) SkipToPrevious(accessToken string) error {
 	if err != nil {
 		return fmt.Errorf("SkipToPrevious: json: unmarshal: %w", err)
 	}
-	return errors.New("SkipToPrevious: Status: " + respStruct.Error.Status + "message: " + respStruct.Error.Message)
+	return fmt.Errorf("SkipToPrevious: %w", &respStruct.Error)
 }
 
 func (SpotifyPlayer) SetPlaybackVolume(accessToken string, volume int) error {
@@ -245,7 +264,7 @@ func (SpotifyPlayer) SetPlaybackVolume(accessToken string, volume int) error {
 		return fmt.Errorf("SetPlaybackVolume: req: %w", err)
 	}
 	req.Header.Add("Authorization", headerStr)
-	c := http.Client{}
+	c := newClient()
 	resp, err := c.Do(req)
 	if err != nil {
 		return fmt.Errorf("SetPlaybackVolume: client: %w", err)
@@ -265,7 +284,46 @@ func (SpotifyPlayer) SetPlaybackVolume(accessToken string, volume int) error {
 	if err != nil {
 		return fmt.Errorf("SetPlaybackVolume: json: unmarshal: %w", err)
 	}
-	return errors.New("SetPlaybackVolume: Status: " + respStruct.Error.Status + "message: " + respStruct.Error.Message)
+	return fmt.Errorf("SetPlaybackVolume: %w", &respStruct.Error)
+}
+
+// Seeks to position in the playing track
+func (SpotifyPlayer) SeekToPosition(accessToken string, positionMs int) error {
+	err := validTokenFormat(accessToken)
+	if err != nil {
+		return fmt.Errorf("SeekToPosition: %w", err)
+	}
+	if positionMs < 0 {
+		return errors.New("SeekToPosition: position must be positive")
+	}
+	apiUrl := "https://api.spotify.com/v1/me/player/seek?position_ms=" + strconv.Itoa(positionMs)
+	headerStr := "Bearer " + accessToken
+	req, err := http.NewRequest("PUT", apiUrl, nil)
+	if err != nil {
+		return fmt.Errorf("SeekToPosition: req: %w", err)
+	}
+	req.Header.Add("Authorization", headerStr)
+	c := newClient()
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("SeekToPosition: client: %w", err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("SeekToPosition: read body: %w", err)
+	}
+
+	var respStruct PlayerErrorResponse
+	err = json.Unmarshal(body, &respStruct)
+	if err != nil {
+		return fmt.Errorf("SeekToPosition: json: unmarshal: %w", err)
+	}
+	return fmt.Errorf("SeekToPosition: %w", &respStruct.Error)
 }
 
 func (SpotifyPlayer) CurrentPlayingTrack(accessToken string) (*SlimCurrentSongData, error) {
@@ -281,12 +339,12 @@ func (SpotifyPlayer) CurrentPlayingTrack(accessToken string) (*SlimCurrentSongDa
 		return nil, fmt.Errorf("CurrentPlayingTrack: req: %w", err)
 	}
 	req.Header.Add("Authorization", headerStr)
-	c := http.Client{}
+	c := newClient()
 	resp, err := c.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("CurrentPlayingTrack: client: %w", err)
 	}
-	if resp.StatusCode < 200 && resp.StatusCode >= 300 {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return nil, errors.New("CurrentPlayingTrack: Http status not successful: " + resp.Status)
 	}
 
@@ -307,7 +365,8 @@ func (SpotifyPlayer) CurrentPlayingTrack(accessToken string) (*SlimCurrentSongDa
 		IsPlaying:    respStruct.IsPlaying,
 		IsShuffled:   respStruct.ShuffleState,
 		SongName:     respStruct.Item.Name,
-		Artist:       respStruct.Item.Artists[0].Name,
+		SongUri:      respStruct.Item.URI,
+		Artist:       respStruct.Item.artist(),
 		Repeat:       respStruct.RepeatState,
 		SongProgress: respStruct.ProgressMs,
 		SongDuration: respStruct.Item.DurationMs,
@@ -333,7 +392,7 @@ func (SpotifyPlayer) RepeatMode(accessToken string, state string) error {
 		return fmt.Errorf("SetRepeatMode: req: %w", err)
 	}
 	req.Header.Add("Authorization", headerStr)
-	c := http.Client{}
+	c := newClient()
 	resp, err := c.Do(req)
 	if err != nil {
 		return fmt.Errorf("SetRepeatMode: client: %w", err)
@@ -353,7 +412,7 @@ func (SpotifyPlayer) RepeatMode(accessToken string, state string) error {
 	if err != nil {
 		return fmt.Errorf("SetRepeatMode: json: unmarshal: %w", err)
 	}
-	return errors.New("SetRepeatMode: Status: " + respStruct.Error.Status + "message: " + respStruct.Error.Message)
+	return fmt.Errorf("SetRepeatMode: %w", &respStruct.Error)
 }
 
 func (SpotifyPlayer) ShuffleMode(accessToken string, isShuffled bool) error {
@@ -374,7 +433,7 @@ func (SpotifyPlayer) ShuffleMode(accessToken string, isShuffled bool) error {
 		return fmt.Errorf("ShuffleMode: req: %w", err)
 	}
 	req.Header.Add("Authorization", headerStr)
-	c := http.Client{}
+	c := newClient()
 	resp, err := c.Do(req)
 	if err != nil {
 		return fmt.Errorf("ShuffleMode: client: %w", err)
@@ -394,7 +453,7 @@ func (SpotifyPlayer) ShuffleMode(accessToken string, isShuffled bool) error {
 	if err != nil {
 		return fmt.Errorf("ShuffleMode: json: unmarshal: %w", err)
 	}
-	return errors.New("ShuffleMode: Status: " + respStruct.Error.Status + "message: " + respStruct.Error.Message)
+	return fmt.Errorf("ShuffleMode: %w", &respStruct.Error)
 }
 
 func validTokenFormat(token string) error {
@@ -411,6 +470,7 @@ type SlimPlayerData struct {
 	SupportsVolume bool
 	Volume         int
 	SongName       string
+	SongUri        string
 	Artist         string
 	Repeat         string
 	SongDuration   int
@@ -422,6 +482,7 @@ type SlimCurrentSongData struct {
 	IsPlaying    bool
 	IsShuffled   bool
 	SongName     string
+	SongUri      string
 	Artist       string
 	Repeat       string
 	SongDuration int
@@ -464,6 +525,14 @@ type Device struct {
 	SupportsVolume   bool    `json:"supports_volume"`
 }
 
+// Volume of the device, 0 when it's unknown (devices that don't support volume send null)
+func (d Device) volume() int {
+	if d.VolumePercent == nil {
+		return 0
+	}
+	return *d.VolumePercent
+}
+
 type Context struct {
 	Type         string `json:"type"`
 	Href         string `json:"href"`
@@ -544,6 +613,18 @@ type Item struct {
 	Type        string  `json:"type"`
 	URI         string  `json:"uri"`
 	IsLocal     bool    `json:"is_local"`
+	// Only set for episodes
+	Show struct {
+		Name string `json:"name"`
+	} `json:"show"`
+}
+
+// First artist, the show for episodes. Ads & episodes have no artists
+func (i Item) artist() string {
+	if len(i.Artists) > 0 {
+		return i.Artists[0].Name
+	}
+	return i.Show.Name
 }
 
 type Action struct {
@@ -566,8 +647,62 @@ type CurrentSongAction struct {
 }
 
 type PlayerErrorResponse struct {
-	Error struct {
-		Status  string `json:"status"`
-		Message string `json:"message"`
-	} `json:"error"`
+	Error APIError `json:"error"`
+}
+
+// Error Spotify sends back when a request fails
+type APIError struct {
+	Message string `json:"message"`
+	Reason  string `json:"reason"` // Only sent by the player endpoints (NO_ACTIVE_DEVICE)
+	Status  int    `json:"status"`
+}
+
+func (e *APIError) Error() string {
+	return "Status: " + strconv.Itoa(e.Status) + " message: " + e.Message
+}
+
+// Returned when no device is playing (or paused)
+var ErrNoPlayback = errors.New("nothing is playing")
+
+// Reasons spotify gives for failed player requests, the others use spotify's message
+var apiReasons = map[string]string{
+	"DEVICE_NOT_CONTROLLABLE": "the device can't be controlled",
+	"NO_ACTIVE_DEVICE":        "no active device, start playing on a device or pick one with :device",
+	"NO_NEXT_TRACK":           "there is no next track",
+	"NO_PREV_TRACK":           "there is no previous track",
+	"PREMIUM_REQUIRED":        "Spotify Premium is needed",
+	"RATE_LIMITED":            "too many requests, try again in a bit",
+	"VOLUME_CONTROL_DISALLOW": "the device doesn't allow changing the volume",
+}
+
+// Why err happened in words for the user
+func ErrorReason(err error) string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if reason, ok := apiReasons[apiErr.Reason]; ok {
+			return reason
+		}
+		if apiErr.Message != "" {
+			return apiErr.Message
+		}
+		return http.StatusText(apiErr.Status)
+	}
+	// NOTE: Errors are wrapped as funcName: funcName: reason, the func names mean nothing to the user
+	msg := err.Error()
+	for {
+		prefix, rest, ok := strings.Cut(msg, ": ")
+		if !ok || strings.ContainsAny(prefix, " \"") {
+			return msg
+		}
+		msg = rest
+	}
+}
+
+// Spotify has no device to play on, or nothing is playing
+func IsNoDevice(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Reason == "NO_ACTIVE_DEVICE" || apiErr.Status == http.StatusNotFound
+	}
+	return errors.Is(err, ErrNoPlayback)
 }
@@ -0,0 +1,35 @@
+package spotify
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// Episodes & ads have no artists & some devices send a null volume
+func TestPlaybackStateWithoutArtists(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		artist string
+		volume int
+	}{
+		{"track", `{"device":{"volume_percent":40},"item":{"artists":[{"name":"Artist"}]}}`, "Artist", 40},
+		{"episode", `{"device":{"volume_percent":null},"currently_playing_type":"episode","item":{"show":{"name":"Show"}}}`, "Show", 0},
+		{"ad", `{"device":{},"currently_playing_type":"ad","item":null}`, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp playbackStateResponse
+			err := json.Unmarshal([]byte(tt.body), &resp)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := resp.Item.artist(); got != tt.artist {
+				t.Errorf("artist() = %q, want %q", got, tt.artist)
+			}
+			if got := resp.Device.volume(); got != tt.volume {
+				t.Errorf("volume() = %d, want %d", got, tt.volume)
+			}
+		})
+	}
+}
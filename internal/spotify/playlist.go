@@ -23,12 +23,12 @@ func (SpotifyPlayer) GetUserPlaylists(accessToken string) ([]SlimPlaylistData, e
 		return nil, fmt.Errorf("GetUserPlaylists: req: %w", err)
 	}
 	req.Header.Add("Authorization", headerStr)
-	c := http.Client{}
+	c := newClient()
 	resp, err := c.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("GetUserPlaylists: client: %w", err)
 	}
-	if resp.StatusCode < 200 && resp.StatusCode >= 300 {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return nil, errors.New("GetUserPlaylists: Http status not successful: " + resp.Status)
 	}
 
@@ -47,18 +47,27 @@ func (SpotifyPlayer) GetUserPlaylists(accessToken string) ([]SlimPlaylistData, e
 
 	userPlaylistResp := []SlimPlaylistData{}
 	for _, item := range respStruct.Items {
-		newSlim := SlimPlaylistData{
-			Name:         item.Name,
-			DetailRefUrl: item.Href,
-			TotalTracks:  item.Tracks.Total,
-			TracksHref:   item.Tracks.Href,
-			ContextUri:   item.URI,
-		}
-		userPlaylistResp = append(userPlaylistResp, newSlim)
+		userPlaylistResp = append(userPlaylistResp, toSlimPlaylistData(item))
 	}
 	return userPlaylistResp, nil
 }
 
+func toSlimPlaylistData(item PlaylistItem) SlimPlaylistData {
+	return SlimPlaylistData{
+		Id:            item.ID,
+		Name:          item.Name,
+		Description:   item.Description,
+		DetailRefUrl:  item.Href,
+		TotalTracks:   item.Tracks.Total,
+		TracksHref:    item.Tracks.Href,
+		ContextUri:    item.URI,
+		SnapshotId:    item.SnapshotID,
+		OwnerId:       item.Owner.ID,
+		Public:        item.Public,
+		Collaborative: item.Collaborative,
+	}
+}
+
 func (SpotifyPlayer) GetTracksFromPlaylist(hrefUrl, accessToken string, numSongs int) ([]SlimTrackInfo, error) {
 	err := validTokenFormat(accessToken)
 	if err != nil {
@@ -76,12 +85,12 @@ func (SpotifyPlayer) GetTracksFromPlaylist(hrefUrl, accessToken string, numSongs
 		return nil, fmt.Errorf("GetUserPlaylists: req: %w", err)
 	}
 	req.Header.Add("Authorization", headerStr)
-	c := http.Client{}
+	c := newClient()
 	resp, err := c.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("GetUserPlaylists: client: %w", err)
 	}
-	if resp.StatusCode < 200 && resp.StatusCode >= 300 {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return nil, errors.New("GetUserPlaylists: Http status not successful: " + resp.Status)
 	}
 
@@ -137,12 +146,12 @@ func (SpotifyPlayer) GetPlaylist(hrefUrl, accessToken string) (*SlimPlaylistWith
 		return nil, fmt.Errorf("GetPlaylist: req: %w", err)
 	}
 	req.Header.Add("Authorization", headerStr)
-	c := http.Client{}
+	c := newClient()
 	resp, err := c.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("GetPlaylist: client: %w", err)
 	}
-	if resp.StatusCode < 200 && resp.StatusCode >= 300 {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return nil, errors.New("GetPlaylist: Http status not successful: " + resp.Status)
 	}
 
@@ -191,11 +200,17 @@ func validateUrl(url string) error {
 }
 
 type SlimPlaylistData struct {
-	Name         string
-	DetailRefUrl string
-	TotalTracks  int
-	TracksHref   string
-	ContextUri   string
+	Id            string
+	Name          string
+	Description   string
+	DetailRefUrl  string
+	TotalTracks   int
+	TracksHref    string
+	ContextUri    string
+	SnapshotId    string
+	OwnerId       string
+	Public        bool
+	Collaborative bool
 }
 
 type SlimTrackInfo struct {
@@ -0,0 +1,286 @@
+package spotify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// NOTE: The tracks href of a playlist is the endpoint used to edit it's items,
+// every edit returns a new snapshot id that should be used for the next edit
+
+// Adds the uris to the playlist, a negative position appends them
+func (SpotifyPlayer) AddTracksToPlaylist(tracksHref, accessToken string, uris []string, position int) (string, error) {
+	err := validTokenFormat(accessToken)
+	if err != nil {
+		return "", fmt.Errorf("AddTracksToPlaylist: %w", err)
+	}
+	err = validateUrl(tracksHref)
+	if err != nil {
+		return "", fmt.Errorf("AddTracksToPlaylist: %w", err)
+	}
+	if len(uris) == 0 || len(uris) > 100 {
+		return "", errors.New("AddTracksToPlaylist: number of uris must be between 1-100")
+	}
+	reqStruct := struct {
+		Uris     []string `json:"uris"`
+		Position *int     `json:"position,omitempty"`
+	}{Uris: uris}
+	if position >= 0 {
+		reqStruct.Position = &position
+	}
+	snapshotId, err := editPlaylistItems("POST", tracksHref, accessToken, reqStruct)
+	if err != nil {
+		return "", fmt.Errorf("AddTracksToPlaylist: %w", err)
+	}
+	return snapshotId, nil
+}
+
+// Removes every occurrence of the uris from the playlist
+func (SpotifyPlayer) RemoveTracksFromPlaylist(tracksHref, accessToken, snapshotId string, uris []string) (string, error) {
+	err := validTokenFormat(accessToken)
+	if err != nil {
+		return "", fmt.Errorf("RemoveTracksFromPlaylist: %w", err)
+	}
+	err = validateUrl(tracksHref)
+	if err != nil {
+		return "", fmt.Errorf("RemoveTracksFromPlaylist: %w", err)
+	}
+	if len(uris) == 0 || len(uris) > 100 {
+		return "", errors.New("RemoveTracksFromPlaylist: number of uris must be between 1-100")
+	}
+	type trackUri struct {
+		Uri string `json:"uri"`
+	}
+	reqStruct := struct {
+		Tracks     []trackUri `json:"tracks"`
+		SnapshotId string     `json:"snapshot_id,omitempty"`
+	}{SnapshotId: snapshotId}
+	for _, uri := range uris {
+		reqStruct.Tracks = append(reqStruct.Tracks, trackUri{Uri: uri})
+	}
+	newSnapshotId, err := editPlaylistItems("DELETE", tracksHref, accessToken, reqStruct)
+	if err != nil {
+		return "", fmt.Errorf("RemoveTracksFromPlaylist: %w", err)
+	}
+	return newSnapshotId, nil
+}
+
+// Moves rangeLength items starting at rangeStart so they are placed before insertBefore
+func (SpotifyPlayer) ReorderPlaylistTracks(tracksHref, accessToken, snapshotId string, rangeStart, insertBefore, rangeLength int) (string, error) {
+	err := validTokenFormat(accessToken)
+	if err != nil {
+		return "", fmt.Errorf("ReorderPlaylistTracks: %w", err)
+	}
+	err = validateUrl(tracksHref)
+	if err != nil {
+		return "", fmt.Errorf("ReorderPlaylistTracks: %w", err)
+	}
+	if rangeStart < 0 || insertBefore < 0 || rangeLength < 1 {
+		return "", errors.New("ReorderPlaylistTracks: not a valid range")
+	}
+	reqStruct := struct {
+		RangeStart   int    `json:"range_start"`
+		InsertBefore int    `json:"insert_before"`
+		RangeLength  int    `json:"range_length"`
+		SnapshotId   string `json:"snapshot_id,omitempty"`
+	}{
+		RangeStart:   rangeStart,
+		InsertBefore: insertBefore,
+		RangeLength:  rangeLength,
+		SnapshotId:   snapshotId,
+	}
+	newSnapshotId, err := editPlaylistItems("PUT", tracksHref, accessToken, reqStruct)
+	if err != nil {
+		return "", fmt.Errorf("ReorderPlaylistTracks: %w", err)
+	}
+	return newSnapshotId, nil
+}
+
+// Sends reqStruct as json to the playlist items endpoint & returns the new snapshot id
+func editPlaylistItems(method, tracksHref, accessToken string, reqStruct any) (string, error) {
+	reqBody, err := json.Marshal(reqStruct)
+	if err != nil {
+		return "", fmt.Errorf("editPlaylistItems: json: marshal: %w", err)
+	}
+	reqBodyReader := bytes.NewReader(reqBody)
+	headerStr := "Bearer " + accessToken
+
+	req, err := http.NewRequest(method, tracksHref, reqBodyReader)
+	if err != nil {
+		return "", fmt.Errorf("editPlaylistItems: req: %w", err)
+	}
+	req.Header.Add("Authorization", headerStr)
+	req.Header.Add("Content-Type", "application/json")
+	c := newClient()
+	resp, err := c.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("editPlaylistItems: client: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("editPlaylistItems: read body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var respStruct PlayerErrorResponse
+		err = json.Unmarshal(body, &respStruct)
+		if err != nil {
+			return "", fmt.Errorf("editPlaylistItems: json: unmarshal: %w", err)
+		}
+		return "", fmt.Errorf("editPlaylistItems: %w", &respStruct.Error)
+	}
+
+	respStruct := struct {
+		SnapshotId string `json:"snapshot_id"`
+	}{}
+	err = json.Unmarshal(body, &respStruct)
+	if err != nil {
+		return "", fmt.Errorf("editPlaylistItems: json: unmarshal: %w", err)
+	}
+	return respStruct.SnapshotId, nil
+}
+
+// Only the fields that are set will be sent to spotify
+type PlaylistChanges struct {
+	Name          *string `json:"name,omitempty"`
+	Description   *string `json:"description,omitempty"`
+	Public        *bool   `json:"public,omitempty"`
+	Collaborative *bool   `json:"collaborative,omitempty"`
+}
+
+func (SpotifyPlayer) CreatePlaylist(userId, accessToken string, changes PlaylistChanges) (*SlimPlaylistData, error) {
+	err := validTokenFormat(accessToken)
+	if err != nil {
+		return nil, fmt.Errorf("CreatePlaylist: %w", err)
+	}
+	if userId == "" {
+		return nil, errors.New("CreatePlaylist: empty user id")
+	}
+	if changes.Name == nil || *changes.Name == "" {
+		return nil, errors.New("CreatePlaylist: playlist name is required")
+	}
+	apiUrl := "https://api.spotify.com/v1/users/" + url.PathEscape(userId) + "/playlists"
+	headerStr := "Bearer " + accessToken
+
+	reqBody, err := json.Marshal(changes)
+	if err != nil {
+		return nil, fmt.Errorf("CreatePlaylist: json: marshal: %w", err)
+	}
+	reqBodyReader := bytes.NewReader(reqBody)
+
+	req, err := http.NewRequest("POST", apiUrl, reqBodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("CreatePlaylist: req: %w", err)
+	}
+	req.Header.Add("Authorization", headerStr)
+	req.Header.Add("Content-Type", "application/json")
+	c := newClient()
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("CreatePlaylist: client: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("CreatePlaylist: read body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var respStruct PlayerErrorResponse
+		err = json.Unmarshal(body, &respStruct)
+		if err != nil {
+			return nil, fmt.Errorf("CreatePlaylist: json: unmarshal: %w", err)
+		}
+		return nil, fmt.Errorf("CreatePlaylist: %w", &respStruct.Error)
+	}
+
+	var respStruct PlaylistItem
+	err = json.Unmarshal(body, &respStruct)
+	if err != nil {
+		return nil, fmt.Errorf("CreatePlaylist: json: unmarshal: %w", err)
+	}
+	slimResp := toSlimPlaylistData(respStruct)
+	return &slimResp, nil
+}
+
+func (SpotifyPlayer) ChangePlaylistDetails(playlistId, accessToken string, changes PlaylistChanges) error {
+	err := validTokenFormat(accessToken)
+	if err != nil {
+		return fmt.Errorf("ChangePlaylistDetails: %w", err)
+	}
+	if playlistId == "" {
+		return errors.New("ChangePlaylistDetails: empty playlist id")
+	}
+	apiUrl := "https://api.spotify.com/v1/playlists/" + url.PathEscape(playlistId)
+	err = sendPlaylistRequest("PUT", apiUrl, accessToken, changes)
+	if err != nil {
+		return fmt.Errorf("ChangePlaylistDetails: %w", err)
+	}
+	return nil
+}
+
+// Spotify has no delete, unfollowing a playlist removes it from the user's library
+func (SpotifyPlayer) UnfollowPlaylist(playlistId, accessToken string) error {
+	err := validTokenFormat(accessToken)
+	if err != nil {
+		return fmt.Errorf("UnfollowPlaylist: %w", err)
+	}
+	if playlistId == "" {
+		return errors.New("UnfollowPlaylist: empty playlist id")
+	}
+	apiUrl := "https://api.spotify.com/v1/playlists/" + url.PathEscape(playlistId) + "/followers"
+	err = sendPlaylistRequest("DELETE", apiUrl, accessToken, nil)
+	if err != nil {
+		return fmt.Errorf("UnfollowPlaylist: %w", err)
+	}
+	return nil
+}
+
+// Sends reqStruct as json (if not nil) when only the status of the response matters
+func sendPlaylistRequest(method, apiUrl, accessToken string, reqStruct any) error {
+	var reqBodyReader io.Reader
+	if reqStruct != nil {
+		reqBody, err := json.Marshal(reqStruct)
+		if err != nil {
+			return fmt.Errorf("sendPlaylistRequest: json: marshal: %w", err)
+		}
+		reqBodyReader = bytes.NewReader(reqBody)
+	}
+	headerStr := "Bearer " + accessToken
+
+	req, err := http.NewRequest(method, apiUrl, reqBodyReader)
+	if err != nil {
+		return fmt.Errorf("sendPlaylistRequest: req: %w", err)
+	}
+	req.Header.Add("Authorization", headerStr)
+	if reqStruct != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	c := newClient()
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("sendPlaylistRequest: client: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("sendPlaylistRequest: read body: %w", err)
+	}
+
+	var respStruct PlayerErrorResponse
+	err = json.Unmarshal(body, &respStruct)
+	if err != nil {
+		return fmt.Errorf("sendPlaylistRequest: json: unmarshal: %w", err)
+	}
+	return fmt.Errorf("sendPlaylistRequest: %w", &respStruct.Error)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"neofy/internal/scheduler"
 	"neofy/internal/terminal"
 	"net/http"
@@ -31,6 +32,9 @@ type User struct {
 	RefreshToken string
 }
 
+// Gets an access token that works, refreshing it when needed
+type TokenSource func() (string, error)
+
 func AccessToken(clientId, clientSecret string) (string, error) {
 	apiUrl := "https://accounts.spotify.com/api/token"
 	data := url.Values{}
@@ -43,7 +47,7 @@ func AccessToken(clientId, clientSecret string) (string, error) {
 		return "", fmt.Errorf("AccessToken: req: %w", err)
 	}
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	c := http.Client{}
+	c := newClient()
 	resp, err := c.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("AccessToken: client: %w", err)
@@ -74,7 +78,7 @@ func AuthorizeUserUrl(clientId string) (string, error) {
 	data.Add("client_id", clientId)
 	data.Add("response_type", "code")
 	data.Add("redirect_uri", redirectUri)
-	data.Add("scope", "user-modify-playback-state user-read-playback-state playlist-read-private")
+	data.Add("scope", "user-modify-playback-state user-read-playback-state playlist-read-private playlist-modify-private playlist-modify-public user-read-recently-played")
 	reqUrl := apiUrl + "?" + data.Encode()
 
 	return reqUrl, nil
@@ -116,7 +120,7 @@ func UserAccessAndRefreshToken(code, clientId, clientSecret string) (string, str
 	encodedAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte(authString))
 	req.Header.Add("Authorization", encodedAuth)
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	c := http.Client{}
+	c := newClient()
 	resp, err := c.Do(req)
 	if err != nil {
 		return "", "", fmt.Errorf("UserAccessAndRefreshToken: client: %w", err)
@@ -126,6 +130,9 @@ func UserAccessAndRefreshToken(code, clientId, clientSecret string) (string, str
 	if err != nil {
 		return "", "", fmt.Errorf("UserAccessAndRefreshToken: read body: %w", err)
 	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("UserAccessAndRefreshToken: %w", tokenError(resp.StatusCode, body))
+	}
 	respStruct := struct {
 		AccessToken  string `json:"access_token"`
 		TokenType    string `json:"token_type"`
@@ -155,7 +162,7 @@ func RefreshUserTokens(refreshToken, clientId, clientSecret string) (string, str
 	encodedAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte(authString))
 	req.Header.Add("Authorization", encodedAuth)
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	c := http.Client{}
+	c := newClient()
 	resp, err := c.Do(req)
 	if err != nil {
 		return "", "", fmt.Errorf("RefreshUserTokens: client: %w", err)
@@ -165,6 +172,9 @@ func RefreshUserTokens(refreshToken, clientId, clientSecret string) (string, str
 	if err != nil {
 		return "", "", fmt.Errorf("RefreshUserTokens: read body: %w", err)
 	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("RefreshUserTokens: %w", tokenError(resp.StatusCode, body))
+	}
 	respStruct := struct {
 		AccessToken  string `json:"access_token"`
 		TokenType    string `json:"token_type"`
@@ -180,11 +190,22 @@ func RefreshUserTokens(refreshToken, clientId, clientSecret string) (string, str
 	return respStruct.AccessToken, respStruct.RefreshToken, nil
 }
 
+// Error the accounts service sends back when a token request fails (invalid_grant)
+func tokenError(status int, body []byte) error {
+	respStruct := struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}{}
+	json.Unmarshal(body, &respStruct)
+	return &APIError{Message: strings.TrimSpace(respStruct.Error + " " + respStruct.ErrorDescription), Status: status}
+}
+
 // Hourly Scheduler to request new tokens & save it
 type refreshTokenJob struct {
 	userTokens   *User
 	clientId     string
 	clientSecret string
+	onRefresh    func(User) // Optional, called with the new tokens
 }
 
 func (s *refreshTokenJob) Execute() {
@@ -192,6 +213,7 @@ func (s *refreshTokenJob) Execute() {
 	newAccess, newRefresh, err := RefreshUserTokens(s.userTokens.RefreshToken, s.clientId, s.clientSecret)
 	if err != nil {
 		// Handle the error
+		slog.Error("token refresh failed", "err", err)
 		panic(fmt.Errorf("Refresh hourly scheduler: Execute: %w", err))
 	}
 	// Update value
@@ -201,15 +223,21 @@ func (s *refreshTokenJob) Execute() {
 		newRefresh = s.userTokens.RefreshToken
 	}
 	s.userTokens.RefreshToken = newRefresh
+	slog.Info("tokens refreshed")
+	if s.onRefresh != nil {
+		s.onRefresh(*s.userTokens)
+	}
 }
 
-func RefreshHourlyScheduler(u *User, clientId, clientSecret string) *scheduler.Schedular {
+// onRefresh is optional, it's called with the new tokens after every refresh
+func RefreshHourlyScheduler(u *User, clientId, clientSecret string, onRefresh func(User)) *scheduler.Schedular {
 	startTime := time.Now().Add(time.Minute * 57)
 	delay := time.Minute * 57
 	j := refreshTokenJob{
 		userTokens:   u,
 		clientId:     clientId,
 		clientSecret: clientSecret,
+		onRefresh:    onRefresh,
 	}
 	var js []scheduler.Job
 	js = append(js, &j)
@@ -0,0 +1,90 @@
+package spotify
+
+import (
+	"fmt"
+	"time"
+)
+
+// What is playing, made for scripts (json & templates). Fields are only ever added
+type Status struct {
+	Artist         string `json:"artist"`
+	Duration       string `json:"duration"` // m:ss, h:mm:ss for an hour or more
+	DurationMs     int    `json:"duration_ms"`
+	Name           string `json:"name"`
+	Playing        bool   `json:"playing"`
+	Progress       string `json:"progress"` // Same format as Duration
+	ProgressMs     int    `json:"progress_ms"`
+	Repeat         string `json:"repeat"` // off, context or track
+	Shuffle        bool   `json:"shuffle"`
+	State          string `json:"state"` // playing, paused or stopped
+	SupportsVolume bool   `json:"supports_volume"`
+	Uri            string `json:"uri"`
+	Volume         int    `json:"volume"` // 0-100, 0 when the device doesn't support volume
+}
+
+func NewStatus(p *SlimPlayerData) Status {
+	progress := 0
+	if p.SongProgress != nil {
+		progress = *p.SongProgress
+	}
+	state := "paused"
+	if p.IsPlaying {
+		state = "playing"
+	}
+	volume := 0
+	if p.SupportsVolume {
+		volume = p.Volume
+	}
+	return Status{
+		Artist:         p.Artist,
+		Duration:       clock(p.SongDuration),
+		DurationMs:     p.SongDuration,
+		Name:           p.SongName,
+		Playing:        p.IsPlaying,
+		Progress:       clock(progress),
+		ProgressMs:     progress,
+		Repeat:         p.Repeat,
+		Shuffle:        p.IsShuffled,
+		State:          state,
+		SupportsVolume: p.SupportsVolume,
+		Uri:            p.SongUri,
+		Volume:         volume,
+	}
+}
+
+// Progress that is further than this from where it should be counts as a seek
+const seekThreshold = 2 * time.Second
+
+// Is s different from last, that was seen elapsed ago. Progress moving along with playback isn't a change
+func (s Status) Changed(last Status, elapsed time.Duration) bool {
+	if s.Seeked(last, elapsed) {
+		return true
+	}
+	last.Progress, last.ProgressMs = "", 0
+	s.Progress, s.ProgressMs = "", 0
+	return last != s
+}
+
+// Did the progress jump since last (seeking or starting the track over)
+func (s Status) Seeked(last Status, elapsed time.Duration) bool {
+	expected := last.ProgressMs
+	if last.Playing {
+		expected += int(elapsed.Milliseconds())
+	}
+	drift := time.Duration(s.ProgressMs-expected) * time.Millisecond
+	return drift > seekThreshold || drift < -seekThreshold
+}
+
+// Status when nothing is playing, see ErrNoPlayback
+func StoppedStatus() Status {
+	return Status{Duration: clock(0), Progress: clock(0), State: "stopped"}
+}
+
+// m:ss, or h:mm:ss for an hour or more
+func clock(ms int) string {
+	s := ms / 1000
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%d:%02d", s/60, s%60)
+}
@@ -3,11 +3,11 @@ package spotify
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 func (SpotifyPlayer) StartTrack(contextUri, accessToken string, songIndex int) error {
@@ -32,7 +32,7 @@ func (SpotifyPlayer) StartTrack(contextUri, accessToken string, songIndex int) e
 		return fmt.Errorf("StartTrack: req: %w", err)
 	}
 	req.Header.Add("Authorization", headerStr)
-	c := http.Client{}
+	c := newClient()
 	resp, err := c.Do(req)
 	if err != nil {
 		return fmt.Errorf("StartTrack: client: %w", err)
@@ -52,5 +52,61 @@ func (SpotifyPlayer) StartTrack(contextUri, accessToken string, songIndex int) e
 	if err != nil {
 		return fmt.Errorf("StartTrack: json: unmarshal: %w", err)
 	}
-	return errors.New("StartTrack: Status: " + respStruct.Error.Status + " message: " + respStruct.Error.Message)
+	return fmt.Errorf("StartTrack: %w", &respStruct.Error)
+}
+
+// Plays a single track uri, any other uri (playlist, album, artist) is played as a context
+func (SpotifyPlayer) PlayUri(uri, accessToken string) error {
+	err := validTokenFormat(accessToken)
+	if err != nil {
+		return fmt.Errorf("PlayUri: %w", err)
+	}
+	err = validateUrl(uri)
+	if err != nil {
+		return fmt.Errorf("PlayUri: uri: %w", err)
+	}
+	apiUrl := "https://api.spotify.com/v1/me/player/play"
+	headerStr := "Bearer " + accessToken
+
+	reqStruct := struct {
+		ContextUri string   `json:"context_uri,omitempty"`
+		Uris       []string `json:"uris,omitempty"`
+	}{}
+	if strings.HasPrefix(uri, "spotify:track:") {
+		reqStruct.Uris = []string{uri}
+	} else {
+		reqStruct.ContextUri = uri
+	}
+	reqBody, err := json.Marshal(reqStruct)
+	if err != nil {
+		return fmt.Errorf("PlayUri: json: marshal: %w", err)
+	}
+	reqBodyReader := bytes.NewReader(reqBody)
+
+	req, err := http.NewRequest("PUT", apiUrl, reqBodyReader)
+	if err != nil {
+		return fmt.Errorf("PlayUri: req: %w", err)
+	}
+	req.Header.Add("Authorization", headerStr)
+	c := newClient()
+	resp, err := c.Do(req)
+	if err != nil {
+		return fmt.Errorf("PlayUri: client: %w", err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("PlayUri: read body: %w", err)
+	}
+
+	var respStruct PlayerErrorResponse
+	err = json.Unmarshal(body, &respStruct)
+	if err != nil {
+		return fmt.Errorf("PlayUri: json: unmarshal: %w", err)
+	}
+	return fmt.Errorf("PlayUri: %w", &respStruct.Error)
 }
@@ -0,0 +1,48 @@
+package spotify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Returns the spotify id of the logged in user
+func (SpotifyPlayer) CurrentUserId(accessToken string) (string, error) {
+	err := validTokenFormat(accessToken)
+	if err != nil {
+		return "", fmt.Errorf("CurrentUserId: %w", err)
+	}
+	apiUrl := "https://api.spotify.com/v1/me"
+	headerStr := "Bearer " + accessToken
+
+	req, err := http.NewRequest("GET", apiUrl, nil)
+	if err != nil {
+		return "", fmt.Errorf("CurrentUserId: req: %w", err)
+	}
+	req.Header.Add("Authorization", headerStr)
+	c := newClient()
+	resp, err := c.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("CurrentUserId: client: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", errors.New("CurrentUserId: Http status not successful: " + resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("CurrentUserId: read body: %w", err)
+	}
+
+	respStruct := struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"display_name"`
+	}{}
+	err = json.Unmarshal(body, &respStruct)
+	if err != nil {
+		return "", fmt.Errorf("CurrentUserId: json: unmarshal: %w", err)
+	}
+	return respStruct.ID, nil
+}
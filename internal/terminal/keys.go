@@ -0,0 +1,387 @@
+package terminal
+
+import (
+	"bytes"
+	"neofy/internal/consts"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Key the user pressed, text they pasted or a click
+type KeyEvent struct {
+	Code  rune // Typed rune or one of the consts key codes
+	Mods  consts.Modifier
+	Mouse MouseEvent // Set when Code is consts.MOUSE
+	Paste string     // Text of a bracketed paste, Code is consts.PASTE
+}
+
+type MouseButton int
+
+const (
+	MouseLeft MouseButton = iota
+	MouseMiddle
+	MouseRight
+	MouseWheelUp
+	MouseWheelDown
+)
+
+// Press of a mouse button or a turn of the wheel, X & Y are the cell from the top left starting at 0
+type MouseEvent struct {
+	Button MouseButton
+	X      int
+	Y      int
+}
+
+// The key as a single rune with the modifiers folded in, the way modes & keymaps see keys
+func (e KeyEvent) Rune() rune {
+	return consts.WithMods(e.Code, e.Mods)
+}
+
+// How long to wait for the rest of a sequence before ESC is taken as the Esc key
+const escTimeout = 50 * time.Millisecond
+
+// How long a paste waits for more before what came is taken as the whole paste.
+// NOTE: A paste can lose it's end when it's cut short, the keys after it would be pasted too
+const pasteTimeout = time.Second
+
+// Longest paste sent as one event, longer ones are sent in parts
+const maxPaste = 1 << 20
+
+var (
+	keys      chan KeyEvent
+	startKeys sync.Once
+	unread    []KeyEvent // Keys that were put back, read before keys
+)
+
+// Keys the user typed, stdin is read by one goroutine so the app can wait on keys & other events together
+func Keys() <-chan KeyEvent {
+	startKeys.Do(func() {
+		keys = make(chan KeyEvent)
+		input := make(chan []byte)
+		go func() {
+			defer RestoreOnPanic()
+			readInput(input)
+		}()
+		go func() {
+			defer RestoreOnPanic()
+			decodeKeys(input, keys)
+		}()
+	})
+	return keys
+}
+
+// Puts key back so it's the next key ReadKeyEvent returns
+func UnreadKey(key KeyEvent) {
+	unread = append(unread, key)
+}
+
+func ReadKeyEvent() KeyEvent {
+	if len(unread) > 0 {
+		key := unread[0]
+		unread = unread[1:]
+		return key
+	}
+	return <-Keys()
+}
+
+func ReadInputKey() rune {
+	return ReadKeyEvent().Rune()
+}
+
+// Sends stdin as it's read, a read can hold many keys or part of one
+func readInput(input chan<- []byte) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			close(input)
+			return
+		}
+		input <- slices.Clone(buf[:n])
+	}
+}
+
+// Decodes the bytes from input into keys, sequences can be split across reads
+func decodeKeys(input <-chan []byte, keys chan<- KeyEvent) {
+	buf := []byte{}
+	for {
+		// NOTE: Anything left in buf is part of a key, a paste gets longer than a key to send the rest
+		timedOut := false
+		if len(buf) == 0 {
+			b, ok := <-input
+			if !ok {
+				return
+			}
+			buf = append(buf, b...)
+		} else {
+			wait := escTimeout
+			if bytes.HasPrefix(buf, pasteStart) {
+				wait = pasteTimeout
+			}
+			select {
+			case b, ok := <-input:
+				if !ok {
+					return
+				}
+				buf = append(buf, b...)
+			case <-time.After(wait):
+				timedOut = true
+			}
+		}
+		if len(buf) > maxPaste && bytes.HasPrefix(buf, pasteStart) && !bytes.Contains(buf, pasteEnd) {
+			// A paste this long is sent in parts, the bytes that could be the start of it's end are kept
+			cut := len(buf) - len(pasteEnd) + 1
+			keys <- KeyEvent{Code: consts.PASTE, Paste: string(buf[len(pasteStart):cut])}
+			buf = append(slices.Clone(pasteStart), buf[cut:]...)
+			continue
+		}
+		for len(buf) > 0 {
+			key, n := decodeKey(buf, timedOut)
+			if n == 0 {
+				break
+			}
+			buf = buf[n:]
+			timedOut = false
+			if key.Code != consts.NOTHINGKEY {
+				keys <- key
+			}
+		}
+	}
+}
+
+var (
+	pasteStart = []byte("\x1b[200~")
+	pasteEnd   = []byte("\x1b[201~")
+)
+
+// Decodes the key at the start of sent once nothing more comes, n is how many bytes it used.
+// Lets keymap notation be checked against what the terminal sends
+func DecodeKey(sent []byte) (KeyEvent, int) {
+	return decodeKey(sent, true)
+}
+
+// Decodes the key at the start of buf, n is 0 when buf only holds part of a key.
+// Once timedOut the start of buf is decoded even if more of it could come
+func decodeKey(buf []byte, timedOut bool) (KeyEvent, int) {
+	if buf[0] == 0x1b {
+		return decodeEscape(buf, timedOut)
+	}
+	if buf[0] < 0x20 || buf[0] == 0x7f {
+		return KeyEvent{Code: controlKey(buf[0])}, 1
+	}
+	if !utf8.FullRune(buf) && !timedOut {
+		return KeyEvent{}, 0
+	}
+	r, size := utf8.DecodeRune(buf)
+	if r == utf8.RuneError {
+		return KeyEvent{Code: consts.NOTHINGKEY}, size
+	}
+	return KeyEvent{Code: r}, size
+}
+
+func controlKey(b byte) rune {
+	switch b {
+	case 3: //CTRL-C
+		return consts.CONTROLCASCII
+	case 4: //CTRL-D
+		return consts.CONTROL_D
+	case 6: //CTRL-F
+		return consts.CONTROL_F
+	case 8, 127: //CTRL-H & BACKSPACE
+		return consts.BACKSPACE
+	case 9: //TAB
+		return consts.TAB
+	case 10, 13: //ENTER
+		return '\r'
+	case 19: //CTRL-S
+		return consts.CONTROL_S
+	case 21: //CTRL-U
+		return consts.CONTROL_U
+	}
+	if b >= 1 && b <= 26 {
+		return consts.WithMods(rune('a'+b-1), consts.ModCtrl)
+	}
+	return consts.NOTHINGKEY
+}
+
+// Decodes ESC on it's own, CSI (ESC [) & SS3 (ESC O) sequences & Alt with a key (ESC x)
+func decodeEscape(buf []byte, timedOut bool) (KeyEvent, int) {
+	if len(buf) == 1 {
+		if timedOut {
+			return KeyEvent{Code: consts.ESC}, 1
+		}
+		return KeyEvent{}, 0
+	}
+	switch buf[1] {
+	case '[':
+		key, n := decodeCSI(buf, timedOut)
+		if n > 0 {
+			return key, n
+		}
+	case 'O':
+		if len(buf) >= 3 {
+			return KeyEvent{Code: ss3Key(buf[2])}, 3
+		}
+	case 0x1b:
+		return KeyEvent{Code: consts.ESC}, 1
+	default:
+		key, n := decodeKey(buf[1:], timedOut)
+		if n == 0 {
+			return KeyEvent{}, 0
+		}
+		key.Mods |= consts.ModAlt
+		return key, n + 1
+	}
+	// The sequence isn't done yet, or it never will be & it was a typed ESC
+	if timedOut {
+		return KeyEvent{Code: consts.ESC}, 1
+	}
+	return KeyEvent{}, 0
+}
+
+// Keys sent as ESC O <b> by terminals in application mode
+func ss3Key(b byte) rune {
+	switch b {
+	case 'A':
+		return consts.UP_ARROW
+	case 'B':
+		return consts.DOWN_ARROW
+	case 'C':
+		return consts.RIGHT_ARROW
+	case 'D':
+		return consts.LEFT_ARROW
+	case 'H':
+		return consts.HOME_KEY
+	case 'F':
+		return consts.END_KEY
+	case 'P', 'Q', 'R', 'S':
+		return consts.F1 + rune(b-'P')
+	}
+	return consts.NOTHINGKEY
+}
+
+// Decodes ESC [ <params> <final>, params are numbers split by ; (1;5A is Ctrl-Up).
+// A paste without it's end is only decoded once timedOut
+func decodeCSI(buf []byte, timedOut bool) (KeyEvent, int) {
+	end := 2
+	for end < len(buf) && buf[end] >= 0x20 && buf[end] <= 0x3f {
+		end++
+	}
+	if end == len(buf) {
+		return KeyEvent{}, 0
+	}
+	if buf[end] < 0x40 || buf[end] > 0x7e {
+		// Not a CSI sequence, ESC [ is dropped & the bytes after it are read as typed keys
+		return KeyEvent{Code: consts.NOTHINGKEY}, 2
+	}
+	n := end + 1
+	params := strings.Split(string(buf[2:end]), ";")
+	if bytes.HasPrefix(buf, pasteStart) {
+		pasteLen := bytes.Index(buf, pasteEnd)
+		if pasteLen < 0 && timedOut {
+			return KeyEvent{Code: consts.PASTE, Paste: string(buf[len(pasteStart):])}, len(buf)
+		}
+		if pasteLen < 0 {
+			return KeyEvent{}, 0
+		}
+		text := string(buf[len(pasteStart):pasteLen])
+		return KeyEvent{Code: consts.PASTE, Paste: text}, pasteLen + len(pasteEnd)
+	}
+	if buf[2] == '<' {
+		return decodeMouse(params, buf[end] == 'M'), n
+	}
+	// Modifiers are sent as 1 + Shift (1), Alt (2) & Ctrl (4)
+	mods := consts.Modifier(0)
+	if len(params) >= 2 {
+		m, err := strconv.Atoi(params[1])
+		if err == nil && m > 1 {
+			mods = consts.Modifier(m-1) & (consts.ModShift | consts.ModAlt | consts.ModCtrl)
+		}
+	}
+	code := rune(consts.NOTHINGKEY)
+	switch buf[end] {
+	case 'A', 'B', 'C', 'D', 'H', 'F', 'P', 'Q', 'R', 'S':
+		code = ss3Key(buf[end])
+	case 'Z':
+		code = consts.TAB
+		mods |= consts.ModShift
+	case '~':
+		code = tildeKey(params[0])
+	}
+	return KeyEvent{Code: code, Mods: mods}, n
+}
+
+// Keys sent as ESC [ <num> ~
+func tildeKey(num string) rune {
+	switch num {
+	case "1", "7":
+		return consts.HOME_KEY
+	case "2":
+		return consts.INSERT_KEY
+	case "3":
+		return consts.DEL_KEY
+	case "4", "8":
+		return consts.END_KEY
+	case "5":
+		return consts.PAGE_UP
+	case "6":
+		return consts.PAGE_DOWN
+	case "11", "12", "13", "14", "15":
+		n, _ := strconv.Atoi(num)
+		return consts.F1 + rune(n-11)
+	case "17", "18", "19", "20", "21":
+		n, _ := strconv.Atoi(num)
+		return consts.F6 + rune(n-17)
+	case "23", "24":
+		n, _ := strconv.Atoi(num)
+		return consts.F11 + rune(n-23)
+	}
+	return consts.NOTHINGKEY
+}
+
+// Decodes an SGR mouse report (ESC [ < button ; x ; y M), only presses & the wheel are kept
+func decodeMouse(params []string, pressed bool) KeyEvent {
+	ignored := KeyEvent{Code: consts.NOTHINGKEY}
+	if len(params) != 3 || !pressed {
+		return ignored
+	}
+	nums := [3]int{}
+	for i, p := range params {
+		num, err := strconv.Atoi(strings.TrimPrefix(p, "<"))
+		if err != nil {
+			return ignored
+		}
+		nums[i] = num
+	}
+	// NOTE: 4, 8 & 16 are Shift, Alt & Ctrl, 32 is a drag
+	code := nums[0]
+	if code&32 != 0 {
+		return ignored
+	}
+	button := MouseButton(code & 3)
+	if code&64 != 0 {
+		button = MouseWheelUp + MouseButton(code&1)
+	} else if button > MouseRight {
+		return ignored
+	}
+	mods := consts.Modifier(0)
+	if code&4 != 0 {
+		mods |= consts.ModShift
+	}
+	if code&8 != 0 {
+		mods |= consts.ModAlt
+	}
+	if code&16 != 0 {
+		mods |= consts.ModCtrl
+	}
+	return KeyEvent{
+		Code:  consts.MOUSE,
+		Mods:  mods,
+		Mouse: MouseEvent{Button: button, X: nums[1] - 1, Y: nums[2] - 1},
+	}
+}
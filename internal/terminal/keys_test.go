@@ -0,0 +1,187 @@
+package terminal
+
+import (
+	"neofy/internal/consts"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeKey(t *testing.T) {
+	alt := consts.ModAlt
+	ctrl := consts.ModCtrl
+	shift := consts.ModShift
+	tests := []struct {
+		name     string
+		buf      string
+		timedOut bool
+		want     KeyEvent
+		n        int // 0 when decodeKey should wait for more
+	}{
+		{"rune", "a", false, KeyEvent{Code: 'a'}, 1},
+		{"utf8", "é", false, KeyEvent{Code: 'é'}, 2},
+		{"part of utf8", "\xc3", false, KeyEvent{}, 0},
+		{"first of many", "ab", false, KeyEvent{Code: 'a'}, 1},
+		{"ctrl", "\x01", false, KeyEvent{Code: consts.WithMods('a', ctrl)}, 1},
+		{"ctrl-c", "\x03", false, KeyEvent{Code: consts.CONTROLCASCII}, 1},
+		{"lone esc waits", "\x1b", false, KeyEvent{}, 0},
+		{"lone esc timed out", "\x1b", true, KeyEvent{Code: consts.ESC}, 1},
+		{"esc esc", "\x1b\x1b", false, KeyEvent{Code: consts.ESC}, 1},
+		{"alt", "\x1bx", false, KeyEvent{Code: 'x', Mods: alt}, 2},
+		{"alt ctrl", "\x1b\x01", false, KeyEvent{Code: consts.WithMods('a', ctrl), Mods: alt}, 2},
+		{"alt utf8", "\x1bé", false, KeyEvent{Code: 'é', Mods: alt}, 3},
+		{"part of csi", "\x1b[1;5", false, KeyEvent{}, 0},
+		{"part of csi timed out", "\x1b[1;5", true, KeyEvent{Code: consts.ESC}, 1},
+		{"part of ss3", "\x1bO", false, KeyEvent{}, 0},
+		{"csi", "\x1b[A", false, KeyEvent{Code: consts.UP_ARROW}, 3},
+		{"csi ctrl", "\x1b[1;5A", false, KeyEvent{Code: consts.UP_ARROW, Mods: ctrl}, 6},
+		{"csi shift", "\x1b[1;2B", false, KeyEvent{Code: consts.DOWN_ARROW, Mods: shift}, 6},
+		{"csi alt", "\x1b[1;3C", false, KeyEvent{Code: consts.RIGHT_ARROW, Mods: alt}, 6},
+		{"csi ctrl shift alt", "\x1b[1;8D", false, KeyEvent{Code: consts.LEFT_ARROW, Mods: ctrl | shift | alt}, 6},
+		{"csi tilde", "\x1b[5~", false, KeyEvent{Code: consts.PAGE_UP}, 4},
+		{"csi tilde ctrl", "\x1b[3;5~", false, KeyEvent{Code: consts.DEL_KEY, Mods: ctrl}, 6},
+		{"csi function", "\x1b[20~", false, KeyEvent{Code: consts.F9}, 5},
+		{"csi back tab", "\x1b[Z", false, KeyEvent{Code: consts.TAB, Mods: shift}, 3},
+		{"csi unknown", "\x1b[99~", false, KeyEvent{Code: consts.NOTHINGKEY}, 5},
+		{"not csi", "\x1b[\x01", false, KeyEvent{Code: consts.NOTHINGKEY}, 2},
+		{"ss3", "\x1bOH", false, KeyEvent{Code: consts.HOME_KEY}, 3},
+		{"ss3 function", "\x1bOS", false, KeyEvent{Code: consts.F4}, 3},
+		{"paste", "\x1b[200~a\x1b[Ab\x1b[201~c", false, KeyEvent{Code: consts.PASTE, Paste: "a\x1b[Ab"}, 17},
+		{"empty paste", "\x1b[200~\x1b[201~", false, KeyEvent{Code: consts.PASTE}, 12},
+		{"part of paste", "\x1b[200~abc", false, KeyEvent{}, 0},
+		{"part of paste timed out", "\x1b[200~abc", true, KeyEvent{Code: consts.PASTE, Paste: "abc"}, 9},
+		{"click", "\x1b[<0;10;5M", false, KeyEvent{Code: consts.MOUSE, Mouse: MouseEvent{Button: MouseLeft, X: 9, Y: 4}}, 10},
+		{"right click", "\x1b[<2;1;1M", false, KeyEvent{Code: consts.MOUSE, Mouse: MouseEvent{Button: MouseRight}}, 9},
+		{"ctrl click", "\x1b[<16;3;4M", false, KeyEvent{Code: consts.MOUSE, Mods: ctrl, Mouse: MouseEvent{Button: MouseLeft, X: 2, Y: 3}}, 10},
+		{"wheel up", "\x1b[<64;1;2M", false, KeyEvent{Code: consts.MOUSE, Mouse: MouseEvent{Button: MouseWheelUp, Y: 1}}, 10},
+		{"wheel down", "\x1b[<65;1;2M", false, KeyEvent{Code: consts.MOUSE, Mouse: MouseEvent{Button: MouseWheelDown, Y: 1}}, 10},
+		{"release", "\x1b[<0;10;5m", false, KeyEvent{Code: consts.NOTHINGKEY}, 10},
+		{"drag", "\x1b[<32;10;5M", false, KeyEvent{Code: consts.NOTHINGKEY}, 11},
+		{"part of mouse", "\x1b[<0;10", false, KeyEvent{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, n := decodeKey([]byte(tt.buf), tt.timedOut)
+			if n != tt.n {
+				t.Fatalf("decodeKey(%q, %v) used %d bytes, want %d", tt.buf, tt.timedOut, n, tt.n)
+			}
+			if n > 0 && key != tt.want {
+				t.Errorf("decodeKey(%q, %v) = %+v, want %+v", tt.buf, tt.timedOut, key, tt.want)
+			}
+		})
+	}
+}
+
+// ESC is the Esc key once nothing follows it in time, a key right after it is Alt with that key
+func TestDecodeKeysEscTimeout(t *testing.T) {
+	input := make(chan []byte)
+	keys := make(chan KeyEvent)
+	go decodeKeys(input, keys)
+	defer close(input)
+	read := func() KeyEvent {
+		t.Helper()
+		select {
+		case key := <-keys:
+			return key
+		case <-time.After(time.Second):
+			t.Fatal("no key was decoded")
+			return KeyEvent{}
+		}
+	}
+
+	input <- []byte("\x1b")
+	input <- []byte("x")
+	if key := read(); key.Code != 'x' || key.Mods != consts.ModAlt {
+		t.Errorf("ESC then x = %+v, want <A-x>", key)
+	}
+
+	input <- []byte("\x1b")
+	if key := read(); key.Code != consts.ESC {
+		t.Errorf("ESC on it's own = %+v, want <Esc>", key)
+	}
+
+	// A sequence split across reads is still one key
+	input <- []byte("\x1b[1;")
+	input <- []byte("5A")
+	if key := read(); key.Code != consts.UP_ARROW || key.Mods != consts.ModCtrl {
+		t.Errorf("split CSI = %+v, want <C-Up>", key)
+	}
+
+	// A paste waits for it's end however long it takes
+	input <- []byte("\x1b[200~ab")
+	time.Sleep(2 * escTimeout)
+	input <- []byte("c\x1b[201~")
+	if key := read(); key.Code != consts.PASTE || key.Paste != "abc" {
+		t.Errorf("slow paste = %+v, want the pasted abc", key)
+	}
+}
+
+// A paste that lost it's end is cut off once nothing more comes, the keys after it aren't swallowed
+func TestDecodeKeysPasteWithoutEnd(t *testing.T) {
+	input := make(chan []byte)
+	keys := make(chan KeyEvent)
+	go decodeKeys(input, keys)
+	defer close(input)
+
+	input <- []byte("\x1b[200~abc")
+	select {
+	case key := <-keys:
+		if key.Code != consts.PASTE || key.Paste != "abc" {
+			t.Errorf("paste without an end = %+v, want the pasted abc", key)
+		}
+	case <-time.After(pasteTimeout + time.Second):
+		t.Fatal("a paste without an end was never sent")
+	}
+	input <- []byte("\x03")
+	select {
+	case key := <-keys:
+		if key.Code != consts.CONTROLCASCII {
+			t.Errorf("key after the paste = %+v, want <C-c>", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the key after the paste was swallowed")
+	}
+}
+
+// A paste longer than maxPaste comes in parts that add up to all of it
+func TestDecodeKeysLongPaste(t *testing.T) {
+	input := make(chan []byte)
+	keys := make(chan KeyEvent)
+	go decodeKeys(input, keys)
+	defer close(input)
+
+	text := strings.Repeat("abcdefgh", maxPaste/4)
+	go func() {
+		input <- []byte("\x1b[200~")
+		sent := []byte(text + "\x1b[201~")
+		for len(sent) > 0 {
+			n := min(len(sent), 4096)
+			input <- sent[:n]
+			sent = sent[n:]
+		}
+		input <- []byte("x")
+	}()
+	pasted := ""
+	parts := 0
+	for {
+		select {
+		case key := <-keys:
+			if key.Code == 'x' {
+				if pasted != text {
+					t.Errorf("long paste = %d bytes in %d parts, want %d bytes", len(pasted), parts, len(text))
+				}
+				if parts < 2 {
+					t.Errorf("long paste came in %d parts, want it split", parts)
+				}
+				return
+			}
+			if key.Code != consts.PASTE {
+				t.Fatalf("long paste = %+v, want only pastes", key)
+			}
+			parts++
+			pasted += key.Paste
+		case <-time.After(5 * time.Second):
+			t.Fatalf("long paste stopped after %d bytes", len(pasted))
+		}
+	}
+}
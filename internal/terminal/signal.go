@@ -0,0 +1,23 @@
+//go:build !windows
+
+package terminal
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Sends when the terminal is resized (SIGWINCH)
+func Resizes() <-chan os.Signal {
+	resizes := make(chan os.Signal, 1)
+	signal.Notify(resizes, syscall.SIGWINCH)
+	return resizes
+}
+
+// Sends when the app is asked to stop (SIGTERM, SIGHUP or SIGINT)
+func Stops() <-chan os.Signal {
+	stops := make(chan os.Signal, 1)
+	signal.Notify(stops, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGINT)
+	return stops
+}
@@ -0,0 +1,19 @@
+package terminal
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// TODO: Windows has no SIGWINCH, the console has to be polled for its size
+func Resizes() <-chan os.Signal {
+	return nil
+}
+
+// Sends when the app is asked to stop (Ctrl-Break or closing the console)
+func Stops() <-chan os.Signal {
+	stops := make(chan os.Signal, 1)
+	signal.Notify(stops, syscall.SIGTERM, syscall.SIGINT)
+	return stops
+}
@@ -1,83 +1,26 @@
 package terminal
 
 import (
-	"bufio"
 	"fmt"
-	"neofy/internal/consts"
-	"os"
+	"log/slog"
 	"os/exec"
 	"runtime"
-	"unicode"
-	"unicode/utf8"
+	"runtime/debug"
 
 	"golang.org/x/term"
 )
 
-func ReaderBytes() []byte {
-	STDINFILE := os.Stdin
-	reader := bufio.NewReader(STDINFILE)
-	inputBytes := make([]byte, 3)
-	reader.Read(inputBytes)
-	return inputBytes
-}
-
-func ReadInputKey() rune {
-	inputBytes := ReaderBytes()
-	inputRune, _ := utf8.DecodeRune(inputBytes)
-	if unicode.IsControl(inputRune) {
-		switch inputRune {
-		case 3: //CTRL-C
-			return consts.CONTROLCASCII
-		case 27: //First byte is A CTRL byte
-			returnRune, _ := utf8.DecodeRune(inputBytes[2:])
-			switch returnRune {
-			case 53: //PAGE UP
-				return consts.PAGE_UP
-			case 54: //PAGE DOWN
-				return consts.PAGE_DOWN
-			case 68: //LEFT ARROW
-				return consts.LEFT_ARROW
-			case 67: //RIGHT ARROW
-				return consts.RIGHT_ARROW
-			case 66: //DOWN ARROW
-				return consts.DOWN_ARROW
-			case 65: //UP ARROW
-				return consts.UP_ARROW
-			case 72: //HOME KEY
-				return consts.HOME_KEY
-			case 70: //END KEY
-				return consts.END_KEY
-			case 51: //DEL KEY
-				return consts.DEL_KEY
-			case 0: //ESC KEY
-				return consts.ESC
-			}
-			return consts.NOTHINGKEY
-		case 127: //BACKSPACE
-			return consts.BACKSPACE
-		case 13: //ENTER
-			return '\r'
-		case 19: //CTRL-S
-			return consts.CONTROL_S
-		case 6: //CTRL-F
-			return consts.CONTROL_F
-		case 4: //CTRL-D
-			return consts.CONTROL_D
-		case 21: //CTRL-U
-			return consts.CONTROL_U
-		default:
-			return consts.NOTHINGKEY
-		}
-	}
-	return inputRune
-}
-
-func Quit(l AppTerm) {
-	l.CloseTerminal()
-	fmt.Print("\033[2J")
-	fmt.Print("\033[H")
-	defer os.Exit(0)
-}
+const (
+	enterAltScreen = "\033[?1049h" // Saves the user's screen & scrollback
+	leaveAltScreen = "\033[?1049l"
+	hideCursor     = "\033[?25l"
+	showCursor     = "\033[?25h"
+	enablePaste    = "\033[?2004h" // Pasted text comes between ESC[200~ & ESC[201~
+	disablePaste   = "\033[?2004l"
+	enableMouse    = "\033[?1000h\033[?1006h" // Clicks & the wheel, reported as ESC[<b;x;yM
+	disableMouse   = "\033[?1006l\033[?1000l"
+	resetStyle     = "\033[0m"
+)
 
 type AppTerm interface {
 	InitTerminal()
@@ -91,6 +34,7 @@ type LinuxTerm struct {
 
 func (l *LinuxTerm) InitTerminal() {
 	l.enableRawMode()
+	fmt.Print(enterAltScreen + hideCursor + enablePaste + enableMouse)
 }
 
 func (l *LinuxTerm) GetTerminalSize() (int, int, error) {
@@ -101,8 +45,14 @@ func (l *LinuxTerm) GetTerminalSize() (int, int, error) {
 	return width, height, nil
 }
 
+// Puts the terminal back the way the user had it, does nothing once it's closed
 func (l *LinuxTerm) CloseTerminal() {
+	if l.OldState == nil {
+		return
+	}
+	fmt.Print(disableMouse + disablePaste + resetStyle + showCursor + leaveAltScreen)
 	l.disableRawMode()
+	l.OldState = nil
 }
 
 func (l *LinuxTerm) enableRawMode() {
@@ -139,9 +89,31 @@ func Openbrowser(url string) error {
 	return nil
 }
 
+// Terminal the app is drawing in, nil until InitAppTerm
+var active AppTerm
+
 // TODO: Handle different environments
 func InitAppTerm() AppTerm {
 	newTerm := LinuxTerm{}
 	newTerm.InitTerminal()
+	active = &newTerm
 	return &newTerm
 }
+
+// Closes the terminal the app is drawing in, if there is one.
+// NOTE: Deferred before the app starts so a panic leaves the terminal usable
+func Restore() {
+	if active != nil {
+		active.CloseTerminal()
+	}
+}
+
+// Deferred first thing in the goroutines the app starts, only the main goroutine defers Restore.
+// The terminal is put back & the panic carries on so it's still printed
+func RestoreOnPanic() {
+	if r := recover(); r != nil {
+		slog.Error("panic", "panic", r, "stack", string(debug.Stack()))
+		Restore()
+		panic(r)
+	}
+}
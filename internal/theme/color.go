@@ -0,0 +1,230 @@
+package theme
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// How many colors the terminal can show, colors are brought down to what it supports
+type ColorMode int
+
+const (
+	NoColor ColorMode = iota
+	Colors16
+	Colors256
+	TrueColor
+)
+
+// Finds what the terminal supports from NO_COLOR, COLORTERM & TERM
+func DetectColorMode() ColorMode {
+	// NOTE: See no-color.org, an empty NO_COLOR doesn't count
+	if os.Getenv("NO_COLOR") != "" {
+		return NoColor
+	}
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return TrueColor
+	}
+	// Windows Terminal supports true color but doesn't set COLORTERM
+	if os.Getenv("WT_SESSION") != "" {
+		return TrueColor
+	}
+	term := os.Getenv("TERM")
+	switch {
+	case term == "dumb":
+		return NoColor
+	case strings.HasSuffix(term, "-direct"):
+		return TrueColor
+	case strings.Contains(term, "256color"):
+		return Colors256
+	}
+	return Colors16
+}
+
+// Reads the colors setting of the config, auto detects it
+func ParseColorMode(str string) (ColorMode, error) {
+	switch str {
+	case "auto":
+		return DetectColorMode(), nil
+	case "none":
+		return NoColor, nil
+	case "16":
+		return Colors16, nil
+	case "256":
+		return Colors256, nil
+	case "truecolor":
+		return TrueColor, nil
+	}
+	return NoColor, errors.New("must be auto, none, 16, 256 or truecolor")
+}
+
+type colorKind int
+
+const (
+	defaultColor colorKind = iota // The terminal's own color
+	ansiColor                     // 0-15, the terminal's palette
+	indexedColor                  // 0-255
+	rgbColor
+)
+
+type Color struct {
+	fallback int // Palette index (1-16, 0 when there is none) used instead with 16 colors
+	kind     colorKind
+	value    int // Palette index or 0xRRGGBB
+}
+
+// Names of the 16 palette colors, the bright colors are the light version of each one
+var colorNames = map[string]int{
+	"black":   0,
+	"red":     1,
+	"green":   2,
+	"yellow":  3,
+	"blue":    4,
+	"magenta": 5,
+	"cyan":    6,
+	"white":   7,
+}
+
+// Reads a color name (blue, bright_blue, default), a 256 color number (208) or a hex color (#458588).
+// A 256 or hex color can be followed by the name to use with 16 colors (#458588/blue), otherwise
+// the closest one is picked
+func ParseColor(str string) (Color, error) {
+	str, fallbackName, hasFallback := strings.Cut(str, "/")
+	if hasFallback {
+		c, err := ParseColor(str)
+		if err != nil {
+			return c, err
+		}
+		fallback, err := ParseColor(fallbackName)
+		if err != nil || fallback.kind != ansiColor || c.kind == ansiColor || c.kind == defaultColor {
+			return c, errors.New("only 256 & hex colors can have a fallback & it must be a color name, not " + str + "/" + fallbackName)
+		}
+		c.fallback = fallback.value + 1
+		return c, nil
+	}
+	if str == "default" {
+		return Color{}, nil
+	}
+	if hex, ok := strings.CutPrefix(str, "#"); ok {
+		rgb, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return Color{}, errors.New("hex color must be #rrggbb, not " + str)
+		}
+		return Color{kind: rgbColor, value: int(rgb)}, nil
+	}
+	if num, err := strconv.Atoi(str); err == nil {
+		if num < 0 || num > 255 {
+			return Color{}, errors.New("color number must be between 0 & 255")
+		}
+		return Color{kind: indexedColor, value: num}, nil
+	}
+	name, bright := strings.CutPrefix(str, "bright_")
+	index, ok := colorNames[name]
+	if !ok {
+		return Color{}, errors.New("unknown color " + str)
+	}
+	if bright {
+		index += 8
+	}
+	return Color{kind: ansiColor, value: index}, nil
+}
+
+// SGR parameter of c as a foreground (base 30) or background (base 40) in mode, "" for the default color
+func (c Color) sgr(mode ColorMode, base int) string {
+	if c.kind == defaultColor || mode == NoColor {
+		return ""
+	}
+	kind, value := c.kind, c.value
+	if mode == Colors16 && c.fallback > 0 {
+		kind, value = ansiColor, c.fallback-1
+	}
+	if kind == rgbColor && mode < TrueColor {
+		kind, value = indexedColor, rgbTo256(value)
+	}
+	if kind == indexedColor && value < 16 {
+		kind = ansiColor
+	}
+	if kind == indexedColor && mode < Colors256 {
+		kind, value = ansiColor, nearestAnsi(rgbOf256(value))
+	}
+	switch kind {
+	case ansiColor:
+		if value >= 8 {
+			return strconv.Itoa(base + 60 + value - 8)
+		}
+		return strconv.Itoa(base + value)
+	case indexedColor:
+		return strconv.Itoa(base+8) + ";5;" + strconv.Itoa(value)
+	}
+	r, g, b := value>>16, value>>8&0xff, value&0xff
+	return strconv.Itoa(base+8) + ";2;" + strconv.Itoa(r) + ";" + strconv.Itoa(g) + ";" + strconv.Itoa(b)
+}
+
+// xterm's default palette, terminals can change it so it's only used to pick the closest color
+var ansiPalette = [16]int{
+	0x000000, 0xcd0000, 0x00cd00, 0xcdcd00, 0x0000ee, 0xcd00cd, 0x00cdcd, 0xe5e5e5,
+	0x7f7f7f, 0xff0000, 0x00ff00, 0xffff00, 0x5c5cff, 0xff00ff, 0x00ffff, 0xffffff,
+}
+
+// Levels of each channel in the 6x6x6 color cube (16-231)
+var cubeLevels = [6]int{0, 95, 135, 175, 215, 255}
+
+func rgbOf256(index int) int {
+	switch {
+	case index < 16:
+		return ansiPalette[index]
+	case index < 232:
+		i := index - 16
+		return cubeLevels[i/36]<<16 | cubeLevels[i/6%6]<<8 | cubeLevels[i%6]
+	}
+	gray := 8 + (index-232)*10
+	return gray<<16 | gray<<8 | gray
+}
+
+// Closest color of the cube or the gray ramp (232-255), the palette is left out since terminals change it
+func rgbTo256(rgb int) int {
+	r, g, b := rgb>>16, rgb>>8&0xff, rgb&0xff
+	cube := 16 + 36*nearestLevel(r) + 6*nearestLevel(g) + nearestLevel(b)
+	gray := 232 + min(max((r+g+b)/3-8+5, 0)/10, 23)
+	if distance(rgb, rgbOf256(gray)) < distance(rgb, rgbOf256(cube)) {
+		return gray
+	}
+	return cube
+}
+
+func nearestLevel(v int) int {
+	best := 0
+	for i, level := range cubeLevels {
+		if abs(v-level) < abs(v-cubeLevels[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+func nearestAnsi(rgb int) int {
+	best := 0
+	for i, c := range ansiPalette {
+		if distance(rgb, c) < distance(rgb, ansiPalette[best]) {
+			best = i
+		}
+	}
+	return best
+}
+
+// Squared distance between 2 colors, good enough to pick the closest one
+func distance(a, b int) int {
+	dr := a>>16 - b>>16
+	dg := a>>8&0xff - b>>8&0xff
+	db := a&0xff - b&0xff
+	return dr*dr + dg*dg + db*db
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,200 @@
+package theme
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+type Style struct {
+	Bg        Color
+	Bold      bool
+	Fg        Color
+	Reverse   bool
+	Underline bool
+}
+
+// Reads a style written as "<fg> on <bg>" with bold, underline or reverse in any order
+// (black on blue, bold #fabd2f, on bright_black). none is no style at all
+func ParseStyle(str string) (Style, error) {
+	s := Style{}
+	if str == "none" {
+		return s, nil
+	}
+	fields := strings.Fields(str)
+	if len(fields) == 0 {
+		return s, errors.New("empty style, use none for no style")
+	}
+	hasFg := false
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "bold":
+			s.Bold = true
+		case "underline":
+			s.Underline = true
+		case "reverse":
+			s.Reverse = true
+		case "on":
+			if i+1 == len(fields) {
+				return s, errors.New("on must be followed by a color")
+			}
+			i++
+			bg, err := ParseColor(fields[i])
+			if err != nil {
+				return s, err
+			}
+			s.Bg = bg
+		default:
+			if hasFg {
+				return s, errors.New("only 1 foreground color, use on for the background")
+			}
+			fg, err := ParseColor(fields[i])
+			if err != nil {
+				return s, err
+			}
+			s.Fg = fg
+			hasFg = true
+		}
+	}
+	return s, nil
+}
+
+// SGR parameters of s in mode (1;30;44), used as \033[<params>m.
+// NOTE: Without colors a background becomes reverse video so cursors & badges can still be seen
+func (s Style) SGR(mode ColorMode) string {
+	params := []string{}
+	if s.Bold {
+		params = append(params, "1")
+	}
+	if s.Underline {
+		params = append(params, "4")
+	}
+	if s.Reverse || (mode == NoColor && s.Bg.kind != defaultColor) {
+		params = append(params, "7")
+	}
+	if fg := s.Fg.sgr(mode, 30); fg != "" {
+		params = append(params, fg)
+	}
+	if bg := s.Bg.sgr(mode, 40); bg != "" {
+		params = append(params, bg)
+	}
+	return strings.Join(params, ";")
+}
+
+// Style of each part of the app as written in the config, see ParseStyle
+type Styles struct {
+	Border   string          // The - & | around panes
+	Cursor   string          // Row under the cursor
+	Error    string          // Messages in the status line by level
+	Header   string          // App name & the titles of the panes
+	Info     string          // Also used by the spinner
+	Modes    map[rune]string // Mode badge in the header by ShortDisplay
+	Playing  string          // The track that is playing
+	Selected string          // The playlist that is loaded
+	Success  string
+	Warning  string
+}
+
+// Themes that come with the app, the hex colors are brought down for terminals without true color
+// & use their fallback with 16 colors
+var Bundled = map[string]Styles{
+	"default": {
+		Border: "none",
+		Cursor: "on bright_black",
+		Error:  "bold red",
+		Header: "none",
+		Info:   "none",
+		Modes: map[rune]string{
+			'?': "black on bright_cyan",
+			'A': "black on red",
+			'C': "black on magenta",
+			'H': "black on cyan",
+			'I': "black on white",
+			'M': "black on bright_magenta",
+			'P': "black on green",
+			'T': "black on yellow",
+			'U': "black on blue",
+		},
+		Playing:  "on blue",
+		Selected: "on blue",
+		Success:  "green",
+		Warning:  "yellow",
+	},
+	"gruvbox": {
+		Border: "#665c54/bright_black",
+		Cursor: "on #504945/bright_black",
+		Error:  "bold #fb4934/red",
+		Header: "bold #fabd2f/yellow",
+		Info:   "#83a598/blue",
+		Modes: map[rune]string{
+			'?': "#282828/black on #fe8019/bright_red",
+			'A': "#282828/black on #fb4934/red",
+			'C': "#282828/black on #d3869b/magenta",
+			'H': "#282828/black on #8ec07c/cyan",
+			'I': "#282828/black on #ebdbb2/white",
+			'M': "#282828/black on #d5c4a1/bright_white",
+			'P': "#282828/black on #b8bb26/green",
+			'T': "#282828/black on #fabd2f/yellow",
+			'U': "#282828/black on #83a598/blue",
+		},
+		Playing:  "#282828/black on #b8bb26/green",
+		Selected: "#282828/black on #83a598/blue",
+		Success:  "#b8bb26/green",
+		Warning:  "#fabd2f/yellow",
+	},
+	"nord": {
+		Border: "#4c566a/bright_black",
+		Cursor: "on #434c5e/bright_black",
+		Error:  "bold #bf616a/red",
+		Header: "bold #88c0d0/cyan",
+		Info:   "#88c0d0/cyan",
+		Modes: map[rune]string{
+			'?': "#2e3440/black on #d08770/bright_red",
+			'A': "#2e3440/black on #bf616a/red",
+			'C': "#2e3440/black on #b48ead/magenta",
+			'H': "#2e3440/black on #88c0d0/cyan",
+			'I': "#2e3440/black on #d8dee9/white",
+			'M': "#2e3440/black on #e5e9f0/bright_white",
+			'P': "#2e3440/black on #a3be8c/green",
+			'T': "#2e3440/black on #ebcb8b/yellow",
+			'U': "#2e3440/black on #81a1c1/blue",
+		},
+		Playing:  "#2e3440/black on #a3be8c/green",
+		Selected: "#2e3440/black on #88c0d0/cyan",
+		Success:  "#a3be8c/green",
+		Warning:  "#ebcb8b/yellow",
+	},
+	// For terminals where colors get in the way, looks the same with NO_COLOR
+	"mono": {
+		Border: "none",
+		Cursor: "reverse",
+		Error:  "bold reverse",
+		Header: "bold",
+		Info:   "none",
+		Modes: map[rune]string{
+			'?': "reverse bold",
+			'A': "reverse bold",
+			'C': "reverse bold",
+			'H': "reverse bold",
+			'I': "reverse bold",
+			'M': "reverse bold",
+			'P': "reverse bold",
+			'T': "reverse bold",
+			'U': "reverse bold",
+		},
+		Playing:  "bold underline",
+		Selected: "bold underline",
+		Success:  "none",
+		Warning:  "bold",
+	},
+}
+
+// Names of the bundled themes in order, for error messages
+func Names() []string {
+	names := []string{}
+	for name := range Bundled {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -1,9 +1,13 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"neofy/internal"
+	"neofy/internal/cli"
+	"neofy/internal/logging"
 	"os"
 
 	"github.com/joho/godotenv"
@@ -11,28 +15,67 @@ import (
 
 func main() {
 	if err := run(os.Stdout, os.Args); err != nil {
+		// NOTE: Commands fail with a message for the user & a code scripts can check
+		var exitErr *cli.ExitError
+		if errors.As(err, &exitErr) {
+			fmt.Fprintf(os.Stderr, "neofy: %s\n", exitErr)
+			os.Exit(exitErr.Code)
+		}
 		fmt.Fprintf(os.Stderr, "%s\n", err)
 		os.Exit(1)
 	}
 }
 
 func run(w io.Writer, args []string) error {
-	fmt.Println("Use:", w, args)
-	fmt.Println("\033[2J") // Clears Page
-	runMockMode := setArgsConfigs(args)
+	runMockMode, debug, cmdArgs, err := setArgsConfigs(args)
+	if errors.Is(err, flag.ErrHelp) {
+		// The usage was printed
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
 
-	err := godotenv.Load()
+	log, err := logging.Setup(debug)
 	if err != nil {
+		return fmt.Errorf("run: %w", err)
+	}
+	defer log.Close()
+
+	// NOTE: .env is optional, credentials can be in the config file
+	err = godotenv.Load()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
 		return fmt.Errorf("run: godotenv: %w", err)
 	}
-	return internal.RunApp(runMockMode)
+	if len(cmdArgs) > 0 {
+		err = internal.RunCommand(w, runMockMode, cmdArgs)
+	} else {
+		err = internal.RunApp(runMockMode)
+	}
+	if debug {
+		// The log is the only place the debug output goes
+		if path, pathErr := logging.Path(); pathErr == nil {
+			fmt.Fprintln(os.Stderr, "Debug log written to "+path)
+		}
+	}
+	return err
 }
 
-func setArgsConfigs(args []string) bool {
-	if len(args) >= 2 {
-		if args[1] == "-t" {
-			return true
-		}
+// -t runs with a mock player, --debug logs at the debug level (HTTP requests included).
+// The args after the flags are a command to run without the TUI (next, vol 40)
+func setArgsConfigs(args []string) (bool, bool, []string, error) {
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	mock := flags.Bool("t", false, "run with a mock player instead of Spotify")
+	debug := flags.Bool("debug", false, "log everything, HTTP requests included")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: neofy [-t] [--debug] [command [args]]")
+		fmt.Fprintln(flags.Output(), "The TUI is opened when there is no command")
+		flags.PrintDefaults()
+		cli.PrintUsage(flags.Output())
+	}
+	err := flags.Parse(args[1:])
+	if err != nil {
+		return false, false, nil, fmt.Errorf("setArgsConfigs: %w", err)
 	}
-	return false
+	return *mock, *debug, flags.Args(), nil
 }